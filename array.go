@@ -0,0 +1,65 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/n-r-w/nerr"
+)
+
+// arrayCond - condition with the array of values: column = ANY(ARRAY[...]), column && ARRAY[...] etc.
+type arrayCond struct {
+	column string
+	op     string
+	values any
+}
+
+// Any - column = ANY(ARRAY[...]): the column equals any of the values. Unlike In, the list is a single array value
+func Any(column string, values any) Cond {
+	return &arrayCond{column: column, op: "= ANY", values: values}
+}
+
+// Overlaps - column && ARRAY[...]: the array column has common elements with the values
+func Overlaps(column string, values any) Cond {
+	return &arrayCond{column: column, op: "&&", values: values}
+}
+
+// ArrayContains - column @> ARRAY[...]: the array column contains all the values
+func ArrayContains(column string, values any) Cond {
+	return &arrayCond{column: column, op: "@>", values: values}
+}
+
+// ArrayContainedBy - column <@ ARRAY[...]: all elements of the array column are among the values
+func ArrayContainedBy(column string, values any) Cond {
+	return &arrayCond{column: column, op: "<@", values: values}
+}
+
+func (c *arrayCond) renderCond(r *condRenderer) (string, error) {
+	if err := checkExpressions("column", []string{c.column}); err != nil {
+		return "", err
+	}
+
+	if c.values == nil {
+		return "", nerr.New(fmt.Sprintf("%s: nil values", c.column))
+	}
+	if k := reflect.ValueOf(c.values).Kind(); k != reflect.Slice && k != reflect.Array {
+		return "", nerr.New(fmt.Sprintf("%s: slice expected, got %T", c.column, c.values))
+	}
+
+	val, err := r.array(c.values)
+	if err != nil {
+		return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+	}
+
+	if c.op == "= ANY" {
+		return c.column + " = ANY(" + val + ")", nil
+	}
+
+	return c.column + " " + c.op + " " + val, nil
+}
+
+// ArrayLength - number of elements of the one-dimensional array column: COALESCE(array_length(column, 1), 0).
+// array_length returns null for an empty array, so it is replaced by 0. Can be used as a column in conditions: Gt(ArrayLength("tags"), 0)
+func ArrayLength(column string) string {
+	return "COALESCE(array_length(" + column + ", 1), 0)"
+}
@@ -0,0 +1,28 @@
+package sqlb
+
+import "testing"
+
+func TestArrayConds(t *testing.T) {
+	c := And(
+		Any("id", []int{1, 2}),
+		Overlaps("tags", []string{"a", "b"}),
+		ArrayContains("tags", []string{"a"}),
+		ArrayContainedBy("tags", []string{}),
+		Gt(ArrayLength("tags"), 0),
+	)
+	sql, err := CondSql(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "id = ANY(ARRAY[1,2]) AND tags && ARRAY[E'a',E'b'] AND tags @> ARRAY[E'a'] AND tags <@ '{}' AND " +
+		"COALESCE(array_length(tags, 1), 0) > 0"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	for _, bad := range []Cond{Any("id", nil), Overlaps("tags", "a"), Any("", []int{1})} {
+		if _, err := CondSql(bad); err == nil {
+			t.Errorf("expected error for %#v", bad)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package sqlb
+
+import "sort"
+
+// BoundValue - bound value of a variable for audit logging. Can be serialized to json
+type BoundValue struct {
+	// Имя переменной, включая ':'
+	Variable string `json:"variable"`
+	// Преобразованное в sql значение
+	Sql string `json:"sql"`
+	// Исходное значение
+	Original any `json:"original,omitempty"`
+	// Значение по умолчанию (BindDefault)
+	Default bool `json:"default,omitempty"`
+	// Конфиденциальное значение (Sensitive или WithRedactPatterns), Sql и Original заменены на [REDACTED]
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// BoundValues - values that will be used in the calculation (explicitly bound and defaults), sorted by variable name.
+// Original is the value passed to Bind (for BindIn, BindOrderBy etc. - their argument).
+// Sql and Original of sensitive values are replaced with [REDACTED]
+func (b *SqlBinder) BoundValues() []BoundValue {
+	res := make([]BoundValue, 0, len(b.values)+len(b.defaults))
+
+	for v, val := range b.values {
+		res = append(res, BoundValue{
+			Variable:  v,
+			Sql:       val,
+			Original:  b.originals[v],
+			Sensitive: b.isSensitive(v),
+		})
+	}
+
+	for v, val := range b.defaults {
+		if _, ok := b.values[v]; ok {
+			continue
+		}
+		res = append(res, BoundValue{
+			Variable:  v,
+			Sql:       val,
+			Original:  b.defaultOriginals[v],
+			Default:   true,
+			Sensitive: b.isSensitive(v),
+		})
+	}
+
+	for i := range res {
+		if res[i].Sensitive {
+			res[i].Sql = redactedValue
+			res[i].Original = redactedValue
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Variable < res[j].Variable
+	})
+
+	return res
+}
+
+// saveOriginal - remember the original value of the variable
+func (b *SqlBinder) saveOriginal(toDefaults bool, variable string, value any) {
+	if v, ok := value.(Value); ok {
+		value = v.value
+	}
+
+	target := &b.originals
+	if toDefaults {
+		target = &b.defaultOriginals
+	}
+
+	if *target == nil {
+		*target = map[string]any{}
+	}
+	(*target)[variableName(variable)] = value
+}
+
+// copyOriginals - copy of the original values map
+func copyOriginals(values map[string]any) map[string]any {
+	if values == nil {
+		return nil
+	}
+
+	res := make(map[string]any, len(values))
+	for k, v := range values {
+		res[k] = v
+	}
+
+	return res
+}
@@ -0,0 +1,133 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/n-r-w/nerr"
+)
+
+// AuditColumns - audit columns of the table. An empty name excludes the column
+type AuditColumns struct {
+	// Время создания строки, задается при INSERT
+	CreatedAt string
+	// Время изменения строки, задается при INSERT и UPDATE
+	UpdatedAt string
+	// Автор изменения, задается при INSERT и UPDATE
+	UpdatedBy string
+}
+
+// defaultAuditColumns - audit columns of the tables registered with zero AuditColumns
+var defaultAuditColumns = AuditColumns{
+	CreatedAt: "created_at",
+	UpdatedAt: "updated_at",
+	UpdatedBy: "updated_by",
+}
+
+type actorKey struct{}
+
+// WithActor - context with the actor (user id, login etc.), which is written to the UpdatedBy audit column
+func WithActor(ctx context.Context, actor any) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom - actor from the context. See WithActor
+func ActorFrom(ctx context.Context) (any, bool) {
+	actor := ctx.Value(actorKey{})
+	return actor, actor != nil
+}
+
+var auditTables atomic.Pointer[map[string]AuditColumns]
+
+// SetAudit - register tables with audit columns. Zero AuditColumns means created_at, updated_at and updated_by.
+// INSERT and UPDATE builders of these tables can't be rendered without the Audit call, so the audit columns can't be forgotten.
+// Table names are the names stored in the database, see Schema. nil disables the check
+func SetAudit(tables map[string]AuditColumns) {
+	if tables == nil {
+		auditTables.Store(nil)
+		return
+	}
+
+	m := make(map[string]AuditColumns, len(tables))
+	for table, columns := range tables {
+		if columns == (AuditColumns{}) {
+			columns = defaultAuditColumns
+		}
+		m[table] = columns
+	}
+
+	auditTables.Store(&m)
+}
+
+// auditColumns - audit columns of the table expression and whether the table is registered by SetAudit
+func auditColumns(table string) (AuditColumns, bool) {
+	if tables := auditTables.Load(); tables != nil {
+		for t, columns := range *tables {
+			if sameTable(t, table) {
+				return columns, true
+			}
+		}
+	}
+
+	return defaultAuditColumns, false
+}
+
+// auditState - audit settings of the builder
+type auditState struct {
+	enabled bool
+	actor   any
+}
+
+// set - enable the audit columns of the table with the actor from the context
+func (a *auditState) set(ctx context.Context, table string) error {
+	a.enabled = true
+
+	columns, _ := auditColumns(table)
+	if len(columns.UpdatedBy) == 0 {
+		return nil
+	}
+
+	actor, ok := ActorFrom(ctx)
+	if !ok {
+		return nerr.New(fmt.Sprintf("table %s: no actor in the context for %s, see WithActor", table, columns.UpdatedBy))
+	}
+	a.actor = actor
+
+	return nil
+}
+
+// values - audit columns and values, except the explicitly set columns. created_at is set only by INSERT
+func (a *auditState) values(table string, insert bool, explicit []string) ([]string, []any, error) {
+	columns, registered := auditColumns(table)
+	if !a.enabled {
+		if registered {
+			return nil, nil, nerr.New(fmt.Sprintf("table %s has audit columns, call Audit", table))
+		}
+		return nil, nil, nil
+	}
+
+	set := make(map[string]bool, len(explicit))
+	for _, c := range explicit {
+		set[aliasKey(c)] = true
+	}
+
+	var (
+		names  []string
+		values []any
+	)
+	add := func(column string, value any) {
+		if len(column) > 0 && !set[aliasKey(column)] {
+			names = append(names, column)
+			values = append(values, value)
+		}
+	}
+
+	if insert {
+		add(columns.CreatedAt, Raw("NOW()"))
+	}
+	add(columns.UpdatedAt, Raw("NOW()"))
+	add(columns.UpdatedBy, a.actor)
+
+	return names, values, nil
+}
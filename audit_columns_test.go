@@ -0,0 +1,53 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuilder_Audit(t *testing.T) {
+	ctx := WithActor(context.Background(), "admin")
+
+	sql, err := Insert("users").Columns("name").Values("bob").Audit(ctx).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "INSERT INTO users (name, created_at, updated_at, updated_by) VALUES (E'bob', NOW(), NOW(), E'admin')"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Update("users").Set("name", "bob").Set("updated_by", 1).Audit(ctx).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "UPDATE users SET name = E'bob', updated_by = 1, updated_at = NOW()"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if err := Update("users").Set("name", "bob").Audit(context.Background()).Err(); err == nil {
+		t.Fatal("expected error for context without actor")
+	}
+}
+
+func TestSetAudit(t *testing.T) {
+	SetAudit(map[string]AuditColumns{
+		"users":  {},
+		"orders": {UpdatedAt: "modified"},
+	})
+	defer SetAudit(nil)
+
+	if _, err := Insert("users").Columns("name").Values("bob").Sql(); err == nil {
+		t.Fatal("expected error without Audit")
+	}
+	if _, err := Update("items").Set("name", "bob").Sql(); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, err := Update("orders").Set("total", 1).Audit(context.Background()).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "UPDATE orders SET total = 1, modified = NOW()"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
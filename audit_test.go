@@ -0,0 +1,26 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSqlBinder_BoundValues(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE id = :id AND token = :token LIMIT :limit")
+	binder.MustBind("id", 5).MustBind("token", "abc", Sensitive())
+	if err := binder.BindDefault("limit", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(binder.BoundValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := `[{"variable":":id","sql":"5","original":5},` +
+		`{"variable":":limit","sql":"10","original":10,"default":true},` +
+		`{"variable":":token","sql":"[REDACTED]","original":"[REDACTED]","sensitive":true}]`
+	if string(data) != req {
+		t.Fatalf("%s, wants: %s", data, req)
+	}
+}
@@ -0,0 +1,108 @@
+package sqlb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Statement - anything that produces sql: SqlBinder, Query, Template binders etc.
+type Statement interface {
+	Sql() (string, error)
+}
+
+// StatementError - error of a single statement of Batch
+type StatementError struct {
+	// Номер выражения в пакете
+	Index int
+	// Имя выражения (может быть пустым)
+	Name string
+	Err  error
+}
+
+// Error - implements error
+func (e *StatementError) Error() string {
+	if len(e.Name) > 0 {
+		return fmt.Sprintf("statement %d (%s): %v", e.Index, e.Name, e.Err)
+	}
+
+	return fmt.Sprintf("statement %d: %v", e.Index, e.Err)
+}
+
+// Unwrap - source error
+func (e *StatementError) Unwrap() error {
+	return e.Err
+}
+
+// Batch - list of statements, rendered as a script joined by semicolons or individually
+type Batch struct {
+	items []batchItem
+}
+
+type batchItem struct {
+	name string
+	stmt Statement
+}
+
+// NewBatch - create Batch
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Add - add the statement
+func (b *Batch) Add(stmt Statement) *Batch {
+	return b.AddNamed("", stmt)
+}
+
+// AddNamed - add the statement with the name used in error messages
+func (b *Batch) AddNamed(name string, stmt Statement) *Batch {
+	b.items = append(b.items, batchItem{name: name, stmt: stmt})
+	return b
+}
+
+// Len - number of statements
+func (b *Batch) Len() int {
+	return len(b.items)
+}
+
+// Statements - sql of each statement. All statements are calculated,
+// errors are reported as StatementError combined by errors.Join
+func (b *Batch) Statements() ([]string, error) {
+	res := make([]string, len(b.items))
+	var errs []error
+
+	for i, item := range b.items {
+		if item.stmt == nil {
+			errs = append(errs, &StatementError{Index: i, Name: item.name, Err: errors.New("nil statement")})
+			continue
+		}
+
+		sql, err := item.stmt.Sql()
+		if err != nil {
+			errs = append(errs, &StatementError{Index: i, Name: item.name, Err: err})
+			continue
+		}
+
+		res[i] = strings.TrimRight(strings.TrimSpace(sql), ";")
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return res, nil
+}
+
+// Sql - the script: all statements joined by semicolons
+func (b *Batch) Sql() (string, error) {
+	statements, err := b.Statements()
+	if err != nil {
+		return "", err
+	}
+
+	if len(statements) == 0 {
+		return "", nil
+	}
+
+	return strings.Join(statements, ";\n") + ";", nil
+}
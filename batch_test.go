@@ -0,0 +1,28 @@
+package sqlb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	batch := NewBatch().
+		Add(NewBinder("DELETE FROM t WHERE id = :id;").MustBind("id", 1)).
+		Add(Q("UPDATE t SET a = :a").Set("a", "x"))
+
+	sql, err := batch.Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "DELETE FROM t WHERE id = 1;\nUPDATE t SET a = E'x';"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	batch.AddNamed("cleanup", NewBinder("DELETE FROM t WHERE id = :id"))
+	_, err = batch.Statements()
+
+	var stmtErr *StatementError
+	if !errors.As(err, &stmtErr) || stmtErr.Index != 2 || stmtErr.Name != "cleanup" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
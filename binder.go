@@ -2,13 +2,17 @@
 package sqlb
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -70,13 +74,28 @@ func (p *Parser) IsVariableParsed(v string) bool {
 	return ok
 }
 
+// checkParsed - parse the template if it has not been parsed yet
+func (p *Parser) checkParsed() error {
+	if p.isParced {
+		return nil
+	}
+
+	m := currentMetrics()
+	if m == nil {
+		return p.Parse()
+	}
+
+	start := time.Now()
+	err := p.Parse()
+	m.Parse(time.Since(start), err)
+
+	return err
+}
+
 // Calculate - substitute values into variables and get the result
 func (p *Parser) Calculate(values map[string]string) (string, error) {
-	if !p.isParced {
-		p.isParced = true
-		if err := p.Parse(); err != nil {
-			return "", err
-		}
+	if err := p.checkParsed(); err != nil {
+		return "", err
 	}
 
 	if len(p.parsed) == 0 {
@@ -85,137 +104,109 @@ func (p *Parser) Calculate(values map[string]string) (string, error) {
 
 	var sql strings.Builder
 	sql.Grow(len(p.sqlTemplate) + len(values)*10)
+	if err := p.calculateTo(&sql, values); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// calculateBuf - same as Calculate, but the query is calculated in buf, which is reused between calls.
+// If buf is nil, Calculate is called
+func (p *Parser) calculateBuf(buf *bytes.Buffer, values map[string]string) (string, error) {
+	if buf == nil {
+		return p.Calculate(values)
+	}
+
+	if err := p.checkParsed(); err != nil {
+		return "", err
+	}
+
+	if len(p.parsed) == 0 {
+		return p.sqlTemplate, nil
+	}
+
+	buf.Reset()
+	buf.Grow(len(p.sqlTemplate) + len(values)*10)
+	if err := p.calculateTo(buf, values); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// calculateTo - write the result of substituting variables into the parsed template to w
+func (p *Parser) calculateTo(w io.StringWriter, values map[string]string) error {
 	shift := 0
 
 	for _, d := range p.parsed {
 		// Остаток слева
-		sql.WriteString(p.sqlTemplate[shift:d.pos])
+		_, _ = w.WriteString(p.sqlTemplate[shift:d.pos])
 		// Заменяем переменную
 		value, ok := values[d.name]
 		if !ok {
-			return "", nerr.New(fmt.Sprintf("bind value not found for: %s", d.name))
+			return nerr.New(fmt.Sprintf("bind value not found for: %s", d.name))
 		}
-		sql.WriteString(value)
+		_, _ = w.WriteString(value)
 		shift = d.pos + len(d.name)
 	}
 
 	// Остаток справа
-	last := p.parsed[len(p.parsed)-1]
-	remains := len(p.sqlTemplate) - (last.pos + len(last.name))
-	sql.WriteString(p.sqlTemplate[len(p.sqlTemplate)-remains:])
+	_, _ = w.WriteString(p.sqlTemplate[shift:])
 
-	return sql.String(), nil
+	return nil
 }
 
+// Parse - find the variables :var outside of comments, string constants and quoted identifiers (see scanSql).
+// :: is a type cast, not a variable
 func (p *Parser) Parse() error {
 	if p.parsedMap == nil {
 		p.parsedMap = make(map[string]*data)
 	}
 
-	commentFound := false // найден комментарий
-	commentLine := false  // комментарий в режиме строки (символы --)
-
-	stringFound := false // найдено начало строки sql (символ ')
-	varFound := false    // найдено начало переменной
-	firstVarPos := -1
-
-	for i := 0; i < len(p.sqlTemplate); i++ {
-		c := p.sqlTemplate[i]
-
-		if commentFound {
-			// состояние поиска окончания комментария
-			if c == '\n' && commentLine {
-				commentFound = false
-				commentLine = false
-			}
-
-			if c == '*' && (i == len(p.sqlTemplate)-1 || p.sqlTemplate[i+1] == '/') {
-				if i != len(p.sqlTemplate)-1 {
-					i++ // переходим на символ вперед, чтобы пропустить '/'
-				}
-				commentFound = false
-				commentLine = false
-			}
-
-			continue
-		}
-
-		if stringFound {
-			// В состоянии поиска закрытия строки
-			if c == '\'' {
-				// Найдена потенциальная закрывающая ковычка
-				if i < len(p.sqlTemplate)-1 && p.sqlTemplate[i+1] == '\'' {
-					// Это двойная одиночная ковычка - пропускаем и переходим на один символ вперед
-					i++
-					continue
-				}
-				stringFound = false
-			}
+	sql := p.sqlTemplate
+	for i := 0; i < len(sql); {
+		if kind, end := scanSql(sql, i); kind != sqlCode {
+			i = end
 			continue
 		}
 
-		if c == '\'' {
-			// Найдена открывающая ковычка
-			stringFound = true
-		}
-
-		if varFound {
-			// В режиме поиска конца переменной
-			alnum := isAllnum(c)
-			if stringFound || (i == len(p.sqlTemplate)-1 || !alnum) {
-				// В конце строки или найден не алфавитно-цифровой символ
-				d := &data{
-					name: p.sqlTemplate[firstVarPos : firstVarPos+i-firstVarPos],
-					pos:  firstVarPos,
-				}
-
-				// Завершающий символ переменной в конце строки
-				if i == len(p.sqlTemplate)-1 && alnum && !stringFound {
-					d.name += string(c)
-				}
-
-				p.parsed = append(p.parsed, d)
-				p.parsedMap[d.name] = d
-
-				varFound = false
-
-				if strings.TrimSpace(d.name) == ":" {
-					p.parsed = []*data{}
-					p.parsedMap = map[string]*data{}
-					return nerr.New("found ':' without variable")
-				}
-			}
+		if sql[i] != ':' {
+			i++
 			continue
 		}
 
-		if !stringFound && c == ':' && i != len(p.sqlTemplate)-1 && p.sqlTemplate[i+1] == ':' {
+		if i+1 < len(sql) && sql[i+1] == ':' {
 			// найдено ::
-			i++
+			i += 2
 			continue
 		}
 
-		if !stringFound && c == ':' {
-			// найдено начало переменной
-			varFound = true
-			firstVarPos = i
-			continue
+		// имя переменной до первого не алфавитно-цифрового символа, он обрабатывается дальше (например :var::int)
+		end := i + 1
+		for end < len(sql) && isAllnum(sql[end]) {
+			end++
 		}
 
-		if c == '/' && i != len(p.sqlTemplate)-1 && p.sqlTemplate[i+1] == '*' {
-			// Начало многострочного комментария
-			commentFound = true
-			commentLine = false
-			i++
-			continue
+		if end == i+1 {
+			if end == len(sql) {
+				// ':' в конце шаблона не считается переменной
+				break
+			}
+
+			p.parsed = []*data{}
+			p.parsedMap = map[string]*data{}
+			return nerr.New("found ':' without variable")
 		}
 
-		if c == '-' && i != len(p.sqlTemplate)-1 && p.sqlTemplate[i+1] == '-' {
-			// Начало однострочного комментария
-			commentFound = true
-			commentLine = true
-			i++
-			continue
+		d := &data{
+			name: sql[i:end],
+			pos:  i,
 		}
+		p.parsed = append(p.parsed, d)
+		p.parsedMap[d.name] = d
+
+		i = end
 	}
 
 	p.isParced = true
@@ -237,81 +228,384 @@ type SqlBinder struct {
 	// Результат парсинга
 	sql        string
 	calculated bool
+	// Значения по умолчанию, используются если переменная не связана явно
+	defaults map[string]string
+	// Исходные значения переменных
+	originals map[string]any
+	// Исходные значения по умолчанию
+	defaultOriginals map[string]any
+	// Переменные с конфиденциальными значениями
+	sensitive map[string]bool
+	// Журнал связывания в режиме отладки
+	trace []BindTrace
+	// Ошибка создания, возвращается всеми методами связывания и вычисления
+	err error
+	// Буфер вычисления запроса, переиспользуется binder'ами из пула
+	buf *bytes.Buffer
+	// Настройки
+	binderOptions
+}
+
+// BinderOption - option of SqlBinder creation
+type BinderOption func(o *binderOptions)
+
+// binderOptions - SqlBinder creation settings
+type binderOptions struct {
+	// Ключ кэширования результата парсинга
+	key string
+	// Ключ кэширования вычисляется по содержимому шаблона
+	autoKey bool
+	// Кэш результатов парсинга, nil - глобальный
+	cache *Cache
+	// Поколение шаблона в кэше
+	generation uint64
+	// Пространство имен ключа кэширования
+	namespace string
+	// Строгий режим: запрещено связывать переменные, которых нет в шаблоне
+	strict bool
+	// Опции преобразования значений по умолчанию
+	valueOpts []Option
+	// Объединение ошибок BindValues
+	joinErrors bool
+	// Хуки вычисления запроса
+	hooks []CalculateHook
+	// Режим отладки
+	debug bool
+	// Шаблоны имен переменных с конфиденциальными значениями
+	redact []string
+	// Идентификатор арендатора (WithTenant)
+	tenant    any
+	hasTenant bool
+	// Запрос без арендатора разрешен явно (WithoutTenant)
+	noTenant bool
+	// Подстановка зарегистрированных фрагментов {{name}}
+	fragments bool
+	// Комментарий, добавляемый к запросу (WithComment)
+	comment string
+}
+
+// WithCacheKey - key is used to exclude repeated parsing of identical queries. The result of parsing is saved.
+// An empty key disables caching
+func WithCacheKey(key string) BinderOption {
+	return func(o *binderOptions) {
+		o.key = key
+	}
 }
 
-var parcedCacheMutex sync.Mutex
-var parcedCache map[string]*Parser
+// WithStrictMode - Bind returns an error for variables that are not present in the template. See SetStrict
+func WithStrictMode() BinderOption {
+	return func(o *binderOptions) {
+		o.strict = true
+	}
+}
+
+// WithTimeFormat - layout for time.Time values (see time.Layout). Can be overridden by the TimeFormat option of Bind
+func WithTimeFormat(layout string) BinderOption {
+	return WithValueOptions(TimeFormat(layout))
+}
+
+// WithValueOptions - conversion options applied to every bound value before the options passed to Bind
+func WithValueOptions(opts ...Option) BinderOption {
+	return func(o *binderOptions) {
+		o.valueOpts = append(o.valueOpts, opts...)
+	}
+}
+
+// WithJoinErrors - BindValues binds all values and reports every error at once (errors.Join) instead of stopping at the first one
+func WithJoinErrors() BinderOption {
+	return func(o *binderOptions) {
+		o.joinErrors = true
+	}
+}
 
 // NewBinder - create SqlBinder
-// key is used to exclude repeated parsing of identical queries. The result of parsing is saved
-func NewBinder(template string, key string) *SqlBinder {
-	var parcer *Parser
+func NewBinder(template string, opts ...BinderOption) *SqlBinder {
+	o := newBinderOptions(opts)
+
+	if o.fragments {
+		expanded, err := ExpandFragments(template)
+		if err != nil {
+			b := newBinder(NewParser(template), o)
+			b.err = err
+			return b
+		}
+		template = expanded
+	}
 
-	if len(key) > 0 {
-		parcedCacheMutex.Lock()
+	key := o.key
+	if len(key) == 0 && o.autoKey {
+		key = templateKey(template)
+	}
+	if len(key) > 0 && len(o.namespace) > 0 {
+		key = namespaceKey(o.namespace, key)
+	}
 
-		if parcedCache == nil {
-			parcedCache = make(map[string]*Parser)
+	parcer, err := templateParser(o.cache, template, key, o.generation)
+	b := newBinder(parcer, o)
+	b.err = err
+	if b.err == nil {
+		b.err = b.applyTenant()
+	}
+
+	return b
+}
+
+// templateParser - parser of the template, from the cache if the key is not empty. If the cache is nil, the global one is used.
+// If the key is already used for a different template, an error is returned together with a new (not cached) parser
+func templateParser(cache *Cache, template string, key string, generation uint64) (*Parser, error) {
+	if len(key) == 0 {
+		return NewParser(template), nil
+	}
+
+	if cache == nil {
+		cache = parcedCache
+	}
+
+	parcer := cache.getGeneration(key, template, generation)
+	if parcer.SqlTemplate() != template {
+		return NewParser(template), nerr.New(fmt.Sprintf("same key for different templates: %s", displayKey(key)))
+	}
+
+	return parcer, nil
+}
+
+// newBinderOptions - collect options
+func newBinderOptions(opts []BinderOption) *binderOptions {
+	o := &binderOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
 		}
+	}
+
+	return o
+}
+
+// newBinder - create SqlBinder for the parser
+func newBinder(parcer *Parser, o *binderOptions) *SqlBinder {
+	return &SqlBinder{
+		parcer:        parcer,
+		values:        map[string]string{},
+		sql:           "",
+		calculated:    false,
+		binderOptions: *o,
+	}
+}
+
+// valueOptions - conversion options of the binder supplemented with opts
+func (b *SqlBinder) valueOptions(opts []Option) []Option {
+	if len(b.valueOpts) == 0 {
+		return opts
+	}
+
+	res := make([]Option, 0, len(b.valueOpts)+len(opts))
+	res = append(res, b.valueOpts...)
+	return append(res, opts...)
+}
+
+// Err - error of the binder creation, e.g. the cache key is already used for a different template.
+// The same error is returned by all Bind methods and Sql
+func (b *SqlBinder) Err() error {
+	return b.err
+}
+
+// SetStrict - in strict mode Bind returns an error for variables that are not present in the template
+func (b *SqlBinder) SetStrict(strict bool) {
+	b.strict = strict
+}
+
+// Validate - checks that all bound variables are present in the template.
+// Returns an error listing every bound but unparsed variable
+func (b *SqlBinder) Validate() error {
+	if b.err != nil {
+		return b.err
+	}
 
-		var ok bool
-		if parcer, ok = parcedCache[key]; !ok {
-			parcer = NewParser(template)
-			parcer.Parse()
-			parcedCache[key] = parcer
-		} else if len(parcer.SqlTemplate()) != len(template) {
-			panic(fmt.Sprintf("same key for different templates: %s", key))
+	if err := b.parcer.checkParsed(); err != nil {
+		return err
+	}
+
+	var unknown []string
+	for v := range b.values {
+		if _, ok := b.parcer.parsedMap[v]; !ok {
+			unknown = append(unknown, v)
 		}
+	}
 
-		parcedCacheMutex.Unlock()
-	} else {
-		parcer = NewParser(template)
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nerr.New(fmt.Sprintf("variables not found in template: %s", strings.Join(unknown, ", ")))
 	}
 
+	return nil
+}
+
+// Clone - independent copy of the binder with the same template and copies of bound values.
+// The parsing result is shared. The copy is not calculated, so more values can be bound to it
+func (b *SqlBinder) Clone() *SqlBinder {
+	// парсим заранее, чтобы копии не парсили общий шаблон независимо друг от друга
+	_ = b.parcer.checkParsed()
+
 	return &SqlBinder{
-		parcer:     parcer,
-		values:     map[string]string{},
-		sql:        "",
-		calculated: false,
+		parcer:           b.parcer,
+		values:           copyValues(b.values),
+		defaults:         copyValues(b.defaults),
+		originals:        copyOriginals(b.originals),
+		defaultOriginals: copyOriginals(b.defaultOriginals),
+		sensitive:        copySensitive(b.sensitive),
+		trace:            append([]BindTrace(nil), b.trace...),
+		err:              b.err,
+		binderOptions:    b.binderOptions,
 	}
 }
 
-// Clear - resets everything except the template
+// Clear - resets everything except the template. Same as Reset
 func (b *SqlBinder) Clear() {
+	b.Reset()
+}
+
+// Reset - removes all bound values (including defaults) and the calculated result, keeping the template, its parsing result and the strict mode.
+// After Reset the binder can be bound and calculated again, any number of times
+func (b *SqlBinder) Reset() {
 	b.calculated = false
 	b.sql = ""
 	b.values = map[string]string{}
+	b.defaults = nil
+	b.originals = nil
+	b.defaultOriginals = nil
+	b.sensitive = nil
+	b.trace = nil
+}
+
+// Recalculate - discards the calculated result but keeps bound values, so that more values can be bound
+// or existing ones replaced with BindOver, after which Sql() calculates the query again
+func (b *SqlBinder) Recalculate() {
+	b.calculated = false
+	b.sql = ""
+}
+
+// copyValues - copy of the variable-value map
+func copyValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		res[k] = v
+	}
+
+	return res
 }
 
 // Bind - replace the format bind in the Sql string :bind to the value of the value variable
-func (b *SqlBinder) Bind(variable string, value any) error {
+func (b *SqlBinder) Bind(variable string, value any, opts ...Option) error {
+	return b.bind(variable, value, false, opts)
+}
+
+// BindOver - same as Bind, but replaces the value if the variable is already bound
+func (b *SqlBinder) BindOver(variable string, value any, opts ...Option) error {
+	return b.bind(variable, value, true, opts)
+}
+
+// BindIf - same as Bind, but only if cond is true. Otherwise does nothing
+func (b *SqlBinder) BindIf(cond bool, variable string, value any, opts ...Option) error {
+	if !cond {
+		return nil
+	}
+
+	return b.Bind(variable, value, opts...)
+}
+
+func (b *SqlBinder) bind(variable string, value any, overwrite bool, opts []Option) error {
+	return b.bindSql(variable, overwrite, value, opts, func() (string, error) {
+		return ToSql(value, b.valueOptions(opts)...)
+	})
+}
+
+// BindDefault - bind a value, which is used at calculation only if the variable was not bound explicitly
+// by Bind or other Bind* methods. Allows library defaults to be overridden by the caller regardless of the binding order
+func (b *SqlBinder) BindDefault(variable string, value any, opts ...Option) error {
+	return b.bindSqlTo(true, variable, false, value, opts, func() (string, error) {
+		return ToSql(value, b.valueOptions(opts)...)
+	})
+}
+
+// bindSql - checks the variable and saves the sql rendered by the render function.
+// value is the original value of the variable, opts are used for the debug trace and sensitive values
+func (b *SqlBinder) bindSql(variable string, overwrite bool, value any, opts []Option, render func() (string, error)) error {
+	return b.bindSqlTo(false, variable, overwrite, value, opts, render)
+}
+
+// bindSqlTo - same as bindSql, but saves into default values if toDefaults is true
+func (b *SqlBinder) bindSqlTo(toDefaults bool, variable string, overwrite bool, value any, opts []Option, render func() (string, error)) error {
+	target := b.values
+	if toDefaults {
+		if b.defaults == nil {
+			b.defaults = map[string]string{}
+		}
+		target = b.defaults
+	}
+
+	val, err := b.bindSqlHelper(target, variable, overwrite, render)
+	if err == nil {
+		b.markSensitive(variable, value, opts)
+		b.saveOriginal(toDefaults, variable, value)
+	} else if m := currentMetrics(); m != nil {
+		m.BindError(variable, err)
+	}
+	if b.debug {
+		b.addTrace(variable, value, opts, val, err)
+	}
+
+	return err
+}
+
+func (b *SqlBinder) bindSqlHelper(target map[string]string, variable string, overwrite bool, render func() (string, error)) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
 	if len(variable) == 0 {
-		return nerr.New("empty variable")
+		return "", nerr.New("empty variable")
 	}
 
 	if b.calculated {
-		return nerr.New("bind after calculate")
+		return "", nerr.New("bind after calculate, call Recalculate first")
 	}
 
-	if _, ok := b.values[variable]; ok {
-		return nerr.New(fmt.Sprintf("already binded %s", variable))
+	v := variableName(variable)
+
+	if _, ok := target[v]; ok && !overwrite {
+		return "", nerr.New(fmt.Sprintf("already binded %s", variable))
 	}
 
-	var v string
-	if variable[0] != ':' {
-		v = ":" + variable
-	} else {
-		v = variable
+	if b.strict {
+		if err := b.parcer.checkParsed(); err != nil {
+			return "", err
+		}
+		if _, ok := b.parcer.parsedMap[v]; !ok {
+			return "", nerr.New(fmt.Sprintf("variable not found in template: %s", v))
+		}
 	}
 
-	val, err := ToSql(value)
+	val, err := render()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	b.values[v] = val
+	target[v] = val
 
-	return nil
+	return val, nil
+}
+
+// variableName - variable name with leading ':'
+func variableName(variable string) string {
+	if len(variable) > 0 && variable[0] == ':' {
+		return variable
+	}
+
+	return ":" + variable
 }
 
 // ToSql - convert any value to sql string for json_path query
@@ -339,16 +633,32 @@ func ToJsonPath(v any) (string, error) {
 }
 
 // ToSql - convert any value to sql string
-func ToSql(v any) (string, error) {
-	val, _, err := toSqlHelper(v, `'`, true)
-	return val, err
+func ToSql(v any, opts ...Option) (string, error) {
+	if val, ok := v.(Value); ok {
+		// опции значения применяются после переданных
+		v = val.value
+		opts = append(opts[:len(opts):len(opts)], val.opts...)
+	}
+
+	return newOptions(opts).toSql(v)
 }
 
+// timeLayout - default layout of time.Time values
+const timeLayout = "2006-01-02 15:04:05.000000 -0700"
+
 func toSqlHelper(v any, quote string, escape bool) (string, bool, error) {
 	var val string
 	isText := false
 
 	if v != nil {
+		// зарегистрированные конвертеры и SqlValuer имеют приоритет над встроенными преобразованиями
+		if custom, found, err := customToSql(v); found {
+			if err != nil {
+				return "", false, err
+			}
+			return custom, false, nil
+		}
+
 		switch v := v.(type) {
 		case time.Duration:
 			total := int64(v.Seconds())
@@ -363,7 +673,7 @@ func toSqlHelper(v any, quote string, escape bool) (string, bool, error) {
 			}
 
 		case time.Time:
-			val = quote + v.Format("2006-01-02 15:04:05.000000 -0700") + quote
+			val = quote + v.Format(timeLayout) + quote
 			isText = true
 
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
@@ -417,7 +727,13 @@ func toSqlHelper(v any, quote string, escape bool) (string, bool, error) {
 		default:
 			// возможно это кастомный тип, который можно скастить
 			e := reflect.ValueOf(&v).Elem().Elem()
-			if e.CanInt() {
+			if (e.Kind() == reflect.Slice || e.Kind() == reflect.Array) && isCustomType(e.Type().Elem()) {
+				arr, err := arrayToSql(e, quote, escape)
+				if err != nil {
+					return "", false, err
+				}
+				return arr, false, nil
+			} else if e.CanInt() {
 				val = strconv.FormatInt(e.Int(), 10)
 			} else if e.CanUint() {
 				val = strconv.FormatUint(e.Uint(), 10)
@@ -441,15 +757,34 @@ func toSqlHelper(v any, quote string, escape bool) (string, bool, error) {
 	return val, isText, nil
 }
 
-// Bind - replace the format bind in the Sql string :bind to the value of the value variable
+// BindValues - bind all values of the map. By default stops at the first error.
+// With the WithJoinErrors option all values are bound and the errors are combined by errors.Join
 func (b *SqlBinder) BindValues(values map[string]any) error {
-	for variable, value := range values {
-		if err := b.Bind(variable, value); err != nil {
-			return err
+	if !b.joinErrors {
+		for variable, value := range values {
+			if err := b.Bind(variable, value); err != nil {
+				return err
+			}
 		}
+
+		return nil
 	}
 
-	return nil
+	// сортируем для стабильного порядка ошибок
+	variables := make([]string, 0, len(values))
+	for variable := range values {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+
+	var errs []error
+	for _, variable := range variables {
+		if err := b.Bind(variable, values[variable]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", variable, err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 func prepareString(s string, quote string, escape bool) string {
@@ -466,21 +801,133 @@ func prepareString(s string, quote string, escape bool) string {
 	}
 }
 
+// SqlTemplate - SQL template of the binder
+func (b *SqlBinder) SqlTemplate() string {
+	return b.parcer.SqlTemplate()
+}
+
+// CacheKey - key set by WithCacheKey, empty if not set
+func (b *SqlBinder) CacheKey() string {
+	return b.key
+}
+
 // Sql - get the result of substituting variables into a template
 func (b *SqlBinder) Sql() (string, error) {
+	return b.sqlContext(context.Background(), false)
+}
+
+// sqlContext - calculate the query in the span of the tracer (if trace is true) and the logger
+func (b *SqlBinder) sqlContext(ctx context.Context, trace bool) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
 	if !b.calculated {
-		b.calculated = true
+		_, s := startSpan(ctx, SpanInfo{
+			Operation: SpanCalculate,
+			Template:  b.SqlTemplate(),
+			Key:       b.key,
+		}, trace)
 
-		var err error
-		b.sql, err = b.parcer.Calculate(b.values)
+		sql, err := calculate(b.parcer, b.calcValues(), b.hooks, b.buf)
 		if err != nil {
+			s.end(-1, err)
 			return "", err
 		}
+
+		b.sql = appendComment(sql, b.comment)
+		b.calculated = true
+
+		if s != nil && s.logger != nil {
+			s.info.Sql = b.logSql()
+		}
+		s.end(-1, nil)
 	}
 
 	return b.sql, nil
 }
 
+// logSql - calculated query for the log, sensitive values are redacted
+func (b *SqlBinder) logSql() string {
+	if len(b.sensitive) == 0 && len(b.redact) == 0 {
+		return b.sql
+	}
+
+	sql, err := b.SqlRedacted()
+	if err != nil {
+		return ""
+	}
+
+	return appendComment(sql, b.comment)
+}
+
+// VariableInfo - information about a template variable
+type VariableInfo struct {
+	// Имя переменной, включая ':'
+	Name string
+	// Положение первого вхождения в шаблоне
+	Pos int
+	// Количество вхождений
+	Count int
+	// Связано ли значение (явно или значением по умолчанию)
+	Bound bool
+	// Значение по умолчанию
+	Default bool
+	// Преобразованное в sql значение
+	Value string
+}
+
+// Variables - information about each template variable in order of first occurrence.
+// If the template can't be parsed, nil is returned
+func (b *SqlBinder) Variables() []VariableInfo {
+	if err := b.parcer.checkParsed(); err != nil {
+		return nil
+	}
+
+	var res []VariableInfo
+	index := map[string]int{}
+	for _, d := range b.parcer.parsed {
+		if i, ok := index[d.name]; ok {
+			res[i].Count++
+			continue
+		}
+
+		info := VariableInfo{
+			Name:  d.name,
+			Pos:   d.pos,
+			Count: 1,
+		}
+
+		if val, ok := b.values[d.name]; ok {
+			info.Bound = true
+			info.Value = val
+		} else if val, ok := b.defaults[d.name]; ok {
+			info.Bound = true
+			info.Default = true
+			info.Value = val
+		}
+
+		index[d.name] = len(res)
+		res = append(res, info)
+	}
+
+	return res
+}
+
+// calcValues - bound values supplemented with default values
+func (b *SqlBinder) calcValues() map[string]string {
+	if len(b.defaults) == 0 {
+		return b.values
+	}
+
+	values := copyValues(b.defaults)
+	for k, v := range b.values {
+		values[k] = v
+	}
+
+	return values
+}
+
 // IsVariableParsed - checks whether there is such a variable in the list of parsed
 func (b *SqlBinder) IsVariableParsed(v string) bool {
 	return b.parcer.IsVariableParsed(v)
@@ -491,9 +938,32 @@ func (b *SqlBinder) ParcedVariables() []string {
 	return b.parcer.ParcedVariables()
 }
 
+// MissingVariables - list of variables in the template that have no bound value.
+// Each variable is reported once in order of first occurrence. If the template can't be parsed, nil is returned
+func (b *SqlBinder) MissingVariables() []string {
+	if err := b.parcer.checkParsed(); err != nil {
+		return nil
+	}
+
+	var res []string
+	found := map[string]bool{}
+	for _, d := range b.parcer.parsed {
+		if _, ok := b.values[d.name]; ok || found[d.name] {
+			continue
+		}
+		if _, ok := b.defaults[d.name]; ok {
+			continue
+		}
+		found[d.name] = true
+		res = append(res, d.name)
+	}
+
+	return res
+}
+
 // BindOne - replace the format bind in the Sql string :bind to the value of the value variable
 func BindOne(template string, variable string, value any, key string) (string, error) {
-	binder := NewBinder(template, key)
+	binder := NewBinder(template, WithCacheKey(key))
 	if err := binder.Bind(variable, value); err != nil {
 		return "", err
 	}
@@ -503,7 +973,7 @@ func BindOne(template string, variable string, value any, key string) (string, e
 
 // Bind - сразу биндит и генерит sql
 func Bind(template string, values map[string]any, key string) (string, error) {
-	binder := NewBinder(template, key)
+	binder := NewBinder(template, WithCacheKey(key))
 	if err := binder.BindValues(values); err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package sqlb
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
@@ -135,7 +136,7 @@ func TestSqlBinder_Sql(t *testing.T) {
 
 	for i := 1; i <= 2; i++ { // двойной прогон для проверки кэширования парсинга по ключу
 		for _, test := range tests {
-			binder := NewBinder(test.template, test.name)
+			binder := NewBinder(test.template, WithCacheKey(test.name))
 			if err := binder.Bind(test.variable, test.value); err != nil {
 				t.Errorf("SqlBinder.Sql() %s: %v", test.name, err)
 				return
@@ -196,3 +197,270 @@ func TestSqlBinder_BindTypes(t *testing.T) {
 		t.Fatalf("%s, wants: %s", sql, req)
 	}
 }
+
+func TestSqlBinder_MissingVariables(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE a = :a AND b = :b OR c = :c AND a2 = :a")
+	if err := binder.Bind("b", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	missing := binder.MissingVariables()
+	if len(missing) != 2 || missing[0] != ":a" || missing[1] != ":c" {
+		t.Fatalf("unexpected missing variables: %v", missing)
+	}
+}
+
+func TestSqlBinder_Strict(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE user_id = :user_id")
+	if err := binder.Bind("userid", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := binder.Validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	binder = NewBinder("SELECT * FROM t WHERE user_id = :user_id")
+	binder.SetStrict(true)
+	if err := binder.Bind("userid", 1); err == nil {
+		t.Fatal("expected strict mode error")
+	}
+	if err := binder.Bind("user_id", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := binder.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSqlBinder_BindOver(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t LIMIT :limit")
+	if err := binder.Bind("limit", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := binder.Bind(":limit", 20); err == nil {
+		t.Fatal("expected already binded error")
+	}
+	if err := binder.BindOver("limit", 30); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, err := binder.Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t LIMIT 30"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestSqlBinder_Must(t *testing.T) {
+	sql := NewBinder("SELECT :a, :b").MustBind("a", 1).MustBind("b", 2).MustSql()
+	if req := "SELECT 1, 2"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	MustBindOne("SELECT :a, :b", "a", 1, "")
+}
+
+func TestSqlBinder_Clone(t *testing.T) {
+	base := NewBinder("SELECT * FROM t WHERE tenant = :tenant AND id = :id")
+	base.MustBind("tenant", 1)
+
+	c1 := base.Clone().MustBind("id", 10)
+	c2 := base.Clone().MustBind("id", 20)
+
+	if sql, req := c1.MustSql(), "SELECT * FROM t WHERE tenant = 1 AND id = 10"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if sql, req := c2.MustSql(), "SELECT * FROM t WHERE tenant = 1 AND id = 20"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if missing := base.MissingVariables(); len(missing) != 1 || missing[0] != ":id" {
+		t.Fatalf("base binder was modified: %v", missing)
+	}
+}
+
+func TestSqlBinder_Reset(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE id = :id")
+
+	if _, err := binder.Sql(); err == nil {
+		t.Fatal("expected error for unbound variable")
+	}
+	// ошибка не должна запоминаться
+	if _, err := binder.Sql(); err == nil {
+		t.Fatal("expected error for unbound variable on second call")
+	}
+
+	for i := 1; i <= 3; i++ {
+		binder.Reset()
+		if err := binder.Bind("id", i); err != nil {
+			t.Fatal(err)
+		}
+
+		sql, err := binder.Sql()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req := fmt.Sprintf("SELECT * FROM t WHERE id = %d", i); sql != req {
+			t.Fatalf("%s, wants: %s", sql, req)
+		}
+
+		if err := binder.Bind("id", i); err == nil {
+			t.Fatal("expected bind after calculate error")
+		}
+	}
+}
+
+func TestSqlBinder_BindDefault(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t LIMIT :limit OFFSET :offset")
+	binder.MustBind("limit", 50)
+	if err := binder.BindDefault("limit", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := binder.BindDefault("offset", 0); err != nil {
+		t.Fatal(err)
+	}
+	if missing := binder.MissingVariables(); len(missing) != 0 {
+		t.Fatalf("unexpected missing variables: %v", missing)
+	}
+
+	if sql, req := binder.MustSql(), "SELECT * FROM t LIMIT 50 OFFSET 0"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestSqlBinder_BindIf(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE a = :a")
+	if err := binder.BindIf(false, "a", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := binder.BindIf(true, "a", 2); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM t WHERE a = 2"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestNewBinder_Options(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE d = :d", WithStrictMode(), WithTimeFormat("2006-01-02"))
+	if err := binder.Bind("x", 1); err == nil {
+		t.Fatal("expected strict mode error")
+	}
+
+	binder.MustBind("d", time.Date(2022, 5, 31, 16, 15, 42, 0, time.UTC))
+	if sql, req := binder.MustSql(), "SELECT * FROM t WHERE d = E'2022-05-31'"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	// кавычки и обратная косая черта в формате экранируются
+	binder = NewBinder("SELECT * FROM t WHERE d = :d")
+	binder.MustBind("d", time.Date(2022, 5, 31, 16, 15, 42, 0, time.UTC), TimeFormat(`Jan 2 '06 \`))
+	if sql, req := binder.MustSql(), `SELECT * FROM t WHERE d = E'May 31 \'22 \\'`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestSqlBinder_Recalculate(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE tenant = :tenant AND id > :cursor")
+	binder.MustBind("tenant", 1).MustBind("cursor", 10)
+	if sql, req := binder.MustSql(), "SELECT * FROM t WHERE tenant = 1 AND id > 10"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	binder.Recalculate()
+	if err := binder.BindOver("cursor", 20); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM t WHERE tenant = 1 AND id > 20"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestSqlBinder_JoinErrors(t *testing.T) {
+	binder := NewBinder("SELECT :a, :b, :c", WithJoinErrors())
+	binder.MustBind("a", 1)
+
+	err := binder.BindValues(map[string]any{
+		"a": 2,
+		"b": 3,
+		"":  4,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("expected 2 joined errors, got: %v", err)
+	}
+	if missing := binder.MissingVariables(); len(missing) != 1 || missing[0] != ":c" {
+		t.Fatalf("valid values must be bound: %v", missing)
+	}
+}
+
+func TestSqlBinder_Variables(t *testing.T) {
+	binder := NewBinder("SELECT :a, :b, :a, :c")
+	binder.MustBind("a", 1)
+	if err := binder.BindDefault("b", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := binder.Variables()
+	req := []VariableInfo{
+		{Name: ":a", Pos: 7, Count: 2, Bound: true, Value: "1"},
+		{Name: ":b", Pos: 11, Count: 1, Bound: true, Default: true, Value: "E'x'"},
+		{Name: ":c", Pos: 19, Count: 1},
+	}
+	if len(vars) != len(req) {
+		t.Fatalf("%v, wants: %v", vars, req)
+	}
+	for i := range req {
+		if vars[i] != req[i] {
+			t.Errorf("%v, wants: %v", vars[i], req[i])
+		}
+	}
+}
+
+func TestSqlBinder_BindValuesOptions(t *testing.T) {
+	binder := NewBinder("SELECT :id, :attrs, :doc")
+	err := binder.BindValues(map[string]any{
+		"id":    1,
+		"attrs": V(map[string]int{"a": 1}, Json()),
+		"doc":   V("<a/>", Xml()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sql, req := binder.MustSql(), `SELECT 1, E'{"a":1}', E'<a/>'::xml`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestParser_EscapeString(t *testing.T) {
+	p := NewParser(`SELECT E'a\'b :no', e'\\', 'c\' :x, id_e':y' FROM t`)
+	if err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if vars := p.ParcedVariables(); len(vars) != 1 || vars[0] != ":x" {
+		t.Fatalf("unexpected variables: %v", vars)
+	}
+}
+
+func TestParser_DollarQuoted(t *testing.T) {
+	p := NewParser(`SELECT $$ :no $$, $fn$ ':no' $fn$, "col:no", $1, :a::int, :b FROM t WHERE c = ANY($2)`)
+	if err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if vars := p.ParcedVariables(); len(vars) != 2 || vars[0] != ":a" || vars[1] != ":b" {
+		t.Fatalf("unexpected variables: %v", vars)
+	}
+}
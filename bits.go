@@ -0,0 +1,81 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Bits - render the value as bit string literal B'0101' for bit/varbit columns.
+// Supported values: string of '0' and '1', []bool, integers (non-negative).
+// width > 0 sets the bit length: integers are padded with leading zeros, strings and []bool must have exactly this length
+func Bits(width int) Option {
+	return func(o *options) {
+		o.bits = true
+		o.bitWidth = width
+	}
+}
+
+// bitsToSql - convert value to bit string literal
+func bitsToSql(v any, width int) (string, error) {
+	if width < 0 {
+		return "", nerr.New(fmt.Sprintf("invalid bit width: %d", width))
+	}
+
+	var bits string
+	pad := false
+
+	switch v := v.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		bits = v
+	case []bool:
+		if v == nil {
+			return "null", nil
+		}
+		var sb strings.Builder
+		sb.Grow(len(v))
+		for _, b := range v {
+			if b {
+				sb.WriteByte('1')
+			} else {
+				sb.WriteByte('0')
+			}
+		}
+		bits = sb.String()
+	default:
+		e := reflect.ValueOf(v)
+		if e.CanUint() {
+			bits = strconv.FormatUint(e.Uint(), 2)
+		} else if e.CanInt() {
+			if e.Int() < 0 {
+				return "", nerr.New(fmt.Sprintf("can't convert negative value to bits: %d", e.Int()))
+			}
+			bits = strconv.FormatInt(e.Int(), 2)
+		} else {
+			return "", nerr.New(fmt.Sprintf("can't convert to bits, value: %v", v))
+		}
+		pad = true
+	}
+
+	for i := 0; i < len(bits); i++ {
+		if bits[i] != '0' && bits[i] != '1' {
+			return "", nerr.New(fmt.Sprintf("invalid bit string: %s", bits))
+		}
+	}
+
+	if width > 0 {
+		if len(bits) > width || (!pad && len(bits) != width) {
+			return "", nerr.New(fmt.Sprintf("bit string %s doesn't match width %d", bits, width))
+		}
+		if len(bits) < width {
+			bits = strings.Repeat("0", width-len(bits)) + bits
+		}
+	}
+
+	return "B'" + bits + "'", nil
+}
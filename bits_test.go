@@ -0,0 +1,38 @@
+package sqlb
+
+import "testing"
+
+func TestToSql_Bits(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		width  int
+		result string
+		err    bool
+	}{
+		{"string", "0101", 0, "B'0101'", false},
+		{"bools", []bool{true, false, true}, 0, "B'101'", false},
+		{"uint", uint8(5), 8, "B'00000101'", false},
+		{"int", 5, 0, "B'101'", false},
+		{"bad string", "0121", 0, "", true},
+		{"overflow", 255, 4, "", true},
+		{"width mismatch", "01", 3, "", true},
+		{"negative", -1, 0, "", true},
+	}
+
+	for _, test := range tests {
+		sql, err := ToSql(test.value, Bits(test.width))
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", test.name, sql)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if sql != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, sql, test.result)
+		}
+	}
+}
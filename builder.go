@@ -0,0 +1,34 @@
+package sqlb
+
+import (
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// builderBinder - binder for the template rendered by a builder. The rendering error is returned by the binder methods
+func builderBinder(template string, err error, opts []BinderOption) *SqlBinder {
+	if err != nil {
+		b := newBinder(NewParser(""), newBinderOptions(opts))
+		b.err = err
+		return b
+	}
+
+	return NewBinder(template, opts...)
+}
+
+// checkExpressions - all expressions are not empty
+func checkExpressions(what string, exprs []string) error {
+	for _, expr := range exprs {
+		if len(strings.TrimSpace(expr)) == 0 {
+			return nerr.New("empty " + what)
+		}
+	}
+
+	return nil
+}
+
+// conditionsToSql - conditions combined by AND
+func conditionsToSql(conds []Cond, r *condRenderer) (string, error) {
+	return And(conds...).renderCond(r)
+}
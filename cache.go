@@ -0,0 +1,600 @@
+package sqlb
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// DefaultCacheSize - default maximum number of templates in the parse cache
+const DefaultCacheSize = 10000
+
+// parcedCache - global cache of parsing results by key (see WithCacheKey)
+var parcedCache = NewCache(DefaultCacheSize)
+
+// SetCacheSize - maximum number of templates in the global parse cache (see Cache.SetSize).
+// The least recently used templates are evicted. n <= 0 means no limit
+func SetCacheSize(n int) {
+	parcedCache.SetSize(n)
+}
+
+// SetCacheMaxBytes - limit of the estimated memory used by the templates in the global parse cache (see Cache.SetMaxBytes).
+// n <= 0 means no limit
+func SetCacheMaxBytes(n int64) {
+	parcedCache.SetMaxBytes(n)
+}
+
+// SetCacheTTL - templates not used for longer than ttl are evicted from the global parse cache.
+// ttl <= 0 means no expiration
+func SetCacheTTL(ttl time.Duration) {
+	parcedCache.SetTTL(ttl)
+}
+
+// CacheClear - remove all templates from the global parse cache
+func CacheClear() {
+	parcedCache.Clear()
+}
+
+// CacheDelete - remove the template with the key from the global parse cache
+func CacheDelete(key string) {
+	parcedCache.Delete(key)
+}
+
+// CacheLen - number of templates in the global parse cache
+func CacheLen() int {
+	return parcedCache.Len()
+}
+
+// WarmCache - parse templates (key -> template) into the global parse cache in advance.
+// All templates are processed, errors are combined by errors.Join
+func WarmCache(templates map[string]string) error {
+	return parcedCache.Warm(templates)
+}
+
+// InvalidateCache - invalidate the generations of the global parse cache less than the given one (see Cache.Invalidate)
+func InvalidateCache(generation uint64) {
+	parcedCache.Invalidate(generation)
+}
+
+// WithGeneration - generation of the template in the parse cache (see Cache.Invalidate).
+// A cached template of an older generation is replaced instead of reporting a key collision
+func WithGeneration(generation uint64) BinderOption {
+	return func(o *binderOptions) {
+		o.generation = generation
+	}
+}
+
+// DropCacheNamespace - remove the templates of the namespace from the global parse cache (see Cache.DropNamespace)
+func DropCacheNamespace(namespace string) int {
+	return parcedCache.DropNamespace(namespace)
+}
+
+// WithNamespace - namespace of the cache key, so that the same keys of different modules do not collide.
+// The templates of a namespace can be removed by Cache.DropNamespace
+func WithNamespace(namespace string) BinderOption {
+	return func(o *binderOptions) {
+		o.namespace = namespace
+	}
+}
+
+// namespaceSeparator - separator of the namespace and the key in the cache
+const namespaceSeparator = "\x00"
+
+// namespaceKey - cache key in the namespace
+func namespaceKey(namespace string, key string) string {
+	return namespace + namespaceSeparator + key
+}
+
+// displayKey - cache key for error messages
+func displayKey(key string) string {
+	return strings.Replace(key, namespaceSeparator, "/", 1)
+}
+
+// WithCache - cache of parsing results to use instead of the global one
+func WithCache(c *Cache) BinderOption {
+	return func(o *binderOptions) {
+		o.cache = c
+	}
+}
+
+// WithAutoKey - if the cache key is not set by WithCacheKey, it is calculated as a hash of the template content
+func WithAutoKey() BinderOption {
+	return func(o *binderOptions) {
+		o.autoKey = true
+	}
+}
+
+// CacheStatistics - parse cache statistics
+type CacheStatistics struct {
+	// Hits - number of templates found in the cache
+	Hits uint64
+	// Misses - number of templates parsed and added to the cache
+	Misses uint64
+	// Evictions - number of templates removed due to size limit or expiration
+	Evictions uint64
+	// Entries - current number of templates in the cache
+	Entries int
+	// Bytes - estimated memory used by the templates in the cache
+	Bytes int64
+}
+
+// CacheStats - statistics of the global parse cache
+func CacheStats() CacheStatistics {
+	return parcedCache.Stats()
+}
+
+// TemplateKey - key of the template by its content, same as the key of WithAutoKey
+func TemplateKey(template string) string {
+	return templateKey(template)
+}
+
+// templateKey - cache key from the template content
+func templateKey(template string) string {
+	sum := sha256.Sum256([]byte(template))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// maxCacheShards - maximum number of cache shards
+const maxCacheShards = 16
+
+// minShardEntries - minimum number of elements per shard, small caches are not sharded to keep the exact LRU order
+const minShardEntries = 256
+
+// Cache - LRU cache of parsing results. By default binders use the global cache, a separate cache
+// can be set with WithCache to isolate templates of a service or tenant.
+// The cache is split into shards by key hash, so concurrent binders with different keys do not block each other
+type Cache struct {
+	// Текущее время, подменяется в тестах
+	now    func() time.Time
+	shards []*cacheShard
+	// Количество используемых сегментов. Если лимит элементов меньше числа сегментов, используются только первые,
+	// чтобы лимит соблюдался точно
+	active atomic.Int32
+	// Шаблоны поколений меньше этого устарели
+	minGeneration atomic.Uint64
+
+	// Защищает настройки лимитов
+	mu sync.Mutex
+	// Максимальное количество элементов, <= 0 - без ограничений
+	maxEntries int
+	// Максимальный оценочный размер элементов в байтах, <= 0 - без ограничений
+	maxBytes int64
+}
+
+// cacheShard - part of the cache with its own lock and LRU list
+type cacheShard struct {
+	mu sync.Mutex
+	// Максимальное количество элементов, <= 0 - без ограничений
+	maxEntries int
+	// Максимальный оценочный размер элементов в байтах, <= 0 - без ограничений
+	maxBytes int64
+	// Текущий оценочный размер элементов в байтах
+	bytes int64
+	// Время жизни неиспользуемого элемента, <= 0 - без ограничений
+	ttl time.Duration
+	// Список элементов, в начале - последние использованные
+	ll    *list.List
+	items map[string]*list.Element
+	// Счетчики статистики
+	hits, misses, evictions uint64
+	// Шаблоны, которые парсятся в данный момент
+	inflight map[string]*inflightParse
+	// Сегмент не используется, элементы в него не добавляются
+	disabled bool
+}
+
+// inflightParse - parsing in progress, other goroutines wait for its result instead of parsing the same template
+type inflightParse struct {
+	done   chan struct{}
+	parser *Parser
+}
+
+// cacheEntry - cache element
+type cacheEntry struct {
+	key    string
+	parser *Parser
+	// Оценочный размер в байтах
+	size int64
+	// Поколение, в котором шаблон добавлен. 0 - не зависит от поколений
+	generation uint64
+	// Время последнего использования
+	used time.Time
+}
+
+// NewCache - create a parse cache. maxEntries <= 0 means no limit
+func NewCache(maxEntries int) *Cache {
+	shards := maxCacheShards
+	if maxEntries > 0 {
+		shards = (maxEntries + minShardEntries - 1) / minShardEntries
+		if shards > maxCacheShards {
+			shards = maxCacheShards
+		}
+	}
+
+	return newShardedCache(maxEntries, shards)
+}
+
+// newShardedCache - create a cache with the given number of shards
+func newShardedCache(maxEntries int, shards int) *Cache {
+	c := &Cache{
+		now:    time.Now,
+		shards: make([]*cacheShard, shards),
+	}
+
+	for i := range c.shards {
+		c.shards[i] = &cacheShard{
+			ll:       list.New(),
+			items:    map[string]*list.Element{},
+			inflight: map[string]*inflightParse{},
+		}
+	}
+	c.active.Store(int32(shards))
+	c.setMaxEntries(maxEntries)
+
+	return c
+}
+
+// shard - shard for the key (FNV-1a hash)
+func (c *Cache) shard(key string) *cacheShard {
+	active := uint32(c.active.Load())
+	if active <= 1 {
+		return c.shards[0]
+	}
+
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+
+	return c.shards[h%active]
+}
+
+// get - parser for the key. If there is no such key, the template is parsed and saved
+func (c *Cache) get(key string, template string) *Parser {
+	return c.getGeneration(key, template, 0)
+}
+
+// getGeneration - parser for the key. If there is no such key or the cached template is of an older generation,
+// the template is parsed and saved with the generation
+func (c *Cache) getGeneration(key string, template string, generation uint64) *Parser {
+	s := c.shard(key)
+
+	s.mu.Lock()
+
+	now := c.now()
+	// устаревшие элементы находятся в конце списка, т.к. он упорядочен по времени использования
+	s.evictExpired(now)
+
+	if e, ok := s.items[key]; ok && c.isStale(e.Value.(*cacheEntry), generation) {
+		s.remove(e)
+		s.evictions++
+	} else if ok {
+		s.ll.MoveToFront(e)
+		entry := e.Value.(*cacheEntry)
+		entry.used = now
+		s.hits++
+		s.mu.Unlock()
+
+		if m := currentMetrics(); m != nil {
+			m.CacheHit()
+		}
+		return entry.parser
+	}
+
+	if f, ok := s.inflight[key]; ok {
+		// шаблон уже парсится в другой горутине, ждем ее результата
+		s.hits++
+		s.mu.Unlock()
+
+		<-f.done
+		if m := currentMetrics(); m != nil {
+			m.CacheHit()
+		}
+		return f.parser
+	}
+
+	f := &inflightParse{done: make(chan struct{})}
+	s.inflight[key] = f
+	s.misses++
+	s.mu.Unlock()
+
+	if m := currentMetrics(); m != nil {
+		m.CacheMiss()
+	}
+
+	// парсинг без блокировки, чтобы не задерживать остальные ключи сегмента
+	f.parser = NewParser(template)
+	// ошибка парсинга будет возвращена при вычислении запроса
+	_ = f.parser.checkParsed()
+
+	s.mu.Lock()
+	if e, ok := s.items[key]; ok {
+		// ключ мог быть загружен, пока шел парсинг
+		s.remove(e)
+	}
+	s.add(key, f.parser, generation, c.now())
+	delete(s.inflight, key)
+	s.mu.Unlock()
+
+	close(f.done)
+
+	return f.parser
+}
+
+// isStale - the element belongs to an invalidated generation or is older than the requested one
+func (c *Cache) isStale(entry *cacheEntry, generation uint64) bool {
+	return entry.generation < generation || entry.generation != 0 && entry.generation < c.minGeneration.Load()
+}
+
+// add - add the parser to the shard. The caller must hold the lock
+func (s *cacheShard) add(key string, parser *Parser, generation uint64, now time.Time) {
+	if s.disabled {
+		return
+	}
+
+	size := parserSize(key, parser)
+	if s.maxBytes > 0 && size > s.maxBytes {
+		// шаблон больше лимита сегмента не кэшируется и не вытесняет остальные
+		return
+	}
+
+	entry := &cacheEntry{key: key, parser: parser, generation: generation, used: now, size: size}
+	s.items[key] = s.ll.PushFront(entry)
+	s.bytes += entry.size
+	s.evict()
+}
+
+// remove - remove the element from the shard. The caller must hold the lock
+func (s *cacheShard) remove(e *list.Element) {
+	entry := e.Value.(*cacheEntry)
+	s.ll.Remove(e)
+	delete(s.items, entry.key)
+	s.bytes -= entry.size
+}
+
+// parserSize - estimated memory used by the cached parser: the key, the template and the variables
+func parserSize(key string, parser *Parser) int64 {
+	const entryOverhead = 128
+	const variableOverhead = 64
+
+	return int64(len(key)+len(parser.sqlTemplate)+entryOverhead) + int64(len(parser.parsed))*variableOverhead
+}
+
+// Invalidate - templates added with a generation less than the given one (see WithGeneration) become stale.
+// They are parsed again on the next use, so the same key can get a new template after reloading sql files.
+// Templates added without a generation are not affected
+func (c *Cache) Invalidate(generation uint64) {
+	for {
+		current := c.minGeneration.Load()
+		if generation <= current || c.minGeneration.CompareAndSwap(current, generation) {
+			return
+		}
+	}
+}
+
+// Clear - remove all templates from the cache. Statistics counters are not reset
+func (c *Cache) Clear() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.clear()
+		s.mu.Unlock()
+	}
+}
+
+// clear - remove all elements from the shard. The caller must hold the lock
+func (s *cacheShard) clear() {
+	s.ll.Init()
+	s.items = map[string]*list.Element{}
+	s.bytes = 0
+}
+
+// Delete - remove the template with the key from the cache.
+// Binders already created with this key keep working
+func (c *Cache) Delete(key string) {
+	s := c.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.remove(e)
+	}
+}
+
+// DropNamespace - remove all templates of the namespace (see WithNamespace) from the cache.
+// Returns the number of removed templates
+func (c *Cache) DropNamespace(namespace string) int {
+	if len(namespace) == 0 {
+		return 0
+	}
+
+	prefix := namespace + namespaceSeparator
+	removed := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, e := range s.items {
+			if strings.HasPrefix(key, prefix) {
+				s.remove(e)
+				removed++
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return removed
+}
+
+// Len - number of templates in the cache
+func (c *Cache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.ll.Len()
+		s.mu.Unlock()
+	}
+
+	return n
+}
+
+// Warm - parse templates (key -> template) into the cache in advance, so parse errors are found at startup.
+// All templates are processed, errors are combined by errors.Join
+func (c *Cache) Warm(templates map[string]string) error {
+	// сортируем для стабильного порядка ошибок
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if len(key) == 0 {
+			errs = append(errs, nerr.New("empty cache key"))
+			continue
+		}
+
+		parcer, err := templateParser(c, templates[key], key, 0)
+		if err == nil {
+			err = parcer.checkParsed()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// contains - the key is in the cache
+func (c *Cache) contains(key string) bool {
+	s := c.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.items[key]
+	return ok
+}
+
+// SetSize - maximum number of templates in the cache. n <= 0 means no limit.
+// The limit is divided between the shards exactly, so a template can be evicted before the whole cache is full.
+// If n is less than the number of shards, only n shards are used. Changing the number of used shards clears the cache
+func (c *Cache) SetSize(n int) {
+	c.setMaxEntries(n)
+}
+
+// setMaxEntries - set the limit of elements and distribute it between shards
+func (c *Cache) setMaxEntries(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = n
+	c.applyLimits()
+}
+
+// SetMaxBytes - limit of the estimated memory used by the templates in the cache. The least recently used templates are evicted.
+// The limit is divided between the used shards of the cache, a template larger than its shard part is not cached.
+// n <= 0 means no limit
+func (c *Cache) SetMaxBytes(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxBytes = n
+	c.applyLimits()
+}
+
+// applyLimits - distribute the limits between the shards. The caller must hold c.mu
+func (c *Cache) applyLimits() {
+	active := len(c.shards)
+	if c.maxEntries > 0 && c.maxEntries < active {
+		active = c.maxEntries
+	}
+
+	// при изменении количества сегментов ключи переходят в другие сегменты, поэтому кэш очищается
+	reshard := int(c.active.Load()) != active
+	c.active.Store(int32(active))
+
+	for i, s := range c.shards {
+		s.mu.Lock()
+
+		s.disabled = i >= active
+		if reshard || s.disabled {
+			s.clear()
+		}
+
+		s.maxEntries = c.maxEntries
+		if c.maxEntries > 0 {
+			// остаток от деления распределяется по первым сегментам, сумма лимитов равна общему лимиту
+			s.maxEntries = c.maxEntries / active
+			if i < c.maxEntries%active {
+				s.maxEntries++
+			}
+		}
+
+		s.maxBytes = c.maxBytes
+		if c.maxBytes > 0 {
+			s.maxBytes = (c.maxBytes + int64(active) - 1) / int64(active)
+		}
+
+		s.evict()
+		s.mu.Unlock()
+	}
+}
+
+// SetTTL - templates not used for longer than ttl are evicted. ttl <= 0 means no expiration
+func (c *Cache) SetTTL(ttl time.Duration) {
+	now := c.now()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.ttl = ttl
+		s.evictExpired(now)
+		s.mu.Unlock()
+	}
+}
+
+// Stats - cache statistics
+func (c *Cache) Stats() CacheStatistics {
+	var st CacheStatistics
+	for _, s := range c.shards {
+		s.mu.Lock()
+		st.Hits += s.hits
+		st.Misses += s.misses
+		st.Evictions += s.evictions
+		st.Entries += s.ll.Len()
+		st.Bytes += s.bytes
+		s.mu.Unlock()
+	}
+
+	return st
+}
+
+// evictExpired - remove elements not used for longer than ttl
+func (s *cacheShard) evictExpired(now time.Time) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	for e := s.ll.Back(); e != nil && now.Sub(e.Value.(*cacheEntry).used) > s.ttl; e = s.ll.Back() {
+		s.remove(e)
+		s.evictions++
+	}
+}
+
+// evict - remove the least recently used elements over the limit
+func (s *cacheShard) evict() {
+	for s.ll.Len() > 0 && (s.maxEntries > 0 && s.ll.Len() > s.maxEntries || s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		e := s.ll.Back()
+		s.remove(e)
+		s.evictions++
+	}
+}
@@ -0,0 +1,215 @@
+package sqlb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// cacheFileMagic - signature and version of the saved cache
+const cacheFileMagic = "SQLBC\x01"
+
+// maxSavedString - protection against allocation of huge buffers when reading a damaged file
+const maxSavedString = 64 << 20
+
+// maxPreallocTemplates - the number of templates read from the data is not trusted, the list is preallocated up to this size
+const maxPreallocTemplates = 1024
+
+// SaveCache - save the parsed templates of the global cache (see Cache.Save)
+func SaveCache(w io.Writer) error {
+	return parcedCache.Save(w)
+}
+
+// LoadCache - load the parsed templates into the global cache (see Cache.Load)
+func LoadCache(r io.Reader) error {
+	return parcedCache.Load(r)
+}
+
+// savedTemplate - parsed template for saving
+type savedTemplate struct {
+	key        string
+	generation uint64
+	parser     *Parser
+}
+
+// Save - write the parsed templates to a compact binary form that can be loaded by Load without parsing.
+// Templates with parse errors are not saved
+func (c *Cache) Save(w io.Writer) error {
+	var templates []savedTemplate
+	for _, s := range c.shards {
+		s.mu.Lock()
+		// от давно использованных к последним, чтобы при загрузке сохранился порядок вытеснения
+		for e := s.ll.Back(); e != nil; e = e.Prev() {
+			entry := e.Value.(*cacheEntry)
+			if entry.parser.isParced {
+				templates = append(templates, savedTemplate{key: entry.key, generation: entry.generation, parser: entry.parser})
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	writeUint := func(v uint64) {
+		n := binary.PutUvarint(buf, v)
+		_, _ = bw.Write(buf[:n])
+	}
+	writeString := func(v string) {
+		writeUint(uint64(len(v)))
+		_, _ = bw.WriteString(v)
+	}
+
+	_, _ = bw.WriteString(cacheFileMagic)
+	writeUint(uint64(len(templates)))
+	for _, t := range templates {
+		writeString(t.key)
+		writeUint(t.generation)
+		writeString(t.parser.sqlTemplate)
+		writeUint(uint64(len(t.parser.parsed)))
+		for _, d := range t.parser.parsed {
+			writeUint(uint64(d.pos))
+			writeUint(uint64(len(d.name)))
+		}
+	}
+
+	// ошибки записи bufio.Writer запоминает и возвращает при Flush
+	if err := bw.Flush(); err != nil {
+		return nerr.New(err)
+	}
+
+	return nil
+}
+
+// Load - load the templates saved by Save. Keys already present in the cache are not replaced.
+// Nothing is loaded if the data is damaged
+func (c *Cache) Load(r io.Reader) error {
+	cr := &cacheReader{r: bufio.NewReader(r)}
+
+	magic := make([]byte, len(cacheFileMagic))
+	if _, err := io.ReadFull(cr.r, magic); err != nil || string(magic) != cacheFileMagic {
+		return nerr.New("invalid cache data format")
+	}
+
+	count, err := cr.readInt()
+	if err != nil {
+		return nerr.New(fmt.Sprintf("invalid cache data: %v", err))
+	}
+
+	prealloc := count
+	if prealloc > maxPreallocTemplates {
+		prealloc = maxPreallocTemplates
+	}
+
+	templates := make([]savedTemplate, 0, prealloc)
+	for i := 0; i < count; i++ {
+		t, err := cr.readTemplate()
+		if err != nil {
+			return nerr.New(fmt.Sprintf("invalid cache data, template %d: %v", i, err))
+		}
+		templates = append(templates, t)
+	}
+
+	now := c.now()
+	for _, t := range templates {
+		s := c.shard(t.key)
+		s.mu.Lock()
+		if _, ok := s.items[t.key]; !ok {
+			s.add(t.key, t.parser, t.generation, now)
+		}
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+// cacheReader - reading of the data saved by Cache.Save
+type cacheReader struct {
+	r *bufio.Reader
+}
+
+// readInt - length or position
+func (cr *cacheReader) readInt() (int, error) {
+	v, err := binary.ReadUvarint(cr.r)
+	if err != nil {
+		return 0, err
+	}
+	if v > maxSavedString {
+		return 0, fmt.Errorf("value too large: %d", v)
+	}
+
+	return int(v), nil
+}
+
+func (cr *cacheReader) readString() (string, error) {
+	n, err := cr.readInt()
+	if err != nil {
+		return "", err
+	}
+
+	// буфер растет по мере чтения, поврежденная длина не приводит к выделению памяти заранее
+	var b strings.Builder
+	if _, err := io.CopyN(&b, cr.r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// readTemplate - read one template and restore its parser
+func (cr *cacheReader) readTemplate() (savedTemplate, error) {
+	key, err := cr.readString()
+	if err != nil {
+		return savedTemplate{}, err
+	}
+	if len(key) == 0 {
+		return savedTemplate{}, fmt.Errorf("empty key")
+	}
+
+	generation, err := binary.ReadUvarint(cr.r)
+	if err != nil {
+		return savedTemplate{}, err
+	}
+
+	template, err := cr.readString()
+	if err != nil {
+		return savedTemplate{}, err
+	}
+
+	count, err := cr.readInt()
+	if err != nil {
+		return savedTemplate{}, err
+	}
+
+	parser := NewParser(template)
+	prev := 0
+	for i := 0; i < count; i++ {
+		pos, err := cr.readInt()
+		if err != nil {
+			return savedTemplate{}, err
+		}
+		size, err := cr.readInt()
+		if err != nil {
+			return savedTemplate{}, err
+		}
+
+		// переменные идут по возрастанию позиции и не пересекаются
+		if pos < prev || size < 2 || pos+size > len(template) || template[pos] != ':' {
+			return savedTemplate{}, fmt.Errorf("invalid variable position: %d", pos)
+		}
+		prev = pos + size
+
+		d := &data{name: template[pos : pos+size], pos: pos}
+		parser.parsed = append(parser.parsed, d)
+		parser.parsedMap[d.name] = d
+	}
+	parser.isParced = true
+
+	return savedTemplate{key: key, generation: generation, parser: parser}, nil
+}
@@ -0,0 +1,79 @@
+package sqlb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCache_SaveLoad(t *testing.T) {
+	src := NewCache(0)
+	src.get("q1", "SELECT * FROM t WHERE a = :a AND b = :b -- :c")
+	src.get("q2", "SELECT ':x', :y::int")
+	src.get("bad", "SELECT : FROM t")
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewCache(0)
+	if err := dst.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Len() != 2 || dst.contains("bad") {
+		t.Fatalf("unexpected cache size: %d", dst.Len())
+	}
+
+	for _, key := range []string{"q1", "q2"} {
+		p := dst.get(key, src.get(key, "").SqlTemplate())
+		if !reflect.DeepEqual(p.ParcedVariables(), src.get(key, "").ParcedVariables()) {
+			t.Fatalf("%s: %v, wants: %v", key, p.ParcedVariables(), src.get(key, "").ParcedVariables())
+		}
+	}
+	if dst.Stats().Misses != 0 {
+		t.Fatal("loaded templates must not be parsed")
+	}
+
+	b := NewBinder("SELECT * FROM t WHERE a = :a AND b = :b -- :c", WithCacheKey("q1"), WithCache(dst))
+	if sql, req := b.MustBind("a", 1).MustBind("b", "x").MustSql(), "SELECT * FROM t WHERE a = 1 AND b = E'x' -- :c"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestCache_LoadDamaged(t *testing.T) {
+	src := NewCache(0)
+	src.get("q", "SELECT :a")
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	for _, damaged := range [][]byte{nil, []byte("garbage"), data[:len(data)-1]} {
+		c := NewCache(0)
+		if err := c.Load(bytes.NewReader(damaged)); err == nil {
+			t.Fatalf("expected error for %q", damaged)
+		}
+		if c.Len() != 0 {
+			t.Fatal("nothing must be loaded")
+		}
+	}
+
+	// огромное количество шаблонов и длина ключа в заголовке не приводят к выделению памяти
+	huge := []byte(cacheFileMagic)
+	huge = binary.AppendUvarint(huge, maxSavedString)
+	huge = binary.AppendUvarint(huge, maxSavedString)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := NewCache(0).Load(bytes.NewReader(huge)); err == nil {
+		t.Fatal("expected error for truncated data")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Fatalf("unexpected allocated memory: %d", n)
+	}
+}
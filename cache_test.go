@@ -0,0 +1,401 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCache_LRU(t *testing.T) {
+	c := NewCache(2)
+
+	a := c.get("a", "SELECT :a")
+	c.get("b", "SELECT :b")
+	if c.get("a", "SELECT :a") != a {
+		t.Fatal("expected cached parser")
+	}
+
+	// вытесняется b, как давно не использованный
+	c.get("c", "SELECT :c")
+	if c.contains("b") {
+		t.Fatal("b must be evicted")
+	}
+	if !c.contains("a") {
+		t.Fatal("a must stay in cache")
+	}
+
+	c.SetSize(1)
+	if n := c.Stats().Entries; n != 1 {
+		t.Fatalf("unexpected cache size: %d", n)
+	}
+}
+
+func TestParseCache_TTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	// один сегмент, чтобы устаревание a проверялось при обращении к b
+	c := newShardedCache(0, 1)
+	c.now = func() time.Time { return now }
+	c.SetTTL(time.Minute)
+
+	c.get("a", "SELECT :a")
+	now = now.Add(30 * time.Second)
+	c.get("b", "SELECT :b")
+	now = now.Add(40 * time.Second)
+
+	// a не использовался 70 секунд
+	c.get("b", "SELECT :b")
+	if c.contains("a") {
+		t.Fatal("a must expire")
+	}
+	if !c.contains("b") {
+		t.Fatal("b must stay in cache")
+	}
+}
+
+func TestNewBinder_AutoKey(t *testing.T) {
+	const tmpl = "SELECT * FROM t WHERE auto_key = :id"
+
+	b1 := NewBinder(tmpl, WithAutoKey())
+	b2 := NewBinder(tmpl, WithAutoKey())
+	if b1.parcer != b2.parcer {
+		t.Fatal("expected shared parser")
+	}
+	if NewBinder(tmpl).parcer == b1.parcer {
+		t.Fatal("binder without key must not use the cache")
+	}
+	if sql, req := b2.MustBind("id", 1).MustSql(), "SELECT * FROM t WHERE auto_key = 1"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestNewBinder_KeyCollision(t *testing.T) {
+	NewBinder("SELECT * FROM t WHERE a = :v", WithCacheKey("collision_test"))
+
+	// шаблон той же длины
+	b := NewBinder("SELECT * FROM t WHERE b = :v", WithCacheKey("collision_test"))
+	if b.Err() == nil {
+		t.Fatal("expected key collision error")
+	}
+	if err := b.Bind("v", 1); err == nil {
+		t.Fatal("expected error from Bind")
+	}
+	if _, err := b.Sql(); err == nil {
+		t.Fatal("expected error from Sql")
+	}
+}
+
+func TestParseCache_Stats(t *testing.T) {
+	c := NewCache(1)
+
+	c.get("a", "SELECT :a")
+	c.get("a", "SELECT :a")
+	c.get("b", "SELECT :b")
+
+	st := c.Stats()
+	if st.Hits != 1 || st.Misses != 2 || st.Evictions != 1 || st.Entries != 1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestNewBinder_WithCache(t *testing.T) {
+	c1 := NewCache(0)
+	c2 := NewCache(0)
+
+	// одинаковые ключи в разных кэшах не конфликтуют
+	b1 := NewBinder("SELECT * FROM t WHERE a = :v", WithCacheKey("by_id"), WithCache(c1))
+	b2 := NewBinder("SELECT * FROM t WHERE b = :v", WithCacheKey("by_id"), WithCache(c2))
+	if b1.Err() != nil || b2.Err() != nil {
+		t.Fatalf("unexpected errors: %v, %v", b1.Err(), b2.Err())
+	}
+
+	if c1.Stats().Entries != 1 || c2.Stats().Entries != 1 {
+		t.Fatal("expected one entry in each cache")
+	}
+	if parcedCache.contains("by_id") {
+		t.Fatal("global cache must not be used")
+	}
+}
+
+func TestCache_Shards(t *testing.T) {
+	c := NewCache(0)
+	if len(c.shards) != maxCacheShards {
+		t.Fatalf("unexpected shards: %d", len(c.shards))
+	}
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if p := c.get(key, "SELECT :"+key); c.get(key, "SELECT :"+key) != p {
+			t.Fatalf("%s: expected cached parser", key)
+		}
+	}
+	if st := c.Stats(); st.Entries != 100 || st.Hits != 100 || st.Misses != 100 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	c.SetSize(32)
+	if n := c.Stats().Entries; n > 32 {
+		t.Fatalf("unexpected cache size: %d", n)
+	}
+}
+
+func TestCache_SetSizeExact(t *testing.T) {
+	c := NewCache(0)
+
+	for _, size := range []int{10, 37, 1} {
+		c.SetSize(size)
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("k%d", i)
+			c.get(key, "SELECT :"+key)
+			if n := c.Len(); n > size {
+				t.Fatalf("cache size %d exceeds the limit %d", n, size)
+			}
+		}
+	}
+
+	// лимит меньше количества сегментов соблюдается точно
+	c.SetSize(10)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("k%d", i)
+		c.get(key, "SELECT :"+key)
+	}
+	if n := c.Len(); n != 10 {
+		t.Fatalf("unexpected cache size: %d", n)
+	}
+
+	c.SetSize(0)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("k%d", i)
+		c.get(key, "SELECT :"+key)
+	}
+	if n := c.Len(); n != 100 {
+		t.Fatalf("unexpected cache size: %d", n)
+	}
+}
+
+func BenchmarkCache_Parallel(b *testing.B) {
+	keys := make([]string, 64)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("bench_%d", i)
+	}
+
+	for _, shards := range []int{1, maxCacheShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			c := newShardedCache(0, shards)
+			for _, k := range keys {
+				c.get(k, "SELECT * FROM t WHERE id = :id")
+			}
+
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					c.get(keys[i%len(keys)], "SELECT * FROM t WHERE id = :id")
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestCache_Management(t *testing.T) {
+	c := NewCache(0)
+	c.get("a", "SELECT :a")
+	c.get("b", "SELECT :b")
+	if c.Len() != 2 {
+		t.Fatalf("unexpected cache size: %d", c.Len())
+	}
+
+	c.Delete("a")
+	c.Delete("unknown")
+	if c.contains("a") || !c.contains("b") || c.Len() != 1 {
+		t.Fatal("only a must be deleted")
+	}
+
+	// после удаления ключ можно использовать для другого шаблона
+	b := NewBinder("SELECT :x", WithCacheKey("b"), WithCache(c))
+	if b.Err() == nil {
+		t.Fatal("expected key collision error")
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Fatalf("unexpected cache size: %d", c.Len())
+	}
+	if b = NewBinder("SELECT :x", WithCacheKey("b"), WithCache(c)); b.Err() != nil {
+		t.Fatal(b.Err())
+	}
+}
+
+func TestCache_Warm(t *testing.T) {
+	c := NewCache(0)
+	c.get("dup", "SELECT :a")
+
+	err := c.Warm(map[string]string{
+		"ok":  "SELECT * FROM t WHERE id = :id",
+		"bad": "SELECT * FROM t WHERE a = : AND b = 1",
+		"dup": "SELECT :b",
+		"":    "SELECT 1",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 3 {
+		t.Fatalf("unexpected number of errors: %d, %v", n, err)
+	}
+
+	if !c.contains("ok") {
+		t.Fatal("ok must be cached")
+	}
+	if c.Stats().Misses != 3 {
+		t.Fatalf("unexpected stats: %+v", c.Stats())
+	}
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	c := NewCache(0)
+	small := c.get("small", "SELECT :a")
+	size := parserSize("small", small)
+	c.SetMaxBytes((size*2 + 1) * int64(len(c.shards)))
+
+	c.get("small", "SELECT :a")
+	// шаблон больше лимита не кэшируется
+	c.get("huge", "SELECT :a"+strings.Repeat(" ", int(size*3)))
+	if c.contains("huge") || !c.contains("small") {
+		t.Fatal("huge template must not be cached")
+	}
+	if st := c.Stats(); st.Bytes != size || st.Evictions != 0 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	c.Delete("small")
+	if c.Stats().Bytes != 0 {
+		t.Fatalf("unexpected cache bytes: %d", c.Stats().Bytes)
+	}
+}
+
+func TestCache_MaxBytesOversized(t *testing.T) {
+	c := newShardedCache(0, 1)
+	keys := []string{"a", "b", "c"}
+	var size int64
+	for _, key := range keys {
+		size += parserSize(key, c.get(key, "SELECT :"+key))
+	}
+	c.SetMaxBytes(size * 2)
+
+	// шаблон больше лимита не вытесняет уже закэшированные
+	c.get("huge", "SELECT :a"+strings.Repeat(" ", int(size*3)))
+	if c.contains("huge") {
+		t.Fatal("huge template must not be cached")
+	}
+	for _, key := range keys {
+		if !c.contains(key) {
+			t.Fatalf("%s must survive the oversized template", key)
+		}
+	}
+	if st := c.Stats(); st.Bytes != size || st.Evictions != 0 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	c := NewCache(0)
+	c.get("static", "SELECT 1")
+
+	b := NewBinder("SELECT :a", WithCacheKey("q"), WithCache(c), WithGeneration(1))
+	if b.Err() != nil {
+		t.Fatal(b.Err())
+	}
+
+	// новый шаблон той же генерации - коллизия
+	if b = NewBinder("SELECT :b", WithCacheKey("q"), WithCache(c), WithGeneration(1)); b.Err() == nil {
+		t.Fatal("expected key collision error")
+	}
+
+	// шаблон новой генерации заменяет старый
+	if b = NewBinder("SELECT :b", WithCacheKey("q"), WithCache(c), WithGeneration(2)); b.Err() != nil {
+		t.Fatal(b.Err())
+	}
+
+	c.Invalidate(3)
+	if b = NewBinder("SELECT :c", WithCacheKey("q"), WithCache(c)); b.Err() != nil {
+		t.Fatal(b.Err())
+	}
+	if sql, req := b.MustBind("c", 1).MustSql(), "SELECT 1"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	// шаблоны без генерации не устаревают
+	c.Invalidate(10)
+	misses := c.Stats().Misses
+	if c.get("static", "SELECT 1"); c.Stats().Misses != misses {
+		t.Fatalf("static template must stay in cache: %+v", c.Stats())
+	}
+}
+
+func TestCache_Namespace(t *testing.T) {
+	c := NewCache(0)
+
+	a := NewBinder("SELECT * FROM a WHERE id = :id", WithCacheKey("by_id"), WithCache(c), WithNamespace("a"))
+	b := NewBinder("SELECT * FROM b WHERE id = :id", WithCacheKey("by_id"), WithCache(c), WithNamespace("b"))
+	if a.Err() != nil || b.Err() != nil {
+		t.Fatalf("unexpected errors: %v, %v", a.Err(), b.Err())
+	}
+	NewBinder("SELECT 1", WithCacheKey("by_id"), WithCache(c))
+
+	err := NewBinder("SELECT 2", WithCacheKey("by_id"), WithCache(c), WithNamespace("a")).Err()
+	if err == nil || !strings.Contains(err.Error(), "a/by_id") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := c.DropNamespace("a"); n != 1 {
+		t.Fatalf("unexpected number of removed templates: %d", n)
+	}
+	if c.Len() != 2 || !c.contains(namespaceKey("b", "by_id")) || !c.contains("by_id") {
+		t.Fatal("only namespace a must be removed")
+	}
+}
+
+// parseCounter - Metrics counting parses
+type parseCounter struct {
+	parses atomic.Int64
+}
+
+func (m *parseCounter) CacheHit()                          {}
+func (m *parseCounter) CacheMiss()                         {}
+func (m *parseCounter) Parse(_ time.Duration, _ error)     { m.parses.Add(1) }
+func (m *parseCounter) Calculate(_ time.Duration, _ error) {}
+func (m *parseCounter) BindError(_ string, _ error)        {}
+
+func TestCache_Singleflight(t *testing.T) {
+	m := &parseCounter{}
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	template := "SELECT * FROM t WHERE " + strings.Repeat("a = :a AND ", 10000) + "TRUE"
+	c := NewCache(0)
+
+	const n = 20
+	parsers := make([]*Parser, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			parsers[i] = c.get("report", template)
+		}(i)
+	}
+	wg.Wait()
+
+	if m.parses.Load() != 1 {
+		t.Fatalf("unexpected number of parses: %d", m.parses.Load())
+	}
+	for _, p := range parsers {
+		if p != parsers[0] {
+			t.Fatal("expected shared parser")
+		}
+	}
+	if st := c.Stats(); st.Misses != 1 || st.Hits != n-1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
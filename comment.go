@@ -0,0 +1,130 @@
+package sqlb
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+type queryTagsKey struct{}
+
+// WithQueryTags - context with tags of the query comment (route, traceparent etc.), see WithComment.
+// Tags are added to the tags already stored in the context, an empty value removes the tag
+func WithQueryTags(ctx context.Context, tags map[string]string) context.Context {
+	res := QueryTagsFrom(ctx)
+	if res == nil {
+		res = make(map[string]string, len(tags))
+	}
+
+	for k, v := range tags {
+		if len(v) == 0 {
+			delete(res, k)
+			continue
+		}
+		res[k] = v
+	}
+
+	return context.WithValue(ctx, queryTagsKey{}, res)
+}
+
+// WithQueryTag - context with a single tag of the query comment. See WithQueryTags
+func WithQueryTag(ctx context.Context, key, value string) context.Context {
+	return WithQueryTags(ctx, map[string]string{key: value})
+}
+
+// QueryTagsFrom - copy of the tags stored in the context. See WithQueryTags
+func QueryTagsFrom(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(queryTagsKey{}).(map[string]string)
+	if tags == nil {
+		return nil
+	}
+
+	res := make(map[string]string, len(tags))
+	for k, v := range tags {
+		res[k] = v
+	}
+
+	return res
+}
+
+var staticQueryTags atomic.Pointer[map[string]string]
+
+// SetQueryTags - tags added to every query comment, e.g. {"app": "billing"}. Tags of the context take precedence. nil disables
+func SetQueryTags(tags map[string]string) {
+	if tags == nil {
+		staticQueryTags.Store(nil)
+		return
+	}
+
+	m := make(map[string]string, len(tags))
+	for k, v := range tags {
+		m[k] = v
+	}
+	staticQueryTags.Store(&m)
+}
+
+// Comment - sqlcommenter comment for the tags of SetQueryTags and the context: /*app='svc',route='GET%20%2Fusers'*/.
+// Keys are sorted, keys and values are url encoded, so the comment can't be closed by the value. Empty if there are no tags
+func Comment(ctx context.Context) string {
+	tags := map[string]string{}
+	if static := staticQueryTags.Load(); static != nil {
+		for k, v := range *static {
+			tags[k] = v
+		}
+	}
+	for k, v := range QueryTagsFrom(ctx) {
+		tags[k] = v
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		if len(k) > 0 && len(v) > 0 {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		items[i] = commentEscape(k) + "='" + commentEscape(tags[k]) + "'"
+	}
+
+	return "/*" + strings.Join(items, ",") + "*/"
+}
+
+// Annotate - sql with the comment of the context (see Comment) appended. The comment is placed before the trailing semicolon
+func Annotate(ctx context.Context, sql string) string {
+	return appendComment(sql, Comment(ctx))
+}
+
+// WithComment - append the comment with the tags of the context to the query (see Comment),
+// so slow queries in pg_stat_activity can be attributed to the call sites
+func WithComment(ctx context.Context) BinderOption {
+	return func(o *binderOptions) {
+		o.comment = Comment(ctx)
+	}
+}
+
+// commentEscape - url encoding of the key or value. Spaces are encoded as %20
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// appendComment - add the comment to the end of the query
+func appendComment(sql string, comment string) string {
+	if len(comment) == 0 {
+		return sql
+	}
+
+	trimmed := strings.TrimRight(sql, " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return strings.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n") + " " + comment + ";"
+	}
+
+	return trimmed + " " + comment
+}
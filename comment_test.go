@@ -0,0 +1,32 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithComment(t *testing.T) {
+	SetQueryTags(map[string]string{"app": "svc"})
+	defer SetQueryTags(nil)
+
+	ctx := WithQueryTags(context.Background(), map[string]string{"route": "GET /users", "traceparent": "00-abc-01"})
+	ctx = WithQueryTag(ctx, "note", "it's */ done")
+
+	sql, err := Select("id").From("users").WhereCond(Eq("id", 1)).Binder(WithComment(ctx)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT id FROM users WHERE id = 1 /*app='svc',note='it%27s%20%2A%2F%20done',route='GET%20%2Fusers',traceparent='00-abc-01'*/"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if sql, req := Annotate(WithQueryTag(ctx, "note", ""), "SELECT 1;"), "SELECT 1 /*app='svc',route='GET%20%2Fusers',traceparent='00-abc-01'*/;"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	SetQueryTags(nil)
+	if sql := Annotate(context.Background(), "SELECT 1"); sql != "SELECT 1" {
+		t.Fatalf("%s, wants: SELECT 1", sql)
+	}
+}
@@ -0,0 +1,329 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Cond - condition for WHERE and HAVING clauses. Values are converted via ToSql.
+// Conditions are created by Eq, In, And, Expr, etc. and can be nested
+type Cond interface {
+	// renderCond - sql of the condition
+	renderCond(r *condRenderer) (string, error)
+}
+
+// condRenderer - rendering of values and subqueries inside conditions
+type condRenderer struct {
+	opts []Option
+	// Переменные встроенных подзапросов
+	embedded embeddedVars
+	// Значения сгенерированных переменных в режиме подстановки (Placeholders), nil - значения встраиваются в запрос
+	params map[string]any
+}
+
+// subquery - sql template of the subquery. In the placeholder mode builders are rendered
+// with the same generated variables as the outer query
+func (r *condRenderer) subquery(query any) (string, error) {
+	if q, ok := query.(rendererQuery); ok && r.params != nil {
+		sql, err := q.template(&condRenderer{params: r.params})
+		if err != nil {
+			return "", err
+		}
+		return r.embedded.register(Sub(query), sql)
+	}
+
+	return r.embedded.embed(query)
+}
+
+// rendererQuery - builder, which can be rendered by the renderer of the outer query
+type rendererQuery interface {
+	template(r *condRenderer) (string, error)
+}
+
+// value - sql of the value or the generated variable in the placeholder mode. Fragments are always inserted as is
+func (r *condRenderer) value(v any) (string, error) {
+	if f, ok := v.(Fragment); ok {
+		return f.sql, nil
+	}
+
+	if r.params != nil {
+		return r.param(v), nil
+	}
+
+	return ToSql(v, r.opts...)
+}
+
+// array - sql ARRAY of the slice or the generated variable in the placeholder mode. Unlike value, a slice
+// of any element type is rendered as ARRAY
+func (r *condRenderer) array(v any) (string, error) {
+	if r.params != nil {
+		return r.param(v), nil
+	}
+
+	return arrayToSql(reflect.ValueOf(v), "'", true)
+}
+
+// CondSql - render the condition to sql. Values are converted via ToSql with the options
+func CondSql(c Cond, opts ...Option) (string, error) {
+	if c == nil {
+		return "", nerr.New("nil condition")
+	}
+
+	return c.renderCond(&condRenderer{opts: opts})
+}
+
+// exprCond - condition as is
+type exprCond string
+
+// Expr - condition as is, e.g. "a > b". It may contain :variables, if it is used in a builder
+func Expr(sql string) Cond {
+	return exprCond(sql)
+}
+
+func (c exprCond) renderCond(_ *condRenderer) (string, error) {
+	if len(strings.TrimSpace(string(c))) == 0 {
+		return "", nerr.New("empty condition")
+	}
+
+	return string(c), nil
+}
+
+// compareCond - comparison of the column with the value
+type compareCond struct {
+	column string
+	op     string
+	value  any
+	// Оператор для сравнения с null
+	nullOp string
+}
+
+// Eq - column = value. nil value is rendered as column IS NULL
+func Eq(column string, value any) Cond {
+	return &compareCond{column: column, op: "=", value: value, nullOp: "IS NULL"}
+}
+
+// Neq - column <> value. nil value is rendered as column IS NOT NULL
+func Neq(column string, value any) Cond {
+	return &compareCond{column: column, op: "<>", value: value, nullOp: "IS NOT NULL"}
+}
+
+// Lt - column < value
+func Lt(column string, value any) Cond {
+	return &compareCond{column: column, op: "<", value: value}
+}
+
+// Lte - column <= value
+func Lte(column string, value any) Cond {
+	return &compareCond{column: column, op: "<=", value: value}
+}
+
+// Gt - column > value
+func Gt(column string, value any) Cond {
+	return &compareCond{column: column, op: ">", value: value}
+}
+
+// Gte - column >= value
+func Gte(column string, value any) Cond {
+	return &compareCond{column: column, op: ">=", value: value}
+}
+
+// Like - column LIKE pattern
+func Like(column string, pattern string) Cond {
+	return &compareCond{column: column, op: "LIKE", value: pattern}
+}
+
+// ILike - column ILIKE pattern (case insensitive)
+func ILike(column string, pattern string) Cond {
+	return &compareCond{column: column, op: "ILIKE", value: pattern}
+}
+
+// IsNull - column IS NULL
+func IsNull(column string) Cond {
+	return &compareCond{column: column, nullOp: "IS NULL"}
+}
+
+// IsNotNull - column IS NOT NULL
+func IsNotNull(column string) Cond {
+	return &compareCond{column: column, nullOp: "IS NOT NULL"}
+}
+
+func (c *compareCond) renderCond(r *condRenderer) (string, error) {
+	if err := checkExpressions("column", []string{c.column}); err != nil {
+		return "", err
+	}
+
+	if c.value == nil {
+		if len(c.nullOp) == 0 {
+			return "", nerr.New(fmt.Sprintf("%s: null value for operator %s", c.column, c.op))
+		}
+		return c.column + " " + c.nullOp, nil
+	}
+
+	val, err := r.value(c.value)
+	if err != nil {
+		return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+	}
+
+	return c.column + " " + c.op + " " + val, nil
+}
+
+// inCond - column IN (values)
+type inCond struct {
+	column string
+	values any
+	not    bool
+}
+
+// In - column IN (values). values is a slice or an array, an empty list matches nothing
+func In(column string, values any) Cond {
+	return &inCond{column: column, values: values}
+}
+
+// NotIn - column NOT IN (values). values is a slice or an array, an empty list matches everything
+func NotIn(column string, values any) Cond {
+	return &inCond{column: column, values: values, not: true}
+}
+
+func (c *inCond) renderCond(r *condRenderer) (string, error) {
+	if err := checkExpressions("column", []string{c.column}); err != nil {
+		return "", err
+	}
+
+	var items []string
+	if c.values != nil {
+		e := reflect.ValueOf(c.values)
+		if e.Kind() != reflect.Slice && e.Kind() != reflect.Array {
+			return "", nerr.New(fmt.Sprintf("%s: slice expected, got %T", c.column, c.values))
+		}
+
+		items = make([]string, e.Len())
+		for i := 0; i < e.Len(); i++ {
+			var err error
+			if items[i], err = r.value(e.Index(i).Interface()); err != nil {
+				return "", nerr.New(fmt.Sprintf("%s: element %d: %v", c.column, i, err))
+			}
+		}
+	}
+
+	list := emptyInList
+	if len(items) > 0 {
+		list = "(" + strings.Join(items, ", ") + ")"
+	}
+
+	if c.not {
+		return c.column + " NOT IN " + list, nil
+	}
+
+	return c.column + " IN " + list, nil
+}
+
+// betweenCond - column BETWEEN from AND to
+type betweenCond struct {
+	column   string
+	from, to any
+}
+
+// Between - column BETWEEN from AND to
+func Between(column string, from, to any) Cond {
+	return &betweenCond{column: column, from: from, to: to}
+}
+
+func (c *betweenCond) renderCond(r *condRenderer) (string, error) {
+	if err := checkExpressions("column", []string{c.column}); err != nil {
+		return "", err
+	}
+
+	from, err := r.value(c.from)
+	if err != nil {
+		return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+	}
+
+	to, err := r.value(c.to)
+	if err != nil {
+		return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+	}
+
+	return c.column + " BETWEEN " + from + " AND " + to, nil
+}
+
+// logicCond - conditions combined by AND or OR
+type logicCond struct {
+	op    string
+	conds []Cond
+}
+
+// And - all conditions are true. Without conditions it is TRUE
+func And(conds ...Cond) Cond {
+	return &logicCond{op: "AND", conds: conds}
+}
+
+// Or - any of the conditions is true. Without conditions it is FALSE
+func Or(conds ...Cond) Cond {
+	return &logicCond{op: "OR", conds: conds}
+}
+
+func (c *logicCond) renderCond(r *condRenderer) (string, error) {
+	if len(c.conds) == 0 {
+		if c.op == "AND" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	}
+
+	items := make([]string, len(c.conds))
+	for i, cond := range c.conds {
+		if cond == nil {
+			return "", nerr.New("nil condition")
+		}
+
+		sql, err := cond.renderCond(r)
+		if err != nil {
+			return "", err
+		}
+
+		if len(c.conds) > 1 && needParentheses(cond) {
+			sql = "(" + sql + ")"
+		}
+		items[i] = sql
+	}
+
+	return strings.Join(items, " "+c.op+" "), nil
+}
+
+// notCond - negation of the condition
+type notCond struct {
+	cond Cond
+}
+
+// Not - NOT (condition)
+func Not(c Cond) Cond {
+	return &notCond{cond: c}
+}
+
+func (c *notCond) renderCond(r *condRenderer) (string, error) {
+	if c.cond == nil {
+		return "", nerr.New("nil condition")
+	}
+
+	sql, err := c.cond.renderCond(r)
+	if err != nil {
+		return "", err
+	}
+
+	return "NOT (" + sql + ")", nil
+}
+
+// needParentheses - the condition must be enclosed in parentheses when combined with others
+func needParentheses(c Cond) bool {
+	switch c := c.(type) {
+	case *logicCond:
+		return len(c.conds) > 1
+	case exprCond, Fragment, fragmentCond:
+		return true
+	}
+
+	return false
+}
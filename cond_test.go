@@ -0,0 +1,52 @@
+package sqlb
+
+import "testing"
+
+func TestCondSql(t *testing.T) {
+	tests := []struct {
+		name   string
+		cond   Cond
+		result string
+	}{
+		{"eq", Eq("id", 5), "id = 5"},
+		{"eq null", Eq("deleted_at", nil), "deleted_at IS NULL"},
+		{"neq null", Neq("deleted_at", nil), "deleted_at IS NOT NULL"},
+		{"like", Like("name", "a'%"), `name LIKE E'a\'%'`},
+		{"in", In("id", []int{1, 2}), "id IN (1, 2)"},
+		{"in empty", In("id", []int{}), "id IN (SELECT NULL WHERE FALSE)"},
+		{"not in", NotIn("id", []string{"a"}), "id NOT IN (E'a')"},
+		{"between", Between("age", 18, 30), "age BETWEEN 18 AND 30"},
+		{"and", And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3)), Expr("d > e")), "a = 1 AND (b = 2 OR c = 3) AND (d > e)"},
+		{"single or", Or(Eq("a", 1)), "a = 1"},
+		{"empty and", And(), "TRUE"},
+		{"empty or", Or(), "FALSE"},
+		{"not", Not(Or(Eq("a", 1), IsNull("b"))), "NOT (a = 1 OR b IS NULL)"},
+	}
+
+	for _, test := range tests {
+		sql, err := CondSql(test.cond)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if sql != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, sql, test.result)
+		}
+	}
+
+	for _, cond := range []Cond{Gt("a", nil), In("a", 1), Eq("", 1), And(Eq("a", 1), nil), Expr(" ")} {
+		if _, err := CondSql(cond); err == nil {
+			t.Errorf("expected error for %#v", cond)
+		}
+	}
+}
+
+func TestSelectBuilder_WhereCond(t *testing.T) {
+	sql, err := Select("id").From("users").WhereCond(Or(Eq("name", "x:y"), In("id", []int{1}))).Where("age > :age").
+		Q().Set("age", 18).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT id FROM users WHERE (name = E'x:y' OR id IN (1)) AND (age > 18)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
@@ -0,0 +1,133 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/n-r-w/nerr"
+)
+
+// SqlValuer - a type that knows how to represent itself as an sql literal
+type SqlValuer interface {
+	// SqlValue - ready to use sql literal. It is inserted into the query as is
+	SqlValue() (string, error)
+}
+
+// converterFunc - converter of a value to sql literal
+type converterFunc func(v any) (string, error)
+
+var convertersMutex sync.RWMutex
+var converters map[reflect.Type]converterFunc
+
+// RegisterConverter - register a function that converts values of type T to sql literal.
+// The converter takes precedence over the built-in conversion and is also used for the elements of slices of T
+func RegisterConverter[T any](conv func(v T) (string, error)) {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+
+	convertersMutex.Lock()
+	defer convertersMutex.Unlock()
+
+	if converters == nil {
+		converters = make(map[reflect.Type]converterFunc)
+	}
+
+	converters[t] = func(v any) (string, error) {
+		return conv(v.(T))
+	}
+}
+
+// UnregisterConverter - remove the converter registered for type T
+func UnregisterConverter[T any]() {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+
+	convertersMutex.Lock()
+	defer convertersMutex.Unlock()
+
+	delete(converters, t)
+}
+
+// hasConverters - is any converter registered
+func hasConverters() bool {
+	convertersMutex.RLock()
+	defer convertersMutex.RUnlock()
+
+	return len(converters) > 0
+}
+
+// customToSql - conversion via registered converter or SqlValuer. found is false if the type is not custom
+func customToSql(v any) (val string, found bool, err error) {
+	convertersMutex.RLock()
+	conv, ok := converters[reflect.TypeOf(v)]
+	convertersMutex.RUnlock()
+
+	if ok {
+		val, err = conv(v)
+		if err != nil {
+			return "", true, nerr.New(err)
+		}
+		return val, true, nil
+	}
+
+	if sv, ok := v.(SqlValuer); ok {
+		val, err = sv.SqlValue()
+		if err != nil {
+			return "", true, nerr.New(err)
+		}
+		return val, true, nil
+	}
+
+	return "", false, nil
+}
+
+// sqlValuerType - type of the SqlValuer interface
+var sqlValuerType = reflect.TypeOf((*SqlValuer)(nil)).Elem()
+
+// isCustomType - values of the type are converted by a registered converter or SqlValuer
+func isCustomType(t reflect.Type) bool {
+	if t.Implements(sqlValuerType) {
+		return true
+	}
+
+	convertersMutex.RLock()
+	defer convertersMutex.RUnlock()
+
+	_, ok := converters[t]
+	return ok
+}
+
+// arrayToSql - converting a slice or an array to sql ARRAY (or to jsonpath array if escape is off)
+func arrayToSql(e reflect.Value, quote string, escape bool) (string, error) {
+	if e.Kind() == reflect.Slice && e.IsNil() {
+		return "null", nil
+	}
+
+	if e.Len() == 0 {
+		if escape {
+			return `'{}'`, nil
+		}
+		return `[]`, nil
+	}
+
+	items := make([]string, e.Len())
+	for i := 0; i < e.Len(); i++ {
+		var err error
+		if escape {
+			items[i], _, err = toSqlHelper(e.Index(i).Interface(), quote, escape)
+		} else {
+			items[i], err = ToJsonPath(e.Index(i).Interface())
+		}
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("array element %d: %v", i, err))
+		}
+	}
+
+	if escape {
+		return "ARRAY[" + strings.Join(items, ",") + "]", nil
+	}
+
+	return "[" + strings.Join(items, ", ") + "]", nil
+}
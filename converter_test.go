@@ -0,0 +1,48 @@
+package sqlb
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testPoint struct {
+	x, y int
+}
+
+func (p testPoint) SqlValue() (string, error) {
+	return fmt.Sprintf("point(%d,%d)", p.x, p.y), nil
+}
+
+type testCode struct {
+	code string
+}
+
+func TestToSql_Arrays(t *testing.T) {
+	RegisterConverter(func(v testCode) (string, error) {
+		return ToSql("#" + v.code)
+	})
+	defer UnregisterConverter[testCode]()
+
+	tests := []struct {
+		name   string
+		value  any
+		result string
+	}{
+		{"valuer", []testPoint{{1, 2}, {3, 4}}, "ARRAY[point(1,2),point(3,4)]"},
+		{"converter", []testCode{{"a"}, {"b'c"}}, `ARRAY[E'#a',E'#b\'c']`},
+		// срезы обычных типов не преобразуются в ARRAY, для них есть BindArray и Any/Overlaps
+		{"ints", []int{1, 2, 3}, "E'[1 2 3]'"},
+		{"empty", []testCode{}, "'{}'"},
+		{"nil", []testCode(nil), "null"},
+	}
+
+	for _, test := range tests {
+		sql, err := ToSql(test.value)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if sql != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, sql, test.result)
+		}
+	}
+}
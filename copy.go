@@ -0,0 +1,287 @@
+package sqlb
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/n-r-w/nerr"
+)
+
+// CopyFormat - format of COPY ... FROM STDIN data
+type CopyFormat int
+
+const (
+	// CopyText - text format (default COPY format): tab separated, \N for null
+	CopyText CopyFormat = iota
+	// CopyCSV - CSV format (COPY ... WITH (FORMAT csv)): comma separated, empty unquoted value for null
+	CopyCSV
+	// CopyBinary - binary format (COPY ... WITH (FORMAT binary)). See copyBinaryValue for the type mapping
+	CopyBinary
+)
+
+// CopyEncoder - writes rows of Go values in the COPY text, CSV or binary format, for use with COPY ... FROM STDIN.
+// Values are converted like in ToSql: time and duration formats are the same, empty strings are null, slices are arrays.
+// Supported options: Json, NullZero, TimeFormat
+type CopyEncoder struct {
+	w       *bufio.Writer
+	format  CopyFormat
+	opts    []Option
+	columns int
+	// Буфер строки
+	row []string
+	// Заголовок бинарного формата записан
+	started bool
+}
+
+// NewCopyEncoder - create COPY encoder. Call Close after the last row
+func NewCopyEncoder(w io.Writer, format CopyFormat, opts ...Option) *CopyEncoder {
+	return &CopyEncoder{
+		w:       bufio.NewWriter(w),
+		format:  format,
+		opts:    opts,
+		columns: -1,
+	}
+}
+
+// WriteHeader - header line with column names for COPY ... WITH (FORMAT csv, HEADER). Only for CSV format
+func (e *CopyEncoder) WriteHeader(columns ...string) error {
+	if e.format != CopyCSV {
+		return nerr.New("header is supported only for CSV format")
+	}
+
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		values[i] = c
+	}
+
+	return e.Encode(values...)
+}
+
+// Encode - write one row. All rows must have the same number of values
+func (e *CopyEncoder) Encode(values ...any) error {
+	if e.columns < 0 {
+		e.columns = len(values)
+	} else if e.columns != len(values) {
+		return nerr.New(fmt.Sprintf("%d values, expected %d", len(values), e.columns))
+	}
+
+	if e.format == CopyBinary {
+		return e.encodeBinary(values)
+	}
+
+	e.row = e.row[:0]
+	for i, v := range values {
+		val, null, err := copyValue(v, e.opts)
+		if err != nil {
+			return nerr.New(fmt.Sprintf("value %d: %v", i, err))
+		}
+
+		switch {
+		case e.format == CopyCSV && null:
+			e.row = append(e.row, "")
+		case e.format == CopyCSV:
+			e.row = append(e.row, csvEscape(val))
+		case null:
+			e.row = append(e.row, `\N`)
+		default:
+			e.row = append(e.row, copyTextEscape(val))
+		}
+	}
+
+	sep := "\t"
+	if e.format == CopyCSV {
+		sep = ","
+	}
+
+	if _, err := e.w.WriteString(strings.Join(e.row, sep) + "\n"); err != nil {
+		return nerr.New(err)
+	}
+
+	return nil
+}
+
+// Close - finish the data (the trailer of the binary format) and flush it. The encoder can't be used after Close
+func (e *CopyEncoder) Close() error {
+	if e.format == CopyBinary {
+		if err := e.writeBinaryHeader(); err != nil {
+			return err
+		}
+		if _, err := e.w.Write([]byte{0xff, 0xff}); err != nil {
+			return nerr.New(err)
+		}
+	}
+
+	return e.Flush()
+}
+
+// Flush - write the buffered data to the underlying writer
+func (e *CopyEncoder) Flush() error {
+	if err := e.w.Flush(); err != nil {
+		return nerr.New(err)
+	}
+
+	return nil
+}
+
+// copyTextEscape - escaping for the text format
+func copyTextEscape(s string) string {
+	if !strings.ContainsAny(s, "\\\t\n\r") {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	return r.Replace(s)
+}
+
+// csvEscape - quoting for the CSV format. Empty string is quoted to differ from null
+func csvEscape(s string) string {
+	if len(s) > 0 && s != `\.` && !strings.ContainsAny(s, ",\"\n\r") {
+		return s
+	}
+
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// copyValue - value in the COPY format before escaping
+func copyValue(v any, opts []Option) (string, bool, error) {
+	if val, ok := v.(Value); ok {
+		v = val.value
+		opts = append(opts[:len(opts):len(opts)], val.opts...)
+	}
+
+	o := newOptions(opts)
+	if o.nullZero && isZero(v) {
+		return "", true, nil
+	}
+
+	if o.json {
+		if v == nil {
+			return "", true, nil
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false, nerr.New(err)
+		}
+		return string(data), false, nil
+	}
+
+	if len(o.timeFormat) > 0 {
+		if t, ok := v.(time.Time); ok {
+			return t.Format(o.timeFormat), false, nil
+		}
+	}
+
+	if o.xml != xmlNone || o.bits {
+		return "", false, nerr.New("xml and bits options are not supported by COPY encoder")
+	}
+
+	return copyPlainValue(v)
+}
+
+// copyPlainValue - value without options
+func copyPlainValue(v any) (string, bool, error) {
+	if v == nil {
+		return "", true, nil
+	}
+
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.000000 -0700"), false, nil
+	case time.Duration:
+		sql, _, err := toSqlHelper(v, "", false)
+		return sql, false, err
+	case string:
+		s := strings.TrimSpace(v)
+		// как и в ToSql, пустая строка - null
+		return s, len(s) == 0, nil
+	case bool:
+		return strconv.FormatBool(v), false, nil
+	case []byte:
+		if v == nil {
+			return "", true, nil
+		}
+		return `\x` + hex.EncodeToString(v), false, nil
+	case json.RawMessage:
+		if len(v) == 0 {
+			return "", true, nil
+		}
+		return string(v), false, nil
+	case uuid.UUID:
+		return v.String(), false, nil
+	case fmt.Stringer:
+		if e := reflect.ValueOf(v); e.Kind() == reflect.Pointer && e.IsNil() {
+			return "", true, nil
+		}
+		return v.String(), false, nil
+	}
+
+	e := reflect.ValueOf(v)
+	switch {
+	case e.Kind() == reflect.Pointer:
+		if e.IsNil() {
+			return "", true, nil
+		}
+		return copyPlainValue(e.Elem().Interface())
+	case e.Kind() == reflect.Slice || e.Kind() == reflect.Array:
+		if e.Kind() == reflect.Slice && e.IsNil() {
+			return "", true, nil
+		}
+		arr, err := copyArray(e)
+		return arr, false, err
+	case e.CanInt():
+		return strconv.FormatInt(e.Int(), 10), false, nil
+	case e.CanUint():
+		return strconv.FormatUint(e.Uint(), 10), false, nil
+	case e.CanFloat():
+		return strconv.FormatFloat(e.Float(), 'f', -1, 64), false, nil
+	case e.Kind() == reflect.String:
+		s := strings.TrimSpace(e.String())
+		return s, len(s) == 0, nil
+	case e.Kind() == reflect.Bool:
+		return strconv.FormatBool(e.Bool()), false, nil
+	}
+
+	return "", false, nerr.New(fmt.Sprintf("unsupported type %T", v))
+}
+
+// copyArray - array literal {a,b,"c d"}
+func copyArray(e reflect.Value) (string, error) {
+	items := make([]string, e.Len())
+	for i := range items {
+		item := e.Index(i)
+		if item.Kind() == reflect.Slice && item.Type().Elem().Kind() != reflect.Uint8 {
+			if item.IsNil() {
+				items[i] = "NULL"
+				continue
+			}
+			// вложенный массив
+			arr, err := copyArray(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = arr
+			continue
+		}
+
+		val, null, err := copyPlainValue(item.Interface())
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("array element %d: %v", i, err))
+		}
+
+		if null {
+			items[i] = "NULL"
+		} else {
+			items[i] = arrayLiteralItem(val)
+		}
+	}
+
+	return "{" + strings.Join(items, ",") + "}", nil
+}
@@ -0,0 +1,270 @@
+package sqlb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/n-r-w/nerr"
+)
+
+// copyBinarySignature - signature of the binary COPY format
+const copyBinarySignature = "PGCOPY\n\xff\r\n\x00"
+
+// Идентификаторы типов PostgreSql для элементов массивов
+const (
+	oidBool        = 16
+	oidBytea       = 17
+	oidInt8        = 20
+	oidInt2        = 21
+	oidInt4        = 23
+	oidText        = 25
+	oidFloat4      = 700
+	oidFloat8      = 701
+	oidTimestamptz = 1184
+	oidInterval    = 1186
+	oidUUID        = 2950
+	oidJsonb       = 3802
+)
+
+// pgEpoch - начало отсчета времени в PostgreSql
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeBinaryHeader - signature, flags and header extension length
+func (e *CopyEncoder) writeBinaryHeader() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	if _, err := e.w.WriteString(copyBinarySignature + "\x00\x00\x00\x00\x00\x00\x00\x00"); err != nil {
+		return nerr.New(err)
+	}
+
+	return nil
+}
+
+// encodeBinary - tuple: field count and length-prefixed fields, -1 length for null
+func (e *CopyEncoder) encodeBinary(values []any) error {
+	if len(values) > math.MaxInt16 {
+		return nerr.New(fmt.Sprintf("too many values: %d", len(values)))
+	}
+
+	tuple := binary.BigEndian.AppendUint16(nil, uint16(len(values)))
+	for i, v := range values {
+		data, null, err := copyBinaryValue(v, e.opts)
+		if err != nil {
+			return nerr.New(fmt.Sprintf("value %d: %v", i, err))
+		}
+		tuple = appendBinaryField(tuple, data, null)
+	}
+
+	if err := e.writeBinaryHeader(); err != nil {
+		return err
+	}
+
+	if _, err := e.w.Write(tuple); err != nil {
+		return nerr.New(err)
+	}
+
+	return nil
+}
+
+func appendBinaryField(buf, data []byte, null bool) []byte {
+	if null {
+		return binary.BigEndian.AppendUint32(buf, math.MaxUint32)
+	}
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	return append(buf, data...)
+}
+
+// copyBinaryValue - value in the binary format. The column type is defined by the Go type:
+// int8, int16, uint8 - int2; int32, uint16 - int4; int, int64, uint32, uint, uint64 - int8; float32 - float4; float64 - float8;
+// bool; string - text; []byte - bytea; time.Time - timestamptz; time.Duration - interval; uuid.UUID - uuid;
+// json.RawMessage and Json option - jsonb; TimeFormat option and fmt.Stringer - text; slices - one-dimensional arrays
+func copyBinaryValue(v any, opts []Option) ([]byte, bool, error) {
+	if val, ok := v.(Value); ok {
+		v = val.value
+		opts = append(opts[:len(opts):len(opts)], val.opts...)
+	}
+
+	o := newOptions(opts)
+	if o.nullZero && isZero(v) {
+		return nil, true, nil
+	}
+
+	if o.json {
+		if v == nil {
+			return nil, true, nil
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, false, nerr.New(err)
+		}
+		return append([]byte{1}, data...), false, nil
+	}
+
+	if len(o.timeFormat) > 0 {
+		if t, ok := v.(time.Time); ok {
+			return []byte(t.Format(o.timeFormat)), false, nil
+		}
+	}
+
+	if o.xml != xmlNone || o.bits {
+		return nil, false, nerr.New("xml and bits options are not supported by COPY encoder")
+	}
+
+	data, _, null, err := copyBinaryPlain(v)
+	return data, null, err
+}
+
+// copyBinaryPlain - value without options and the type identifier
+func copyBinaryPlain(v any) ([]byte, uint32, bool, error) {
+	if v == nil {
+		return nil, 0, true, nil
+	}
+
+	be := binary.BigEndian
+	switch v := v.(type) {
+	case time.Time:
+		return be.AppendUint64(nil, uint64(v.Sub(pgEpoch).Microseconds())), oidTimestamptz, false, nil
+	case time.Duration:
+		// те же ограничения, что и в ToSql
+		if v.Seconds() > 60*60*24 {
+			return nil, 0, false, nerr.New(fmt.Sprintf("can't bind time.Duration, value: %v", v))
+		}
+		micros := int64(v.Seconds()) * 1000000
+		return append(be.AppendUint64(nil, uint64(micros)), 0, 0, 0, 0, 0, 0, 0, 0), oidInterval, false, nil
+	case string:
+		s := strings.TrimSpace(v)
+		// как и в ToSql, пустая строка - null
+		return []byte(s), oidText, len(s) == 0, nil
+	case bool:
+		if v {
+			return []byte{1}, oidBool, false, nil
+		}
+		return []byte{0}, oidBool, false, nil
+	case []byte:
+		return v, oidBytea, v == nil, nil
+	case json.RawMessage:
+		if len(v) == 0 {
+			return nil, oidJsonb, true, nil
+		}
+		return append([]byte{1}, v...), oidJsonb, false, nil
+	case uuid.UUID:
+		return v[:], oidUUID, false, nil
+	case fmt.Stringer:
+		if e := reflect.ValueOf(v); e.Kind() == reflect.Pointer && e.IsNil() {
+			return nil, oidText, true, nil
+		}
+		return []byte(v.String()), oidText, false, nil
+	}
+
+	e := reflect.ValueOf(v)
+	switch e.Kind() {
+	case reflect.Pointer:
+		if e.IsNil() {
+			return nil, 0, true, nil
+		}
+		return copyBinaryPlain(e.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if e.Kind() == reflect.Slice && e.IsNil() {
+			return nil, 0, true, nil
+		}
+		data, err := copyBinaryArray(e)
+		return data, 0, false, err
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return be.AppendUint16(nil, uint16(reflectInt(e))), oidInt2, false, nil
+	case reflect.Int32, reflect.Uint16:
+		return be.AppendUint32(nil, uint32(reflectInt(e))), oidInt4, false, nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return be.AppendUint64(nil, uint64(reflectInt(e))), oidInt8, false, nil
+	case reflect.Uint, reflect.Uint64:
+		if e.Uint() > math.MaxInt64 {
+			return nil, 0, false, nerr.New(fmt.Sprintf("value %d overflows int8", e.Uint()))
+		}
+		return be.AppendUint64(nil, e.Uint()), oidInt8, false, nil
+	case reflect.Float32:
+		return be.AppendUint32(nil, math.Float32bits(float32(e.Float()))), oidFloat4, false, nil
+	case reflect.Float64:
+		return be.AppendUint64(nil, math.Float64bits(e.Float())), oidFloat8, false, nil
+	case reflect.String:
+		s := strings.TrimSpace(e.String())
+		return []byte(s), oidText, len(s) == 0, nil
+	case reflect.Bool:
+		return copyBinaryPlain(e.Bool())
+	}
+
+	return nil, 0, false, nerr.New(fmt.Sprintf("unsupported type %T", v))
+}
+
+func reflectInt(e reflect.Value) int64 {
+	if e.CanUint() {
+		return int64(e.Uint())
+	}
+	return e.Int()
+}
+
+// copyBinaryArray - one-dimensional array: dimensions, null flag, element type, size, lower bound and elements
+func copyBinaryArray(e reflect.Value) ([]byte, error) {
+	if e.Len() > math.MaxInt32 {
+		return nil, nerr.New("array is too large")
+	}
+
+	var (
+		elements []byte
+		oid      uint32
+		hasNull  uint32
+	)
+	for i := 0; i < e.Len(); i++ {
+		data, elemOid, null, err := copyBinaryPlain(e.Index(i).Interface())
+		if err != nil {
+			return nil, nerr.New(fmt.Sprintf("array element %d: %v", i, err))
+		}
+
+		if elemOid == 0 && !null {
+			return nil, nerr.New(fmt.Sprintf("array element %d: nested arrays are not supported", i))
+		}
+		if elemOid != 0 {
+			if oid != 0 && oid != elemOid {
+				return nil, nerr.New(fmt.Sprintf("array element %d: different element types", i))
+			}
+			oid = elemOid
+		}
+		if null {
+			hasNull = 1
+		}
+
+		elements = appendBinaryField(elements, data, null)
+	}
+
+	if oid == 0 {
+		// тип элементов неизвестен (пустой массив или только null), определяем по типу среза
+		_, elemOid, _, _ := copyBinaryPlain(reflect.Zero(e.Type().Elem()).Interface())
+		if elemOid == 0 {
+			elemOid = oidText
+		}
+		oid = elemOid
+	}
+
+	be := binary.BigEndian
+	if e.Len() == 0 {
+		buf := be.AppendUint32(nil, 0)
+		buf = be.AppendUint32(buf, 0)
+		return be.AppendUint32(buf, oid), nil
+	}
+
+	buf := be.AppendUint32(nil, 1)
+	buf = be.AppendUint32(buf, hasNull)
+	buf = be.AppendUint32(buf, oid)
+	buf = be.AppendUint32(buf, uint32(e.Len()))
+	buf = be.AppendUint32(buf, 1)
+
+	return append(buf, elements...), nil
+}
@@ -0,0 +1,58 @@
+package sqlb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestCopyEncoder_Binary(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewCopyEncoder(&buf, CopyBinary)
+
+	ts := time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)
+	if err := e.Encode(int32(1), "ab", nil, ts, []int16{1, 2}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(1); err == nil {
+		t.Fatal("expected error for values count")
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := hex.EncodeToString([]byte(copyBinarySignature)) + "0000000000000000" +
+		"0006" +
+		"00000004" + "00000001" +
+		"00000002" + "6162" +
+		"ffffffff" +
+		"00000008" + "00000000000f4240" +
+		"00000020" + "00000001" + "00000000" + "00000015" + "00000002" + "00000001" +
+		"00000002" + "0001" + "00000002" + "0002" +
+		"00000001" + "01" +
+		"ffff"
+	if got := hex.EncodeToString(buf.Bytes()); got != req {
+		t.Fatalf("%s, wants: %s", got, req)
+	}
+}
+
+func TestCopyEncoder_BinaryEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewCopyEncoder(&buf, CopyBinary)
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := copyBinarySignature + "\x00\x00\x00\x00\x00\x00\x00\x00\xff\xff"
+	if buf.String() != req {
+		t.Fatalf("%q, wants: %q", buf.String(), req)
+	}
+
+	if _, _, err := copyBinaryValue([][]int{{1}}, nil); err == nil {
+		t.Fatal("expected error for nested array")
+	}
+	if _, _, err := copyBinaryValue(48*time.Hour, nil); err == nil {
+		t.Fatal("expected error for duration")
+	}
+}
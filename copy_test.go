@@ -0,0 +1,59 @@
+package sqlb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCopyEncoder_Text(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewCopyEncoder(&buf, CopyText)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var nilPtr *int
+	n := 7
+	if err := e.Encode(1, "a\tb\\c\nd", nil, ts, []byte{1, 255}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(&n, "", nilPtr, V(0, NullZero()), []string{"x y", "z", ""}, Money{Amount: 12345, Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(1); err == nil {
+		t.Fatal("expected error for values count")
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := "1\ta\\tb\\\\c\\nd\t\\N\t2024-01-02 03:04:05.000000 +0000\t\\\\x01ff\ttrue\n" +
+		"7\t\\N\t\\N\t\\N\t{\"x y\",z,NULL}\t123.45\n"
+	if buf.String() != req {
+		t.Fatalf("%q, wants: %q", buf.String(), req)
+	}
+}
+
+func TestCopyEncoder_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewCopyEncoder(&buf, CopyCSV, TimeFormat("2006-01-02"))
+
+	if err := e.WriteHeader("id", "name", "day", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(1, `say "hi", bob`, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), V(map[string]int{"a": 1}, Json())); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(2, nil, nil, []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := "id,name,day,tags\n" +
+		"1,\"say \"\"hi\"\", bob\",2024-01-02,\"{\"\"a\"\":1}\"\n" +
+		"2,,,\"{1,2}\"\n"
+	if buf.String() != req {
+		t.Fatalf("%q, wants: %q", buf.String(), req)
+	}
+}
@@ -0,0 +1,128 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// cte - common table expression
+type cte struct {
+	name         string
+	query        any
+	materialized string
+}
+
+// With - add the common table expression: WITH name AS (query).
+// query is a template string, a compiled *Template, a builder (anything with the Template() (string, error) method) or a SubQuery.
+// name may contain the column list: "tree(id, parent_id)"
+func (s *SelectBuilder) With(name string, query any) *SelectBuilder {
+	return s.with(name, query, "")
+}
+
+// WithMaterialized - same as With, with the MATERIALIZED hint
+func (s *SelectBuilder) WithMaterialized(name string, query any) *SelectBuilder {
+	return s.with(name, query, "MATERIALIZED ")
+}
+
+// WithNotMaterialized - same as With, with the NOT MATERIALIZED hint
+func (s *SelectBuilder) WithNotMaterialized(name string, query any) *SelectBuilder {
+	return s.with(name, query, "NOT MATERIALIZED ")
+}
+
+// Recursive - WITH RECURSIVE
+func (s *SelectBuilder) Recursive() *SelectBuilder {
+	s.recursive = true
+	return s
+}
+
+func (s *SelectBuilder) with(name string, query any, materialized string) *SelectBuilder {
+	if err := checkExpressions("CTE name", []string{name}); err != nil {
+		s.setErr(err)
+		return s
+	}
+
+	key := cteName(name)
+	for _, c := range s.ctes {
+		if cteName(c.name) == key {
+			s.setErr(nerr.New(fmt.Sprintf("CTE %s is defined more than once", name)))
+			return s
+		}
+	}
+
+	s.ctes = append(s.ctes, cte{name: name, query: query, materialized: materialized})
+	return s
+}
+
+// withToSql - WITH clause with a trailing space
+func (s *SelectBuilder) withToSql(r *condRenderer) (string, error) {
+	if len(s.ctes) == 0 {
+		return "", nil
+	}
+
+	items := make([]string, len(s.ctes))
+	for i, c := range s.ctes {
+		query, err := r.subquery(c.query)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("CTE %s: %v", c.name, err))
+		}
+		items[i] = c.name + " AS " + c.materialized + "(" + query + ")"
+	}
+
+	if s.recursive {
+		return "WITH RECURSIVE " + strings.Join(items, ", ") + " ", nil
+	}
+
+	return "WITH " + strings.Join(items, ", ") + " ", nil
+}
+
+// cteName - name without the column list, unquoted names are case insensitive
+func cteName(name string) string {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+
+	return aliasKey(strings.TrimSpace(name))
+}
+
+// subquerySql - sql template of the subquery: string, *Template, builder, or calculated *SqlBinder / *Query
+func subquerySql(query any) (string, error) {
+	if err := checkEmbeddable(query); err != nil {
+		return "", err
+	}
+
+	var sql string
+	switch q := query.(type) {
+	case string:
+		sql = q
+	case *Template:
+		if q == nil {
+			return "", nerr.New("nil template")
+		}
+		sql = q.SqlTemplate()
+	case *SqlBinder:
+		var err error
+		if sql, err = q.Sql(); err != nil {
+			return "", err
+		}
+	case *Query:
+		var err error
+		if sql, err = q.Sql(); err != nil {
+			return "", err
+		}
+	case interface{ Template() (string, error) }:
+		var err error
+		if sql, err = q.Template(); err != nil {
+			return "", err
+		}
+	default:
+		return "", nerr.New(fmt.Sprintf("unsupported subquery type %T", query))
+	}
+
+	if len(strings.TrimSpace(sql)) == 0 {
+		return "", nerr.New("empty subquery")
+	}
+
+	return sql, nil
+}
@@ -0,0 +1,33 @@
+package sqlb
+
+import "testing"
+
+func TestSelectBuilder_With(t *testing.T) {
+	active := Select("id").From("users").Where("active")
+	tree := "SELECT id, parent_id FROM nodes WHERE id = :root UNION ALL SELECT n.id, n.parent_id FROM nodes n JOIN tree ON n.parent_id = tree.id"
+
+	sql, err := Select("*").From("tree").Join("active a", "a.id = tree.id").
+		Recursive().
+		With("tree(id, parent_id)", tree).
+		WithMaterialized("active", active).
+		WithNotMaterialized("totals", MustCompile("SELECT 1")).
+		Q().Set("root", 1).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := "WITH RECURSIVE tree(id, parent_id) AS (SELECT id, parent_id FROM nodes WHERE id = 1 UNION ALL " +
+		"SELECT n.id, n.parent_id FROM nodes n JOIN tree ON n.parent_id = tree.id), " +
+		"active AS MATERIALIZED (SELECT id FROM users WHERE active), totals AS NOT MATERIALIZED (SELECT 1) " +
+		"SELECT * FROM tree JOIN active a ON a.id = tree.id"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if Select().From("a").With("a", "SELECT 1").With("A(x)", "SELECT 2").Err() == nil {
+		t.Fatal("expected duplicate CTE error")
+	}
+	if _, err := Select().From("a").With("a", 1).Template(); err == nil {
+		t.Fatal("expected error for unsupported subquery")
+	}
+}
@@ -0,0 +1,79 @@
+package sqlb
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// BindTrace - record of a single bind call in debug mode
+type BindTrace struct {
+	// Имя переменной
+	Variable string
+	// Go тип значения
+	Type string
+	// Преобразованное в sql значение, [REDACTED] для конфиденциальных значений
+	Value string
+	// Опции преобразования
+	Options string
+	// Место вызова в формате file:line
+	Caller string
+	// Ошибка связывания
+	Err error
+}
+
+// WithDebug - debug mode: every bind call is recorded and can be retrieved via DebugTrace.
+// Sensitive values (Sensitive option or WithRedactPatterns) are recorded as [REDACTED]
+func WithDebug() BinderOption {
+	return func(o *binderOptions) {
+		o.debug = true
+	}
+}
+
+// DebugTrace - bind calls recorded in debug mode (see WithDebug), in call order
+func (b *SqlBinder) DebugTrace() []BindTrace {
+	return append([]BindTrace(nil), b.trace...)
+}
+
+// addTrace - record the bind call
+func (b *SqlBinder) addTrace(variable string, value any, opts []Option, val string, err error) {
+	if v, ok := value.(Value); ok {
+		value = v.value
+		opts = append(opts[:len(opts):len(opts)], v.opts...)
+	}
+
+	o := newOptions(b.valueOptions(opts))
+	if o.sensitive || b.isSensitive(variableName(variable)) {
+		val = redactedValue
+	}
+
+	b.trace = append(b.trace, BindTrace{
+		Variable: variable,
+		Type:     fmt.Sprintf("%T", value),
+		Value:    val,
+		Options:  o.String(),
+		Caller:   externalCaller(),
+		Err:      err,
+	})
+}
+
+// externalCaller - the first caller outside of the package sources (tests are considered external)
+func externalCaller() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	_, self, _, _ := runtime.Caller(0)
+	dir := filepath.Dir(self)
+
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != dir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package sqlb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSqlBinder_DebugTrace(t *testing.T) {
+	binder := NewBinder("SELECT :a, :b", WithDebug())
+	binder.MustBind("a", 1)
+	if err := binder.Bind("b", map[string]int{"x": 1}, Json()); err != nil {
+		t.Fatal(err)
+	}
+	_ = binder.Bind("b", 2)
+
+	trace := binder.DebugTrace()
+	if len(trace) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(trace))
+	}
+
+	if trace[0].Variable != "a" || trace[0].Type != "int" || trace[0].Value != "1" {
+		t.Errorf("unexpected record: %+v", trace[0])
+	}
+	if trace[1].Options != "json" || trace[1].Value != `E'{"x":1}'` {
+		t.Errorf("unexpected record: %+v", trace[1])
+	}
+	if trace[2].Err == nil {
+		t.Errorf("expected error in record: %+v", trace[2])
+	}
+	if !strings.Contains(trace[0].Caller, "debug_test.go") {
+		t.Errorf("unexpected caller: %s", trace[0].Caller)
+	}
+
+	if len(NewBinder("SELECT :a").MustBind("a", 1).DebugTrace()) != 0 {
+		t.Error("trace must be empty without debug mode")
+	}
+}
+
+func TestSqlBinder_DebugTrace_Redacted(t *testing.T) {
+	binder := NewBinder("SELECT :a, :password, :c", WithDebug(), WithRedactPatterns("password"))
+	binder.MustBind("a", "secret", Sensitive())
+	binder.MustBind("password", "qwerty")
+	binder.MustBind("c", 1)
+
+	trace := binder.DebugTrace()
+	if trace[0].Value != "[REDACTED]" || trace[1].Value != "[REDACTED]" {
+		t.Errorf("sensitive values must be redacted: %+v", trace[:2])
+	}
+	if trace[2].Value != "1" {
+		t.Errorf("unexpected record: %+v", trace[2])
+	}
+}
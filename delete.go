@@ -0,0 +1,184 @@
+package sqlb
+
+import (
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// DeleteBuilder - builder of DELETE queries. WHERE conditions are inserted as is and may contain :variables,
+// which are bound by the binder returned by Binder or Q.
+// To protect against accidental deletion of the whole table, the query without conditions is not rendered unless AllRows is called
+type DeleteBuilder struct {
+	table     string
+	where     []Cond
+	allRows   bool
+	returning []string
+	// Удалять физически строки таблиц с мягким удалением
+	includeDeleted bool
+	// Режим подстановки переменных вместо значений
+	placeholders bool
+	err          error
+}
+
+// Delete - create DELETE builder for the table
+func Delete(table string) *DeleteBuilder {
+	d := &DeleteBuilder{table: table}
+	d.setErr(checkExpressions("table", []string{table}))
+
+	return d
+}
+
+// setErr - save the first error
+func (d *DeleteBuilder) setErr(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// When - call fn with the builder, if cond is true, so optional parts can be added without breaking the chain
+func (d *DeleteBuilder) When(cond bool, fn func(b *DeleteBuilder)) *DeleteBuilder {
+	if cond && fn != nil {
+		fn(d)
+	}
+
+	return d
+}
+
+// Where - add the condition as is, it may contain :variables. Conditions are combined by AND
+func (d *DeleteBuilder) Where(cond string) *DeleteBuilder {
+	d.setErr(checkExpressions("condition", []string{cond}))
+	d.where = append(d.where, Expr(cond))
+	return d
+}
+
+// WhereCond - add the condition (see Cond). Conditions are combined by AND
+func (d *DeleteBuilder) WhereCond(c Cond) *DeleteBuilder {
+	if c == nil {
+		d.setErr(nerr.New("nil condition"))
+		return d
+	}
+
+	d.where = append(d.where, c)
+	return d
+}
+
+// AllRows - explicitly allow deletion of all rows of the table
+func (d *DeleteBuilder) AllRows() *DeleteBuilder {
+	d.allRows = true
+	return d
+}
+
+// Returning - RETURNING clause
+func (d *DeleteBuilder) Returning(exprs ...string) *DeleteBuilder {
+	d.setErr(checkExpressions("RETURNING expression", exprs))
+	d.returning = append(d.returning, exprs...)
+	return d
+}
+
+// IncludeDeleted - delete the rows physically, including soft deleted ones. See SetSoftDelete
+func (d *DeleteBuilder) IncludeDeleted() *DeleteBuilder {
+	d.includeDeleted = true
+	return d
+}
+
+// Placeholders - emit values as generated variables :sqlb_1, :sqlb_2, ... instead of inline literals.
+// The values are returned by TemplateParams and bound by Binder and Q
+func (d *DeleteBuilder) Placeholders() *DeleteBuilder {
+	d.placeholders = true
+	return d
+}
+
+func (d *DeleteBuilder) usesPlaceholders() bool {
+	return d.placeholders
+}
+
+// Err - the first error that occurred
+func (d *DeleteBuilder) Err() error {
+	return d.err
+}
+
+// Validate - check the query and, if the schema is registered by SetSchema, check it against the schema. See Schema.Validate
+func (d *DeleteBuilder) Validate() error {
+	if _, err := d.Template(); err != nil {
+		return err
+	}
+
+	return validateSchema(d)
+}
+
+// Template - sql template of the query
+func (d *DeleteBuilder) Template() (string, error) {
+	return d.template(newCondRenderer(d.placeholders))
+}
+
+// TemplateParams - sql template of the query and the values of the generated variables (see Placeholders)
+func (d *DeleteBuilder) TemplateParams() (string, map[string]any, error) {
+	r := newCondRenderer(d.placeholders)
+	sql, err := d.template(r)
+	return sql, r.params, err
+}
+
+func (d *DeleteBuilder) template(r *condRenderer) (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+
+	if len(d.where) == 0 && !d.allRows {
+		return "", nerr.New("DELETE without WHERE, call AllRows to delete all rows")
+	}
+
+	var sql strings.Builder
+	conds := d.where
+	if column := softDeleteColumn(d.table); len(column) > 0 && !d.includeDeleted {
+		// мягкое удаление
+		sql.WriteString("UPDATE " + d.table + " SET " + column + " = NOW()")
+		conds = append(conds[:len(conds):len(conds)], IsNull(column))
+	} else {
+		sql.WriteString("DELETE FROM ")
+		sql.WriteString(d.table)
+	}
+
+	if len(conds) > 0 {
+		where, err := conditionsToSql(conds, r)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" WHERE ")
+		sql.WriteString(where)
+	}
+
+	if len(d.returning) > 0 {
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(strings.Join(d.returning, ", "))
+	}
+
+	if err := r.embedded.check(sql.String()); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// Binder - binder for the query template
+func (d *DeleteBuilder) Binder(opts ...BinderOption) *SqlBinder {
+	q := d
+	if sql, err := d.Template(); err == nil && needTenantCond(sql, opts) {
+		c := *d
+		c.where = append(d.where[:len(d.where):len(d.where)], tenantCond(tableAlias(d.table)))
+		q = &c
+	}
+
+	sql, params, err := q.TemplateParams()
+	return paramsBinder(sql, params, err, opts)
+}
+
+// Q - chainable Query for the query template
+func (d *DeleteBuilder) Q(opts ...BinderOption) *Query {
+	return &Query{binder: d.Binder(opts...)}
+}
+
+// Sql - the query, if its conditions have no variables
+func (d *DeleteBuilder) Sql() (string, error) {
+	return d.Binder().Sql()
+}
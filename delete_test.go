@@ -0,0 +1,25 @@
+package sqlb
+
+import "testing"
+
+func TestDeleteBuilder(t *testing.T) {
+	sql, err := Delete("users").Where("id = :id").Returning("id").Q().Set("id", 5).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "DELETE FROM users WHERE id = 5 RETURNING id"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Delete("users").Sql(); err == nil {
+		t.Fatal("expected error without WHERE")
+	}
+
+	sql, err = Delete("users").AllRows().Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "DELETE FROM users"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
@@ -0,0 +1,216 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// ExplainSql - the calculated query prefixed with EXPLAIN (ANALYZE, FORMAT ...).
+// format can be empty (server default), TEXT, JSON, XML or YAML.
+// Only read queries (SELECT, VALUES, TABLE, WITH without data modification) are allowed, see ExplainSqlForce
+func (b *SqlBinder) ExplainSql(analyze bool, format string) (string, error) {
+	return b.explainSql(analyze, format, false)
+}
+
+// ExplainSqlForce - same as ExplainSql, but for any statement that can be explained (not SHOW).
+// Note that EXPLAIN ANALYZE actually executes the statement
+func (b *SqlBinder) ExplainSqlForce(analyze bool, format string) (string, error) {
+	return b.explainSql(analyze, format, true)
+}
+
+func (b *SqlBinder) explainSql(analyze bool, format string, force bool) (string, error) {
+	sql, err := b.Sql()
+	if err != nil {
+		return "", err
+	}
+
+	// SHOW только читает, но PostgreSQL не умеет его объяснять
+	if StatementType(sql) == "SHOW" {
+		return "", nerr.New("explain is not supported for SHOW")
+	}
+
+	if !force && !IsReadOnlyStatement(sql) {
+		return "", nerr.New(fmt.Sprintf("explain is allowed only for read queries, got %s", StatementType(sql)))
+	}
+
+	var opts []string
+	if analyze {
+		opts = append(opts, "ANALYZE")
+	}
+
+	switch f := strings.ToUpper(strings.TrimSpace(format)); f {
+	case "":
+	case "TEXT", "JSON", "XML", "YAML":
+		opts = append(opts, "FORMAT "+f)
+	default:
+		return "", nerr.New(fmt.Sprintf("unknown explain format: %s", format))
+	}
+
+	if len(opts) == 0 {
+		return "EXPLAIN " + sql, nil
+	}
+
+	return "EXPLAIN (" + strings.Join(opts, ", ") + ") " + sql, nil
+}
+
+// ExplainPlan - result of EXPLAIN (ANALYZE, FORMAT JSON), see ParseExplain
+type ExplainPlan struct {
+	// Корневой узел плана
+	Plan PlanNode `json:"Plan"`
+	// Время планирования, мс
+	PlanningTime float64 `json:"Planning Time"`
+	// Время выполнения, мс
+	ExecutionTime float64 `json:"Execution Time"`
+}
+
+// PlanNode - node of the query plan. Times are in milliseconds, Actual* fields are filled only by ANALYZE
+type PlanNode struct {
+	NodeType          string     `json:"Node Type"`
+	RelationName      string     `json:"Relation Name,omitempty"`
+	Alias             string     `json:"Alias,omitempty"`
+	IndexName         string     `json:"Index Name,omitempty"`
+	StartupCost       float64    `json:"Startup Cost"`
+	TotalCost         float64    `json:"Total Cost"`
+	PlanRows          float64    `json:"Plan Rows"`
+	PlanWidth         int        `json:"Plan Width"`
+	ActualStartupTime float64    `json:"Actual Startup Time"`
+	ActualTotalTime   float64    `json:"Actual Total Time"`
+	ActualRows        float64    `json:"Actual Rows"`
+	ActualLoops       float64    `json:"Actual Loops"`
+	Plans             []PlanNode `json:"Plans,omitempty"`
+}
+
+// ParseExplain - parse the result of EXPLAIN (FORMAT JSON)
+func ParseExplain(data []byte) (*ExplainPlan, error) {
+	var plans []ExplainPlan
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, nerr.New(fmt.Sprintf("invalid explain json: %v", err))
+	}
+
+	if len(plans) != 1 {
+		return nil, nerr.New(fmt.Sprintf("single plan expected, got %d", len(plans)))
+	}
+
+	return &plans[0], nil
+}
+
+// TotalTime - planning and execution time
+func (p *ExplainPlan) TotalTime() time.Duration {
+	return time.Duration((p.PlanningTime + p.ExecutionTime) * float64(time.Millisecond))
+}
+
+// Rows - number of rows returned by the query
+func (p *ExplainPlan) Rows() float64 {
+	return p.Plan.ActualRows
+}
+
+// NodeTypes - distinct node types of the plan in the depth-first order: Limit, Index Scan etc.
+func (p *ExplainPlan) NodeTypes() []string {
+	var res []string
+	seen := map[string]bool{}
+
+	p.Walk(func(n *PlanNode) {
+		if !seen[n.NodeType] {
+			seen[n.NodeType] = true
+			res = append(res, n.NodeType)
+		}
+	})
+
+	return res
+}
+
+// HasNode - the plan contains the node type, e.g. "Seq Scan"
+func (p *ExplainPlan) HasNode(nodeType string) bool {
+	found := false
+	p.Walk(func(n *PlanNode) {
+		found = found || n.NodeType == nodeType
+	})
+
+	return found
+}
+
+// Walk - call fn for each node of the plan in the depth-first order
+func (p *ExplainPlan) Walk(fn func(n *PlanNode)) {
+	walkPlan(&p.Plan, fn)
+}
+
+// walkPlan - call fn for the node and its children
+func walkPlan(n *PlanNode, fn func(n *PlanNode)) {
+	fn(n)
+	for i := range n.Plans {
+		walkPlan(&n.Plans[i], fn)
+	}
+}
+
+// StatementType - the first keyword of the statement in upper case (SELECT, INSERT, WITH, etc.),
+// skipping whitespace, comments and opening parentheses
+func StatementType(sql string) string {
+	words := sqlWords(sql, 1)
+	if len(words) == 0 {
+		return ""
+	}
+
+	return words[0]
+}
+
+// IsReadOnlyStatement - is the statement a read query: SELECT, VALUES, TABLE, SHOW
+// or WITH without INSERT, UPDATE, DELETE and MERGE. Keywords inside strings and comments are ignored
+func IsReadOnlyStatement(sql string) bool {
+	switch StatementType(sql) {
+	case "SELECT", "VALUES", "TABLE", "SHOW":
+		// SELECT ... INTO создает таблицу
+		for _, w := range sqlWords(sql, -1) {
+			if w == "INTO" {
+				return false
+			}
+		}
+		return true
+	case "WITH":
+		for _, w := range sqlWords(sql, -1) {
+			switch w {
+			case "INSERT", "UPDATE", "DELETE", "MERGE", "INTO":
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// sqlWords - first n words of the sql in upper case (all words if n < 0).
+// Strings, quoted identifiers and comments are skipped
+func sqlWords(sql string, n int) []string {
+	var words []string
+
+	for i := 0; i < len(sql) && (n < 0 || len(words) < n); {
+		if kind, end := scanSql(sql, i); kind != sqlCode {
+			i = end
+			continue
+		}
+
+		c := sql[i]
+		switch {
+		case isLetter(c):
+			j := i
+			for j < len(sql) && isAllnum(sql[j]) {
+				j++
+			}
+			words = append(words, strings.ToUpper(sql[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return words
+}
+
+// isLetter - is the symbol a latin letter or '_'
+func isLetter(ch byte) bool {
+	return ch-'a' < 26 || ch-'A' < 26 || ch == '_'
+}
@@ -0,0 +1,72 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSqlBinder_ExplainSql(t *testing.T) {
+	binder := NewBinder("/* report */ SELECT * FROM t WHERE name = :name").MustBind("name", "delete")
+	sql, err := binder.ExplainSql(true, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "EXPLAIN (ANALYZE, FORMAT JSON) /* report */ SELECT * FROM t WHERE name = E'delete'"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	for _, tmpl := range []string{
+		"DELETE FROM t",
+		"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d",
+		"SELECT * INTO t2 FROM t",
+	} {
+		if _, err := NewBinder(tmpl).ExplainSql(false, ""); err == nil {
+			t.Errorf("expected error for %s", tmpl)
+		}
+	}
+
+	sql, err = NewBinder("DELETE FROM t").ExplainSqlForce(false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "EXPLAIN DELETE FROM t"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if !IsReadOnlyStatement("SHOW search_path") {
+		t.Error("SHOW must be a read-only statement")
+	}
+	if _, err := NewBinder("SHOW search_path").ExplainSql(false, ""); err == nil {
+		t.Error("expected error for SHOW")
+	}
+	if _, err := NewBinder("SHOW search_path").ExplainSqlForce(false, ""); err == nil {
+		t.Error("expected error for forced SHOW")
+	}
+}
+
+func TestParseExplain(t *testing.T) {
+	data := []byte(`[{"Plan": {"Node Type": "Limit", "Total Cost": 1.5, "Plan Rows": 10, "Actual Total Time": 0.2, "Actual Rows": 3, "Actual Loops": 1,
+		"Plans": [{"Node Type": "Index Scan", "Relation Name": "t", "Index Name": "t_pkey", "Actual Rows": 3, "Actual Loops": 1}]},
+		"Planning Time": 0.5, "Triggers": [], "Execution Time": 1.5}]`)
+
+	plan, err := ParseExplain(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.TotalTime() != 2*time.Millisecond || plan.Rows() != 3 {
+		t.Fatalf("unexpected metrics: %v, %v", plan.TotalTime(), plan.Rows())
+	}
+	if types := plan.NodeTypes(); len(types) != 2 || types[0] != "Limit" || types[1] != "Index Scan" {
+		t.Fatalf("unexpected node types: %v", types)
+	}
+	if !plan.HasNode("Index Scan") || plan.HasNode("Seq Scan") {
+		t.Fatal("unexpected HasNode result")
+	}
+	if plan.Plan.Plans[0].IndexName != "t_pkey" {
+		t.Fatalf("unexpected index: %s", plan.Plan.Plans[0].IndexName)
+	}
+
+	if _, err := ParseExplain([]byte(`{}`)); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
@@ -0,0 +1,252 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Filter - filter condition requested by the API client, e.g. {"field":"age","op":"gte","value":18}
+type Filter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+}
+
+// Filter operators
+const (
+	FilterEq      = "eq"
+	FilterNeq     = "neq"
+	FilterLt      = "lt"
+	FilterLte     = "lte"
+	FilterGt      = "gt"
+	FilterGte     = "gte"
+	FilterLike    = "like"
+	FilterILike   = "ilike"
+	FilterIn      = "in"
+	FilterNotIn   = "nin"
+	FilterBetween = "between"
+	// FilterIsNull - the value is bool: true - IS NULL, false - IS NOT NULL
+	FilterIsNull = "isnull"
+)
+
+// FilterType - allowed type of the filter value
+type FilterType int
+
+const (
+	// FilterAny - string, number or bool
+	FilterAny FilterType = iota
+	FilterString
+	FilterInt
+	FilterFloat
+	FilterBool
+	// FilterTime - time.Time or string in RFC3339 format
+	FilterTime
+)
+
+// FilterField - field allowed for filtering
+type FilterField struct {
+	// Column - sql expression of the field
+	Column string
+	// Ops - allowed operators, if empty only FilterEq is allowed
+	Ops []string
+	// Type - allowed type of the value
+	Type FilterType
+}
+
+// FilterCond - condition from the filters combined by AND. Fields and operators absent from allowed cause an error,
+// values are checked and converted according to the field type
+func FilterCond(filters []Filter, allowed map[string]FilterField) (Cond, error) {
+	conds := make([]Cond, 0, len(filters))
+	for _, f := range filters {
+		field, ok := allowed[f.Field]
+		if !ok {
+			return nil, nerr.New(fmt.Sprintf("filtering by %q is not allowed", f.Field))
+		}
+
+		if !field.allowOp(f.Op) {
+			return nil, nerr.New(fmt.Sprintf("operator %q is not allowed for %q", f.Op, f.Field))
+		}
+
+		cond, err := field.cond(f.Op, f.Value)
+		if err != nil {
+			return nil, nerr.New(fmt.Sprintf("%s: %v", f.Field, err))
+		}
+		conds = append(conds, cond)
+	}
+
+	return And(conds...), nil
+}
+
+// allowOp - the operator is allowed for the field
+func (f FilterField) allowOp(op string) bool {
+	if len(f.Ops) == 0 {
+		return op == FilterEq
+	}
+
+	for _, o := range f.Ops {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cond - condition for the operator
+func (f FilterField) cond(op string, value any) (Cond, error) {
+	switch op {
+	case FilterIsNull:
+		isNull, ok := value.(bool)
+		if !ok {
+			return nil, nerr.New(fmt.Sprintf("bool expected for %s, got %T", op, value))
+		}
+		if isNull {
+			return IsNull(f.Column), nil
+		}
+		return IsNotNull(f.Column), nil
+
+	case FilterLike, FilterILike:
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, nerr.New(fmt.Sprintf("string expected for %s, got %T", op, value))
+		}
+		if op == FilterLike {
+			return Like(f.Column, pattern), nil
+		}
+		return ILike(f.Column, pattern), nil
+
+	case FilterIn, FilterNotIn, FilterBetween:
+		values, err := f.convertList(value)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case FilterIn:
+			return In(f.Column, values), nil
+		case FilterNotIn:
+			return NotIn(f.Column, values), nil
+		}
+		if len(values) != 2 {
+			return nil, nerr.New(fmt.Sprintf("2 values expected for %s, got %d", op, len(values)))
+		}
+		return Between(f.Column, values[0], values[1]), nil
+	}
+
+	v, err := f.convert(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case FilterEq:
+		return Eq(f.Column, v), nil
+	case FilterNeq:
+		return Neq(f.Column, v), nil
+	case FilterLt:
+		return Lt(f.Column, v), nil
+	case FilterLte:
+		return Lte(f.Column, v), nil
+	case FilterGt:
+		return Gt(f.Column, v), nil
+	case FilterGte:
+		return Gte(f.Column, v), nil
+	}
+
+	return nil, nerr.New(fmt.Sprintf("unknown operator %q", op))
+}
+
+// convertList - convert each element of the list
+func (f FilterField) convertList(value any) ([]any, error) {
+	e := reflect.ValueOf(value)
+	if value == nil || e.Kind() != reflect.Slice && e.Kind() != reflect.Array {
+		return nil, nerr.New(fmt.Sprintf("list expected, got %T", value))
+	}
+
+	res := make([]any, e.Len())
+	for i := range res {
+		var err error
+		if res[i], err = f.convert(e.Index(i).Interface()); err != nil {
+			return nil, nerr.New(fmt.Sprintf("element %d: %v", i, err))
+		}
+	}
+
+	return res, nil
+}
+
+// convert - check the type of the value and convert it
+func (f FilterField) convert(value any) (any, error) {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			value = i
+		} else if fl, err := n.Float64(); err == nil {
+			value = fl
+		}
+	}
+
+	switch f.Type {
+	case FilterString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+
+	case FilterInt:
+		switch v := reflect.ValueOf(value); v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.Uint() <= math.MaxInt64 {
+				return int64(v.Uint()), nil
+			}
+		case reflect.Float32, reflect.Float64:
+			// числа из json декодируются как float64
+			if fl := v.Float(); fl == math.Trunc(fl) && math.Abs(fl) < 1<<53 {
+				return int64(fl), nil
+			}
+		}
+
+	case FilterFloat:
+		switch v := reflect.ValueOf(value); v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(v.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(v.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			if fl := v.Float(); !math.IsNaN(fl) && !math.IsInf(fl, 0) {
+				return fl, nil
+			}
+		}
+
+	case FilterBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+
+	case FilterTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, nerr.New(fmt.Sprintf("invalid time: %s", v))
+			}
+			return t, nil
+		}
+
+	default:
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return value, nil
+		}
+	}
+
+	return nil, nerr.New(fmt.Sprintf("unexpected value type %T", value))
+}
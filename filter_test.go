@@ -0,0 +1,56 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFilterCond(t *testing.T) {
+	allowed := map[string]FilterField{
+		"age":     {Column: "u.age", Ops: []string{FilterGte, FilterLt, FilterBetween}, Type: FilterInt},
+		"name":    {Column: "u.name", Ops: []string{FilterEq, FilterILike}, Type: FilterString},
+		"status":  {Column: "u.status", Ops: []string{FilterIn}, Type: FilterString},
+		"created": {Column: "u.created_at", Ops: []string{FilterGte, FilterIsNull}, Type: FilterTime},
+		"id":      {Column: "u.id"},
+	}
+
+	var filters []Filter
+	err := json.Unmarshal([]byte(`[
+		{"field":"age","op":"gte","value":18},
+		{"field":"name","op":"ilike","value":"a%"},
+		{"field":"status","op":"in","value":["new","active"]},
+		{"field":"created","op":"isnull","value":false},
+		{"field":"id","op":"eq","value":"x"}
+	]`), &filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cond, err := FilterCond(filters, allowed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err := CondSql(cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "u.age >= 18 AND u.name ILIKE E'a%' AND u.status IN (E'new', E'active') AND u.created_at IS NOT NULL AND u.id = E'x'"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	bad := []Filter{
+		{Field: "password", Op: FilterEq, Value: "x"},
+		{Field: "id", Op: FilterGt, Value: 1},
+		{Field: "age", Op: FilterGte, Value: "18; DROP TABLE users"},
+		{Field: "age", Op: FilterGte, Value: 18.5},
+		{Field: "age", Op: FilterBetween, Value: []any{1.0}},
+		{Field: "created", Op: FilterGte, Value: "yesterday"},
+		{Field: "id", Op: FilterEq, Value: map[string]any{}},
+	}
+	for _, f := range bad {
+		if _, err := FilterCond([]Filter{f}, allowed); err == nil {
+			t.Errorf("expected error for %+v", f)
+		}
+	}
+}
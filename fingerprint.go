@@ -0,0 +1,180 @@
+package sqlb
+
+import "strings"
+
+// Fingerprint - shape of the calculated query for grouping in logs and error trackers: string constants
+// (including E'...', B'...', X'...' and $$...$$), numbers, also negative, and true, false, null are replaced with ?
+// (except IS [NOT] NULL, IS [NOT] TRUE, IS [NOT] FALSE, which are predicates, not values), lists of literals
+// are collapsed into a single ? (IN (1, 2, 3) becomes IN (?)), comments are removed and whitespace is normalized.
+// Identifiers, keywords and positional parameters $1 are kept as is. The query is scanned by the same rules
+// as the Parser uses for :var
+func Fingerprint(sql string) string {
+	f := fingerprinter{out: make([]byte, 0, len(sql))}
+
+	for i := 0; i < len(sql); {
+		switch kind, end := scanSql(sql, i); kind {
+		case sqlComment:
+			f.space = true
+			i = end
+			continue
+		case sqlString:
+			f.literal()
+			i = end
+			continue
+		case sqlQuotedIdent:
+			f.token(sql[i:end])
+			i = end
+			continue
+		}
+
+		c := sql[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			f.space = true
+			i++
+
+		case isNumberStart(sql, i):
+			i = skipNumber(sql, i)
+			f.literal()
+
+		case (c == '-' || c == '+') && isNumberStart(sql, i+1) && f.unary():
+			// знак числа входит в литерал: x = -1 и x = 1 имеют одинаковый отпечаток
+			i = skipNumber(sql, i+1)
+			f.literal()
+
+		case c == '$':
+			// позиционный параметр $1
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			f.token(sql[i:j])
+			i = j
+
+		case isAllnum(c):
+			j := i
+			for j < len(sql) && (isAllnum(sql[j]) || sql[j] == '$') {
+				j++
+			}
+			if f.keywordLiteral(sql[i:j]) {
+				f.literal()
+			} else {
+				f.token(sql[i:j])
+			}
+			i = j
+
+		default:
+			f.token(sql[i : i+1])
+			i++
+		}
+	}
+
+	return string(f.out)
+}
+
+// fingerprinter - builder of the Fingerprint result
+type fingerprinter struct {
+	out []byte
+	// Перед следующей лексемой был пробел
+	space bool
+	// Две последние лексемы и начало последней
+	last, prev string
+	lastPos    int
+}
+
+// token - add the token
+func (f *fingerprinter) token(t string) {
+	if f.space && len(f.out) > 0 && t != "," && t != ")" && t != "]" && f.last != "(" && f.last != "[" {
+		f.out = append(f.out, ' ')
+	}
+	f.space = false
+
+	f.prev, f.last = f.last, t
+	f.lastPos = len(f.out)
+	f.out = append(f.out, t...)
+}
+
+// literal - add the literal. A literal after "?," is collapsed
+func (f *fingerprinter) literal() {
+	if f.last == "," && f.prev == "?" {
+		f.out = f.out[:f.lastPos]
+		f.last, f.prev = "?", ""
+		f.space = false
+		return
+	}
+
+	f.token("?")
+}
+
+// keywordLiteral - the word is the literal true, false or null, which is not a part of IS [NOT] ...
+func (f *fingerprinter) keywordLiteral(word string) bool {
+	switch strings.ToUpper(word) {
+	case "TRUE", "FALSE", "NULL":
+	default:
+		return false
+	}
+
+	last := strings.ToUpper(f.last)
+	return last != "IS" && !(last == "NOT" && strings.ToUpper(f.prev) == "IS")
+}
+
+// unary - a sign at the current position is unary, i.e. the last token is not an operand
+func (f *fingerprinter) unary() bool {
+	switch f.last {
+	case "", "(", "[", ",", "=", "<", ">", "+", "-", "*", "/", "%", "^", "|", "&", "!":
+		return true
+	case "?", ")", "]":
+		return false
+	}
+
+	if f.last[0] == '"' || f.last[0] == '$' {
+		return false
+	}
+
+	return unaryKeywords[strings.ToUpper(f.last)]
+}
+
+// unaryKeywords - keywords after which a sign belongs to the number
+var unaryKeywords = map[string]bool{
+	"SELECT": true, "WHERE": true, "AND": true, "OR": true, "NOT": true, "WHEN": true, "THEN": true, "ELSE": true,
+	"BETWEEN": true, "IN": true, "IS": true, "LIKE": true, "ILIKE": true, "SET": true, "VALUES": true, "RETURN": true,
+	"RETURNING": true, "HAVING": true, "ON": true, "BY": true, "ANY": true, "ALL": true, "CASE": true, "DISTINCT": true,
+}
+
+// isNumberStart - a number starts at the position i: 12, .5
+func isNumberStart(sql string, i int) bool {
+	if i >= len(sql) {
+		return false
+	}
+
+	c := sql[i]
+	return c >= '0' && c <= '9' || c == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9'
+}
+
+// skipNumber - position after the number starting at i: 12, 1.5, .5, 1e-3
+func skipNumber(sql string, i int) int {
+	digits := func() {
+		for i < len(sql) && sql[i] >= '0' && sql[i] <= '9' {
+			i++
+		}
+	}
+
+	digits()
+	if i < len(sql) && sql[i] == '.' && !(i+1 < len(sql) && sql[i+1] == '.') {
+		i++
+		digits()
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+
+	return i
+}
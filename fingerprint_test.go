@@ -0,0 +1,55 @@
+package sqlb
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"SELECT * FROM users WHERE id = 12 AND name = E'bo\\'b' /*route='GET'*/",
+			"SELECT * FROM users WHERE id = ? AND name = ?",
+		},
+		{
+			"SELECT id\n  FROM t -- comment\n  WHERE id IN (1, 2, 3) AND x > -1.5e3",
+			"SELECT id FROM t WHERE id IN (?) AND x > ?",
+		},
+		{
+			`INSERT INTO "t1" (a, b) VALUES ('x', 2), ('it''s', $$raw$$)`,
+			`INSERT INTO "t1" (a, b) VALUES (?), (?)`,
+		},
+		{
+			"SELECT data::jsonb, t2.c FROM t2 WHERE id = $1 AND flags = B'101' AND arr = ARRAY[1, 2]",
+			"SELECT data::jsonb, t2.c FROM t2 WHERE id = $1 AND flags = ? AND arr = ARRAY[?]",
+		},
+		{
+			`SELECT a - 1 AS d, -2 AS n, 'c\' :x AS s, e'\\' AS e FROM t WHERE b = +3 AND c = "q-1" - 4`,
+			`SELECT a - ? AS d, ? AS n, ? :x AS s, ? AS e FROM t WHERE b = ? AND c = "q-1" - ?`,
+		},
+		{
+			"UPDATE t SET a = true, b = NULL WHERE c IS NULL AND d IS NOT TRUE AND e = False AND f IN (1, null)",
+			"UPDATE t SET a = ?, b = ? WHERE c IS NULL AND d IS NOT TRUE AND e = ? AND f IN (?)",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Fingerprint(tt.sql); got != tt.want {
+			t.Errorf("%s, wants: %s", got, tt.want)
+		}
+	}
+
+	if Fingerprint("SELECT * FROM t WHERE x = -1") != Fingerprint("SELECT * FROM t WHERE x = 1") {
+		t.Fatal("negative and positive literals must have the same fingerprint")
+	}
+
+	if Fingerprint("SELECT * FROM t WHERE x = true") != Fingerprint("SELECT * FROM t WHERE x = 1") {
+		t.Fatal("boolean and numeric literals must have the same fingerprint")
+	}
+
+	a, _ := NewBinder("SELECT * FROM t WHERE id IN :ids").MustBind("ids", []int{1, 2}).Sql()
+	b, _ := NewBinder("SELECT * FROM t WHERE id IN :ids").MustBind("ids", []int{3, 4, 5}).Sql()
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Fatalf("%s, wants: %s", Fingerprint(a), Fingerprint(b))
+	}
+}
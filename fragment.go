@@ -0,0 +1,187 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/n-r-w/nerr"
+)
+
+// constSql - sql text known at compile time. The type is unexported, so only untyped string constants
+// can be passed as values of this type from outside the package
+type constSql string
+
+// Fragment - vetted sql fragment, inserted into the query as is (without quoting).
+// A fragment can be created only from a string constant (Raw) or from the result of another binder (FragmentOf),
+// so an arbitrary string can't get into the query as raw sql by mistake
+type Fragment struct {
+	sql string
+	// Переменные зарегистрированного фрагмента
+	vars []string
+}
+
+// Raw - fragment from a string constant. Variables can't be passed here, only constants: Raw("NOW()")
+func Raw(sql constSql) Fragment {
+	return Fragment{sql: string(sql)}
+}
+
+// FragmentOf - fragment from the result of another binder, e.g. for subqueries
+func FragmentOf(b *SqlBinder) (Fragment, error) {
+	sql, err := b.Sql()
+	if err != nil {
+		return Fragment{}, err
+	}
+
+	return Fragment{sql: sql}, nil
+}
+
+// String - sql text of the fragment
+func (f Fragment) String() string {
+	return f.sql
+}
+
+// SqlValue - implements SqlValuer
+func (f Fragment) SqlValue() (string, error) {
+	return f.sql, nil
+}
+
+// BindSql - insert the fragment into the placeholder as is
+func (b *SqlBinder) BindSql(variable string, f Fragment) error {
+	return b.bindSql(variable, false, f, nil, f.SqlValue)
+}
+
+// fragments - registered fragments by name
+var fragments = struct {
+	mu    sync.RWMutex
+	items map[string]Fragment
+}{items: map[string]Fragment{}}
+
+// RegisterFragment - parse and register the named fragment, e.g. a common predicate:
+// RegisterFragment("active_account", "a.status = 'active' AND a.deleted_at IS NULL").
+// The fragment may contain :variables, they are bound in the query that uses it. Registering the same name twice is an error.
+// Registered fragments are used by FragmentCond in builders and by {{name}} in templates (see ExpandFragments)
+func RegisterFragment(name string, sql constSql) error {
+	if _, ok := identParts(name); !ok || strings.Contains(name, ".") {
+		return nerr.New(fmt.Sprintf("invalid fragment name: %q", name))
+	}
+
+	if len(strings.TrimSpace(string(sql))) == 0 {
+		return nerr.New(fmt.Sprintf("fragment %s: empty sql", name))
+	}
+
+	p := NewParser(string(sql))
+	if err := p.Parse(); err != nil {
+		return nerr.New(fmt.Sprintf("fragment %s: %v", name, err))
+	}
+
+	fragments.mu.Lock()
+	defer fragments.mu.Unlock()
+
+	if _, ok := fragments.items[name]; ok {
+		return nerr.New(fmt.Sprintf("fragment already registered: %s", name))
+	}
+	fragments.items[name] = Fragment{sql: string(sql), vars: p.ParcedVariables()}
+
+	return nil
+}
+
+// MustRegisterFragment - same as RegisterFragment, but panics on error
+func MustRegisterFragment(name string, sql constSql) {
+	if err := RegisterFragment(name, sql); err != nil {
+		panic(err)
+	}
+}
+
+// LookupFragment - registered fragment by name
+func LookupFragment(name string) (Fragment, error) {
+	fragments.mu.RLock()
+	f, ok := fragments.items[name]
+	fragments.mu.RUnlock()
+
+	if !ok {
+		return Fragment{}, nerr.New(fmt.Sprintf("fragment not registered: %s", name))
+	}
+
+	return f, nil
+}
+
+// Variables - variables of the registered fragment, which must be bound in the query. Empty for Raw and FragmentOf
+func (f Fragment) Variables() []string {
+	return f.vars
+}
+
+// renderCond - the fragment can be used as a condition
+func (f Fragment) renderCond(_ *condRenderer) (string, error) {
+	if len(strings.TrimSpace(f.sql)) == 0 {
+		return "", nerr.New("empty fragment")
+	}
+
+	return f.sql, nil
+}
+
+// fragmentCond - registered fragment as a condition
+type fragmentCond string
+
+// FragmentCond - condition from the registered fragment, it is looked up when the condition is rendered
+func FragmentCond(name string) Cond {
+	return fragmentCond(name)
+}
+
+func (c fragmentCond) renderCond(r *condRenderer) (string, error) {
+	f, err := LookupFragment(string(c))
+	if err != nil {
+		return "", err
+	}
+
+	return f.renderCond(r)
+}
+
+// ExpandFragments - replace {{name}} in the template with the registered fragments.
+// {{name}} inside comments, string constants and quoted identifiers is not replaced (see Parser.Parse).
+// Variables of the fragments become variables of the template. Unknown fragment names are an error
+func ExpandFragments(template string) (string, error) {
+	if !strings.Contains(template, "{{") {
+		return template, nil
+	}
+
+	var res strings.Builder
+	shift := 0
+	for i := 0; i < len(template); {
+		if kind, end := scanSql(template, i); kind != sqlCode {
+			i = end
+			continue
+		}
+
+		if !strings.HasPrefix(template[i:], "{{") {
+			i++
+			continue
+		}
+
+		end := strings.Index(template[i:], "}}")
+		if end < 0 {
+			break
+		}
+		end += i
+
+		f, err := LookupFragment(strings.TrimSpace(template[i+2 : end]))
+		if err != nil {
+			return "", err
+		}
+
+		res.WriteString(template[shift:i])
+		res.WriteString(f.sql)
+		i = end + 2
+		shift = i
+	}
+	res.WriteString(template[shift:])
+
+	return res.String(), nil
+}
+
+// WithFragments - expand the registered fragments {{name}} in the template before parsing. See ExpandFragments
+func WithFragments() BinderOption {
+	return func(o *binderOptions) {
+		o.fragments = true
+	}
+}
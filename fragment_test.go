@@ -0,0 +1,84 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_BindSql(t *testing.T) {
+	sub := NewBinder("SELECT id FROM users WHERE name = :name").MustBind("name", "it's")
+	f, err := FragmentOf(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binder := NewBinder("SELECT * FROM orders WHERE user_id IN (:sub) AND created < :now AND note = :note")
+	if err := binder.BindSql("sub", f); err != nil {
+		t.Fatal(err)
+	}
+	binder.MustBind("now", Raw("NOW()"))
+	// обычная строка никогда не попадает в запрос как sql
+	binder.MustBind("note", "NOW()")
+
+	req := `SELECT * FROM orders WHERE user_id IN (SELECT id FROM users WHERE name = E'it\'s') AND created < NOW() AND note = E'NOW()'`
+	if sql := binder.MustSql(); sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestRegisterFragment(t *testing.T) {
+	MustRegisterFragment("test_active_account", "a.status = 'active' AND a.region = :region")
+	defer func() {
+		fragments.mu.Lock()
+		delete(fragments.items, "test_active_account")
+		fragments.mu.Unlock()
+	}()
+
+	if err := RegisterFragment("test_active_account", "TRUE"); err == nil {
+		t.Fatal("expected error for duplicate name")
+	}
+
+	f, err := LookupFragment("test_active_account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars := f.Variables(); len(vars) != 1 || vars[0] != ":region" {
+		t.Fatalf("%v, wants: [:region]", vars)
+	}
+
+	sql, err := Select("a.id").From("accounts a").Where("a.id > 0").WhereCond(FragmentCond("test_active_account")).
+		Binder().MustBind("region", "eu").Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT a.id FROM accounts a WHERE (a.id > 0) AND (a.status = 'active' AND a.region = E'eu')"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = NewBinder("SELECT * FROM accounts a WHERE {{ test_active_account }}", WithFragments()).
+		MustBind("region", "us").Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM accounts a WHERE a.status = 'active' AND a.region = E'us'"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	// маркеры внутри строк, комментариев и идентификаторов не заменяются
+	template := `SELECT '{{test_active_account}}', E'\'{{x}}', $$ {{x}} $$, "{{x}}" /* {{x}} */ FROM a -- {{x}}
+		WHERE {{test_active_account}}`
+	sql, err = ExpandFragments(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = `SELECT '{{test_active_account}}', E'\'{{x}}', $$ {{x}} $$, "{{x}}" /* {{x}} */ FROM a -- {{x}}
+		WHERE a.status = 'active' AND a.region = :region`
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := NewBinder("SELECT {{unknown}}", WithFragments()).Sql(); err == nil {
+		t.Fatal("expected error for unknown fragment")
+	}
+	if _, err := CondSql(FragmentCond("unknown")); err == nil {
+		t.Fatal("expected error for unknown fragment")
+	}
+}
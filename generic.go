@@ -0,0 +1,126 @@
+package sqlb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// BindT - typed version of SqlBinder.Bind. Values of the built-in scalar types (integers, floats, string, bool,
+// time.Time) are rendered directly by the type switch, without reflection. Options, registered converters
+// and other types are processed by ToSql
+func BindT[T any](b *SqlBinder, variable string, value T, opts ...Option) error {
+	return b.bindSql(variable, false, value, opts, func() (string, error) {
+		opts := b.valueOptions(opts)
+		if len(opts) == 0 && !hasConverters() {
+			if sql, ok := scalarToSql(value); ok {
+				return sql, nil
+			}
+		}
+
+		return ToSql(value, opts...)
+	})
+}
+
+// BindTime - bind time.Time value. The value is formatted directly according to the TimeFormat and NullZero options,
+// other conversion options and registered converters are processed by ToSql
+func BindTime(b *SqlBinder, variable string, value time.Time, opts ...Option) error {
+	return b.bindSql(variable, false, value, opts, func() (string, error) {
+		o := newOptions(b.valueOptions(opts))
+		if o.json || o.jsonPath || o.xml != xmlNone || o.bits || hasConverters() {
+			return o.toSql(value)
+		}
+
+		if o.nullZero && value.IsZero() {
+			return "null", nil
+		}
+		if len(o.timeFormat) > 0 {
+			return prepareString(value.Format(o.timeFormat), `'`, true), nil
+		}
+
+		return `'` + value.Format(timeLayout) + `'`, nil
+	})
+}
+
+// BindJSON - bind the value serialized to json. Same as Bind with the Json option, but the value is marshalled
+// directly, without choosing the conversion at runtime
+func BindJSON[T any](b *SqlBinder, variable string, value T, opts ...Option) error {
+	return b.bindSql(variable, false, value, opts, func() (string, error) {
+		if newOptions(b.valueOptions(opts)).nullZero && isZero(value) {
+			return "null", nil
+		}
+
+		return jsonToSql(value)
+	})
+}
+
+// scalarToSql - conversion of the built-in scalar types. ok is false for other types
+func scalarToSql[T any](value T) (sql string, ok bool) {
+	switch v := any(value).(type) {
+	case int:
+		return strconv.Itoa(v), true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		if v {
+			return "true", true
+		}
+		return "false", true
+	case string:
+		if v = strings.TrimSpace(v); len(v) == 0 {
+			return "null", true
+		}
+		return prepareString(v, `'`, true), true
+	case time.Time:
+		return `'` + v.Format(timeLayout) + `'`, true
+	}
+
+	return "", false
+}
+
+// BindArray - bind the slice as sql ARRAY. Each element is converted via ToSql with the given options,
+// without reflection over the slice itself
+func BindArray[T any](b *SqlBinder, variable string, values []T, opts ...Option) error {
+	return b.bindSql(variable, false, values, opts, func() (string, error) {
+		if values == nil {
+			return "null", nil
+		}
+		if len(values) == 0 {
+			return `'{}'`, nil
+		}
+
+		o := newOptions(b.valueOptions(opts))
+		items := make([]string, len(values))
+		for i, v := range values {
+			var err error
+			if items[i], err = o.toSql(v); err != nil {
+				return "", nerr.New(fmt.Sprintf("array element %d: %v", i, err))
+			}
+		}
+
+		return "ARRAY[" + strings.Join(items, ",") + "]", nil
+	})
+}
@@ -0,0 +1,56 @@
+package sqlb
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBindGeneric(t *testing.T) {
+	b := NewBinder("SELECT :id, :d, :j, :a, :e")
+	if err := BindT(b, "id", int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindTime(b, "d", time.Date(2022, 5, 31, 0, 0, 0, 0, time.UTC), TimeFormat("2006-01-02")); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindJSON(b, "j", map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindArray(b, "a", []string{"x", "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindArray(b, "e", []int{}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := `SELECT 1, E'2022-05-31', E'{"a":1}', ARRAY[E'x',E'y'], '{}'`
+	if sql := b.MustSql(); sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestBindT_Scalars(t *testing.T) {
+	b := NewBinder("SELECT :f, :s, :b, :e, :d, :z")
+	BindT(b, "f", 1.5)
+	BindT(b, "s", "it's")
+	BindT(b, "b", true)
+	BindT(b, "e", " ")
+	BindT(b, "d", time.Date(2022, 5, 31, 16, 15, 42, 234, time.UTC))
+	BindTime(b, "z", time.Time{}, NullZero())
+
+	req := `SELECT 1.5, E'it\'s', true, null, '2022-05-31 16:15:42.000000 +0000', null`
+	if sql := b.MustSql(); sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	// зарегистрированный конвертер имеет приоритет
+	RegisterConverter(func(v int) (string, error) { return "int(" + strconv.Itoa(v) + ")", nil })
+	defer UnregisterConverter[int]()
+
+	b = NewBinder("SELECT :i")
+	BindT(b, "i", 7)
+	if sql, req := b.MustSql(), "SELECT int(7)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
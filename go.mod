@@ -1,6 +1,6 @@
 module github.com/n-r-w/sqlb
 
-go 1.18
+go 1.20
 
 require github.com/n-r-w/nerr v1.1.0
 
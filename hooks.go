@@ -0,0 +1,98 @@
+package sqlb
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// BeforeCalculateHook - called before the query is calculated. values must not be modified
+type BeforeCalculateHook func(template string, values map[string]string)
+
+// CalculateHook - called after the query is calculated, successfully or not. values must not be modified
+type CalculateHook func(template string, values map[string]string, sql string, err error)
+
+var hooksMutex sync.RWMutex
+var beforeHooks []*BeforeCalculateHook
+var afterHooks []*CalculateHook
+
+// OnBeforeCalculate - register a hook called before every calculation of SqlBinder.Sql and Template.Sql.
+// Returns a function that removes the hook
+func OnBeforeCalculate(hook BeforeCalculateHook) (remove func()) {
+	h := &hook
+
+	hooksMutex.Lock()
+	beforeHooks = append(beforeHooks, h)
+	hooksMutex.Unlock()
+
+	return func() {
+		hooksMutex.Lock()
+		defer hooksMutex.Unlock()
+		beforeHooks = removeHook(beforeHooks, h)
+	}
+}
+
+// OnCalculate - register a hook called after every calculation of SqlBinder.Sql and Template.Sql.
+// Returns a function that removes the hook
+func OnCalculate(hook CalculateHook) (remove func()) {
+	h := &hook
+
+	hooksMutex.Lock()
+	afterHooks = append(afterHooks, h)
+	hooksMutex.Unlock()
+
+	return func() {
+		hooksMutex.Lock()
+		defer hooksMutex.Unlock()
+		afterHooks = removeHook(afterHooks, h)
+	}
+}
+
+// WithCalculateHook - hook called after every calculation of this binder, in addition to the global hooks
+func WithCalculateHook(hook CalculateHook) BinderOption {
+	return func(o *binderOptions) {
+		o.hooks = append(o.hooks, hook)
+	}
+}
+
+// removeHook - copy of the list without the hook. The list is copied, so that the hooks being called are not affected
+func removeHook[T any](hooks []*T, h *T) []*T {
+	res := make([]*T, 0, len(hooks))
+	for _, v := range hooks {
+		if v != h {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// calculate - calculate the query calling the hooks. buf is the reusable buffer of the query, may be nil
+func calculate(p *Parser, values map[string]string, hooks []CalculateHook, buf *bytes.Buffer) (string, error) {
+	hooksMutex.RLock()
+	before, after := beforeHooks, afterHooks
+	hooksMutex.RUnlock()
+
+	for _, h := range before {
+		(*h)(p.sqlTemplate, values)
+	}
+
+	var sql string
+	var err error
+	if m := currentMetrics(); m != nil {
+		start := time.Now()
+		sql, err = p.calculateBuf(buf, values)
+		m.Calculate(time.Since(start), err)
+	} else {
+		sql, err = p.calculateBuf(buf, values)
+	}
+
+	for _, h := range after {
+		(*h)(p.sqlTemplate, values, sql, err)
+	}
+	for _, h := range hooks {
+		h(p.sqlTemplate, values, sql, err)
+	}
+
+	return sql, err
+}
@@ -0,0 +1,31 @@
+package sqlb
+
+import "testing"
+
+func TestHooks(t *testing.T) {
+	var before, after, local int
+	var lastSql string
+
+	removeBefore := OnBeforeCalculate(func(template string, values map[string]string) {
+		before++
+	})
+	removeAfter := OnCalculate(func(template string, values map[string]string, sql string, err error) {
+		after++
+		lastSql = sql
+	})
+
+	binder := NewBinder("SELECT :a", WithCalculateHook(func(template string, values map[string]string, sql string, err error) {
+		local++
+	}))
+	binder.MustBind("a", 1).MustSql()
+	binder.MustSql() // результат запомнен, хуки не вызываются
+	MustCompile("SELECT 2").Sql(nil)
+
+	removeBefore()
+	removeAfter()
+	MustCompile("SELECT 3").Sql(nil)
+
+	if before != 2 || after != 2 || local != 1 || lastSql != "SELECT 2" {
+		t.Fatalf("unexpected hook calls: before %d, after %d, local %d, sql %s", before, after, local, lastSql)
+	}
+}
@@ -0,0 +1,114 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/n-r-w/nerr"
+)
+
+// maxIdentLen - maximum identifier length in PostgreSql (NAMEDATALEN - 1)
+const maxIdentLen = 63
+
+// BindIdent - bind the identifier (table, column, etc.) in double quotes.
+// Embedded double quotes are doubled, control characters are not allowed
+func (b *SqlBinder) BindIdent(variable string, name string) error {
+	return b.bindSql(variable, false, name, nil, func() (string, error) {
+		return quoteIdent(name)
+	})
+}
+
+// QuoteIdent - identifier for use in sql. Like quote_ident in PostgreSql, the name is quoted only when necessary:
+// unquoted identifiers are folded to lower case, so names with upper case letters, special characters,
+// a leading digit or reserved words are quoted. Embedded double quotes are doubled
+func QuoteIdent(name string) (string, error) {
+	quoted, err := quoteIdent(name)
+	if err != nil {
+		return "", err
+	}
+
+	if isPlainIdent(name) && !reservedWords[name] {
+		return name, nil
+	}
+
+	return quoted, nil
+}
+
+// QuoteQualified - schema-qualified name: schema.table. An empty schema is omitted. See QuoteIdent
+func QuoteQualified(schema, table string) (string, error) {
+	t, err := QuoteIdent(table)
+	if err != nil {
+		return "", err
+	}
+
+	if len(schema) == 0 {
+		return t, nil
+	}
+
+	s, err := QuoteIdent(schema)
+	if err != nil {
+		return "", err
+	}
+
+	return s + "." + t, nil
+}
+
+// isPlainIdent - identifier, which doesn't change after folding to lower case and doesn't need quotes
+func isPlainIdent(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r == '_':
+		case (r >= '0' && r <= '9') || r == '$':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// reservedWords - reserved key words of PostgreSql, which can't be used as unquoted column or table names
+var reservedWords = func() map[string]bool {
+	words := `all analyse analyze and any array as asc asymmetric authorization binary both case cast check collate
+		collation column concurrently constraint create cross current_catalog current_date current_role current_schema
+		current_time current_timestamp current_user default deferrable desc distinct do else end except false fetch for
+		foreign freeze from full grant group having ilike in initially inner intersect into is isnull join lateral
+		leading left like limit localtime localtimestamp natural not notnull null offset on only or order outer overlaps
+		placing primary references returning right select session_user similar some symmetric system_user table
+		tablesample then to trailing true union unique user using variadic verbose when where window with`
+
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		m[w] = true
+	}
+
+	return m
+}()
+
+// quoteIdent - validate and quote the identifier
+func quoteIdent(name string) (string, error) {
+	if len(name) == 0 {
+		return "", nerr.New("empty identifier")
+	}
+
+	if len(name) > maxIdentLen {
+		return "", nerr.New(fmt.Sprintf("identifier is too long: %s", name))
+	}
+
+	if !utf8.ValidString(name) {
+		return "", nerr.New(fmt.Sprintf("invalid utf8 in identifier: %q", name))
+	}
+
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", nerr.New(fmt.Sprintf("control character in identifier: %q", name))
+		}
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
+}
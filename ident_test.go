@@ -0,0 +1,61 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_BindIdent(t *testing.T) {
+	binder := NewBinder("SELECT :col FROM :table")
+	if err := binder.BindIdent("col", `my"col`); err != nil {
+		t.Fatal(err)
+	}
+	if err := binder.BindIdent("table", "Users"); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), `SELECT "my""col" FROM "Users"`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	for _, bad := range []string{"", "a\x00b", "a\nb"} {
+		if err := NewBinder("SELECT :c").BindIdent("c", bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestQuoteIdent(t *testing.T) {
+	tests := map[string]string{
+		"users":    "users",
+		"user_id2": "user_id2",
+		"Users":    `"Users"`,
+		"user":     `"user"`,
+		"order":    `"order"`,
+		"1st":      `"1st"`,
+		"my col":   `"my col"`,
+		`a"b`:      `"a""b"`,
+		"имя":      `"имя"`,
+	}
+	for name, req := range tests {
+		sql, err := QuoteIdent(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != req {
+			t.Errorf("%s, wants: %s", sql, req)
+		}
+	}
+
+	if _, err := QuoteIdent(""); err == nil {
+		t.Error("expected error for empty identifier")
+	}
+
+	sql, err := QuoteQualified("Sales", "order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := `"Sales"."order"`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if sql, _ = QuoteQualified("", "users"); sql != "users" {
+		t.Fatalf("%s, wants: users", sql)
+	}
+}
@@ -0,0 +1,74 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// emptyInList - IN list, that doesn't match anything. IN () is a syntax error in PostgreSql
+const emptyInList = "(SELECT NULL WHERE FALSE)"
+
+// BindIn - bind a slice or an array as a list of values for the IN operator: WHERE id IN :ids.
+// Each element is converted via ToSql with the given options. An empty (or nil) slice is rendered as
+// (SELECT NULL WHERE FALSE), so the condition is false instead of a syntax error
+func (b *SqlBinder) BindIn(variable string, values any, opts ...Option) error {
+	return b.bindSql(variable, false, values, opts, func() (string, error) {
+		return inListToSql(values, b.valueOptions(opts))
+	})
+}
+
+// inListToSql - convert slice to (v1, v2, ...)
+func inListToSql(values any, opts []Option) (string, error) {
+	items, err := listItemsToSql(values, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if len(items) == 0 {
+		return emptyInList, nil
+	}
+
+	return "(" + strings.Join(items, ", ") + ")", nil
+}
+
+// ToSqlList - list of values in parentheses: (v1, v2, v3). Each value is converted via ToSql with the options,
+// use V for options of a single value. Useful for row comparisons (a, b) > (1, 2) and VALUES rows.
+// An empty list is an error, because () is not valid sql
+func ToSqlList(values []any, opts ...Option) (string, error) {
+	if len(values) == 0 {
+		return "", nerr.New("empty list of values")
+	}
+
+	var sql strings.Builder
+	if err := writeValuesRow(&sql, values, opts); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// listItemsToSql - convert each element of slice or array via ToSql
+func listItemsToSql(values any, opts []Option) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	e := reflect.ValueOf(values)
+	if e.Kind() != reflect.Slice && e.Kind() != reflect.Array {
+		return nil, nerr.New(fmt.Sprintf("slice expected, got %T", values))
+	}
+
+	o := newOptions(opts)
+	items := make([]string, e.Len())
+	for i := 0; i < e.Len(); i++ {
+		var err error
+		if items[i], err = o.toSql(e.Index(i).Interface()); err != nil {
+			return nil, nerr.New(fmt.Sprintf("element %d: %v", i, err))
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,48 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_BindIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		values any
+		result string
+	}{
+		{"ints", []int{1, 2, 3}, "SELECT * FROM t WHERE id IN (1, 2, 3)"},
+		{"strings", [2]string{"a", "b'c"}, `SELECT * FROM t WHERE id IN (E'a', E'b\'c')`},
+		{"empty", []int{}, "SELECT * FROM t WHERE id IN (SELECT NULL WHERE FALSE)"},
+		{"nil", nil, "SELECT * FROM t WHERE id IN (SELECT NULL WHERE FALSE)"},
+	}
+
+	for _, test := range tests {
+		binder := NewBinder("SELECT * FROM t WHERE id IN :ids")
+		if err := binder.BindIn("ids", test.values); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		sql, err := binder.Sql()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if sql != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, sql, test.result)
+		}
+	}
+
+	if err := NewBinder("SELECT :ids").BindIn("ids", 5); err == nil {
+		t.Error("expected error for non-slice value")
+	}
+}
+
+func TestToSqlList(t *testing.T) {
+	sql, err := ToSqlList([]any{1, "a", nil, V(0, NullZero())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "(1, E'a', null, null)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := ToSqlList(nil); err == nil {
+		t.Fatal("expected error for empty list")
+	}
+}
@@ -0,0 +1,406 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// InsertBuilder - builder of INSERT queries. Values are converted via ToSql, use V to pass conversion options.
+// The first error is saved and returned by Sql
+type InsertBuilder struct {
+	table     string
+	columns   []string
+	rows      [][]any
+	query     any
+	returning []string
+	audit     auditState
+	// Режим подстановки переменных вместо значений
+	placeholders bool
+	err          error
+}
+
+// Insert - create INSERT builder for the table
+func Insert(table string) *InsertBuilder {
+	i := &InsertBuilder{table: table}
+	i.setErr(checkExpressions("table", []string{table}))
+
+	return i
+}
+
+// setErr - save the first error
+func (i *InsertBuilder) setErr(err error) {
+	if i.err == nil {
+		i.err = err
+	}
+}
+
+// When - call fn with the builder, if cond is true, so optional parts can be added without breaking the chain
+func (i *InsertBuilder) When(cond bool, fn func(b *InsertBuilder)) *InsertBuilder {
+	if cond && fn != nil {
+		fn(i)
+	}
+
+	return i
+}
+
+// Columns - add columns
+func (i *InsertBuilder) Columns(columns ...string) *InsertBuilder {
+	i.setErr(checkExpressions("column", columns))
+	i.columns = append(i.columns, columns...)
+	return i
+}
+
+// Values - add a row. The number of values must match the number of columns
+func (i *InsertBuilder) Values(values ...any) *InsertBuilder {
+	i.rows = append(i.rows, values)
+	return i
+}
+
+// Select - INSERT INTO table (columns) SELECT ...: insert the rows of the query instead of values.
+// The query is SelectBuilder, Template or any other query supported by Sub, its :variables are bound by the binder
+// returned by Binder or Q. Columns may be omitted, then the query must return all columns of the table in order
+func (i *InsertBuilder) Select(query any) *InsertBuilder {
+	if query == nil {
+		i.setErr(nerr.New("nil query"))
+		return i
+	}
+
+	i.query = query
+	return i
+}
+
+// Returning - RETURNING clause
+func (i *InsertBuilder) Returning(exprs ...string) *InsertBuilder {
+	i.setErr(checkExpressions("RETURNING expression", exprs))
+	i.returning = append(i.returning, exprs...)
+	return i
+}
+
+// Audit - set the audit columns (see SetAudit): created_at and updated_at - NOW(), updated_by - the actor from the context
+// (see WithActor). For tables not registered by SetAudit the columns are created_at, updated_at and updated_by.
+// Explicitly inserted columns are not changed
+func (i *InsertBuilder) Audit(ctx context.Context) *InsertBuilder {
+	i.setErr(i.audit.set(ctx, i.table))
+	return i
+}
+
+// Placeholders - emit values as generated variables :sqlb_1, :sqlb_2, ... instead of inline literals.
+// The values are returned by TemplateParams and bound by Binder and Q
+func (i *InsertBuilder) Placeholders() *InsertBuilder {
+	i.placeholders = true
+	return i
+}
+
+func (i *InsertBuilder) usesPlaceholders() bool {
+	return i.placeholders
+}
+
+// Err - the first error that occurred
+func (i *InsertBuilder) Err() error {
+	return i.err
+}
+
+// Validate - check the query and, if the schema is registered by SetSchema, check it against the schema. See Schema.Validate
+func (i *InsertBuilder) Validate() error {
+	if _, err := i.Template(); err != nil {
+		return err
+	}
+
+	return validateSchema(i)
+}
+
+// Sql - INSERT query. For INSERT ... SELECT the query must have no unbound variables
+func (i *InsertBuilder) Sql() (string, error) {
+	if i.query != nil || i.placeholders {
+		return i.Binder().Sql()
+	}
+
+	return i.Template()
+}
+
+// Template - sql template of the query. Values are already converted (unless Placeholders is used),
+// only the query of INSERT ... SELECT may contain :variables
+func (i *InsertBuilder) Template() (string, error) {
+	return i.template(newCondRenderer(i.placeholders))
+}
+
+// TemplateParams - sql template of the query and the values of the generated variables (see Placeholders)
+func (i *InsertBuilder) TemplateParams() (string, map[string]any, error) {
+	r := newCondRenderer(i.placeholders)
+	sql, err := i.template(r)
+	return sql, r.params, err
+}
+
+func (i *InsertBuilder) template(r *condRenderer) (string, error) {
+	if i.err != nil {
+		return "", i.err
+	}
+
+	if i.query != nil {
+		return i.selectTemplate(r)
+	}
+
+	if len(i.columns) == 0 {
+		return "", nerr.New("no columns to insert")
+	}
+
+	if len(i.rows) == 0 {
+		return "", nerr.New("no values to insert")
+	}
+
+	auditCols, auditVals, err := i.audit.values(i.table, true, i.columns)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(i.table)
+	sql.WriteString(" (")
+	sql.WriteString(strings.Join(append(i.columns[:len(i.columns):len(i.columns)], auditCols...), ", "))
+	sql.WriteString(") VALUES ")
+
+	for n, row := range i.rows {
+		if len(row) != len(i.columns) {
+			return "", nerr.New(fmt.Sprintf("row %d: %d values for %d columns", n, len(row), len(i.columns)))
+		}
+
+		if n > 0 {
+			sql.WriteString(", ")
+		}
+		if err := writeRow(&sql, append(row[:len(row):len(row)], auditVals...), r); err != nil {
+			return "", nerr.New(fmt.Sprintf("row %d: %v", n, err))
+		}
+	}
+
+	if len(i.returning) > 0 {
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(strings.Join(i.returning, ", "))
+	}
+
+	return sql.String(), nil
+}
+
+// selectTemplate - INSERT ... SELECT template
+func (i *InsertBuilder) selectTemplate(r *condRenderer) (string, error) {
+	if len(i.rows) > 0 {
+		return "", nerr.New("both values and query to insert")
+	}
+
+	auditCols, _, err := i.audit.values(i.table, true, i.columns)
+	if err != nil {
+		return "", err
+	}
+	if len(auditCols) > 0 {
+		return "", nerr.New("audit columns are not supported for INSERT ... SELECT, select them explicitly")
+	}
+
+	query, err := r.subquery(i.query)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO ")
+	sql.WriteString(i.table)
+	if len(i.columns) > 0 {
+		sql.WriteString(" (")
+		sql.WriteString(strings.Join(i.columns, ", "))
+		sql.WriteString(")")
+	}
+	sql.WriteString(" ")
+	sql.WriteString(query)
+
+	if len(i.returning) > 0 {
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(strings.Join(i.returning, ", "))
+	}
+
+	if err := r.embedded.check(sql.String()); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// Binder - binder for the query template. With WithTenant the column tenant_id = :tenant_id is added to every row,
+// if the query doesn't reference :tenant_id itself
+func (i *InsertBuilder) Binder(opts ...BinderOption) *SqlBinder {
+	q := i
+	if sql, err := i.Template(); err == nil && needTenantCond(sql, opts) {
+		var err error
+		if q, err = i.withTenant(); err != nil {
+			return paramsBinder("", nil, err, opts)
+		}
+	}
+
+	sql, params, err := q.TemplateParams()
+	return paramsBinder(sql, params, err, opts)
+}
+
+// withTenant - copy of the builder with the tenant column in every row
+func (i *InsertBuilder) withTenant() (*InsertBuilder, error) {
+	if i.query != nil {
+		return nil, nerr.New("INSERT ... SELECT must select :" + TenantVariable + " explicitly")
+	}
+
+	for _, c := range i.columns {
+		if aliasKey(c) == TenantVariable {
+			return nil, nerr.New("column " + TenantVariable + " is bound by WithTenant, don't insert it explicitly")
+		}
+	}
+
+	c := *i
+	c.columns = append(i.columns[:len(i.columns):len(i.columns)], TenantVariable)
+	c.rows = make([][]any, len(i.rows))
+	for n, row := range i.rows {
+		c.rows[n] = append(row[:len(row):len(row)], Raw(":"+TenantVariable))
+	}
+
+	return &c, nil
+}
+
+// Q - chainable Query for the query template
+func (i *InsertBuilder) Q(opts ...BinderOption) *Query {
+	return &Query{binder: i.Binder(opts...)}
+}
+
+// writeValuesRow - row of values in parentheses, converted via ToSql
+func writeValuesRow(sql *strings.Builder, row []any, opts []Option) error {
+	return writeRow(sql, row, &condRenderer{opts: opts})
+}
+
+// writeRow - row of values in parentheses, rendered by the renderer
+func writeRow(sql *strings.Builder, row []any, r *condRenderer) error {
+	sql.WriteString("(")
+	for n, value := range row {
+		val, err := r.value(value)
+		if err != nil {
+			return nerr.New(fmt.Sprintf("value %d: %v", n, err))
+		}
+
+		if n > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(val)
+	}
+	sql.WriteString(")")
+
+	return nil
+}
+
+// DefaultRowsPerStatement - default maximum number of rows in one statement generated by InsertRows
+const DefaultRowsPerStatement = 1000
+
+// InsertOption - option of InsertRows: RowsPerStatement or value conversion Option
+type InsertOption interface {
+	applyInsert(o *rowsOptions)
+}
+
+// UpdateRowsOption - option of UpdateRows: RowsPerStatement, ColumnType or value conversion Option
+type UpdateRowsOption interface {
+	applyUpdate(o *rowsOptions)
+}
+
+// RowsOption - option of both InsertRows and UpdateRows
+type RowsOption interface {
+	InsertOption
+	UpdateRowsOption
+}
+
+// rowsOptions - settings of InsertRows and UpdateRows
+type rowsOptions struct {
+	// Максимальное количество строк в одном запросе
+	rowsPerStatement int
+	// Опции преобразования значений
+	values []Option
+	// Типы колонок для приведения значений VALUES (UpdateRows)
+	columnTypes map[string]string
+}
+
+// rowsOption - implementation of RowsOption
+type rowsOption func(o *rowsOptions)
+
+func (f rowsOption) applyInsert(o *rowsOptions) {
+	f(o)
+}
+
+func (f rowsOption) applyUpdate(o *rowsOptions) {
+	f(o)
+}
+
+// newInsertOptions - collect InsertRows options
+func newInsertOptions(opts []InsertOption) *rowsOptions {
+	o := &rowsOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyInsert(o)
+		}
+	}
+
+	return o
+}
+
+// chunk - maximum number of rows in one statement
+func (o *rowsOptions) chunk() int {
+	if o.rowsPerStatement <= 0 {
+		return DefaultRowsPerStatement
+	}
+
+	return o.rowsPerStatement
+}
+
+// RowsPerStatement - maximum number of rows in one statement generated by InsertRows and UpdateRows
+func RowsPerStatement(n int) RowsOption {
+	return rowsOption(func(o *rowsOptions) {
+		o.rowsPerStatement = n
+	})
+}
+
+// InsertRows - multi-row INSERT statements. Values are converted via ToSql with the value conversion options,
+// rows are split into statements of at most RowsPerStatement (DefaultRowsPerStatement) rows
+func InsertRows(table string, columns []string, rows [][]any, opts ...InsertOption) ([]string, error) {
+	if err := checkExpressions("table", []string{table}); err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return nil, nerr.New("no columns to insert")
+	}
+	if err := checkExpressions("column", columns); err != nil {
+		return nil, err
+	}
+
+	o := newInsertOptions(opts)
+	chunk := o.chunk()
+
+	prefix := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES "
+	res := make([]string, 0, (len(rows)+chunk-1)/chunk)
+	for start := 0; start < len(rows); start += chunk {
+		end := start + chunk
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var sql strings.Builder
+		sql.WriteString(prefix)
+		for n := start; n < end; n++ {
+			if len(rows[n]) != len(columns) {
+				return nil, nerr.New(fmt.Sprintf("row %d: %d values for %d columns", n, len(rows[n]), len(columns)))
+			}
+
+			if n > start {
+				sql.WriteString(", ")
+			}
+			if err := writeValuesRow(&sql, rows[n], o.values); err != nil {
+				return nil, nerr.New(fmt.Sprintf("row %d: %v", n, err))
+			}
+		}
+		res = append(res, sql.String())
+	}
+
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package sqlb
+
+import "testing"
+
+func TestInsertBuilder(t *testing.T) {
+	sql, err := Insert("users").Columns("id", "name").Values(1, "a'b").Values(2, nil).Returning("id").Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := `INSERT INTO users (id, name) VALUES (1, E'a\'b'), (2, null) RETURNING id`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Insert("users").Columns("id", "name").Values(1).Sql(); err == nil {
+		t.Fatal("expected error for values count")
+	}
+	if _, err := Insert("users").Columns("id").Sql(); err == nil {
+		t.Fatal("expected error without values")
+	}
+	if _, err := Insert("").Columns("id").Values(1).Sql(); err == nil {
+		t.Fatal("expected error for empty table")
+	}
+}
+
+func TestInsertRows(t *testing.T) {
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, nil}}
+
+	sqls, err := InsertRows("t", []string{"id", "name"}, rows, RowsPerStatement(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := []string{
+		"INSERT INTO t (id, name) VALUES (1, E'a'), (2, E'b')",
+		"INSERT INTO t (id, name) VALUES (3, null)",
+	}
+	if len(sqls) != len(req) {
+		t.Fatalf("%v, wants: %v", sqls, req)
+	}
+	for i := range req {
+		if sqls[i] != req[i] {
+			t.Fatalf("%s, wants: %s", sqls[i], req[i])
+		}
+	}
+
+	if sqls, err = InsertRows("t", []string{"id"}, [][]any{{0}, {nil}}, NullZero()); err != nil || len(sqls) != 1 {
+		t.Fatalf("%v, %v", sqls, err)
+	}
+	if req := "INSERT INTO t (id) VALUES (null), (null)"; sqls[0] != req {
+		t.Fatalf("%s, wants: %s", sqls[0], req)
+	}
+
+	if _, err := InsertRows("t", []string{"id"}, [][]any{{1, 2}}); err == nil {
+		t.Fatal("expected error for values count")
+	}
+}
+
+func TestInsertBuilder_Select(t *testing.T) {
+	q := Insert("orders_archive").Columns("id", "total").
+		Select(Select("id", "total").From("orders").Where("created_at < :before")).
+		Returning("id")
+
+	sql, err := q.Binder().MustBind("before", "2020-01-01").Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "INSERT INTO orders_archive (id, total) SELECT id, total FROM orders WHERE created_at < E'2020-01-01' RETURNING id"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	tmpl, err := Compile("SELECT * FROM orders WHERE id = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err = Insert("orders_archive").Select(tmpl).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "INSERT INTO orders_archive SELECT * FROM orders WHERE id = 1"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Insert("t").Columns("a").Values(1).Select("SELECT 1").Sql(); err == nil {
+		t.Fatal("expected error for values and query")
+	}
+}
@@ -0,0 +1,162 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Join - INNER JOIN table ON condition. table is a table name with an optional alias: "orders o".
+// Each alias (or table name without an alias) can be defined only once in the query
+func (s *SelectBuilder) Join(table string, on string) *SelectBuilder {
+	return s.join("JOIN", table, tableAlias(table), on)
+}
+
+// LeftJoin - LEFT JOIN table ON condition
+func (s *SelectBuilder) LeftJoin(table string, on string) *SelectBuilder {
+	return s.join("LEFT JOIN", table, tableAlias(table), on)
+}
+
+// RightJoin - RIGHT JOIN table ON condition
+func (s *SelectBuilder) RightJoin(table string, on string) *SelectBuilder {
+	return s.join("RIGHT JOIN", table, tableAlias(table), on)
+}
+
+// FullJoin - FULL JOIN table ON condition
+func (s *SelectBuilder) FullJoin(table string, on string) *SelectBuilder {
+	return s.join("FULL JOIN", table, tableAlias(table), on)
+}
+
+// CrossJoin - CROSS JOIN table
+func (s *SelectBuilder) CrossJoin(table string) *SelectBuilder {
+	return s.join("CROSS JOIN", table, tableAlias(table), "")
+}
+
+// LateralJoin - JOIN LATERAL (subquery) alias ON condition. An empty condition is rendered as ON TRUE
+func (s *SelectBuilder) LateralJoin(subquery string, alias string, on string) *SelectBuilder {
+	return s.lateralJoin("JOIN LATERAL", subquery, alias, on)
+}
+
+// LeftLateralJoin - LEFT JOIN LATERAL (subquery) alias ON condition. An empty condition is rendered as ON TRUE
+func (s *SelectBuilder) LeftLateralJoin(subquery string, alias string, on string) *SelectBuilder {
+	return s.lateralJoin("LEFT JOIN LATERAL", subquery, alias, on)
+}
+
+func (s *SelectBuilder) lateralJoin(kind string, subquery string, alias string, on string) *SelectBuilder {
+	if err := checkExpressions("subquery", []string{subquery, alias}); err != nil {
+		s.setErr(err)
+		return s
+	}
+
+	if len(strings.TrimSpace(on)) == 0 {
+		on = "TRUE"
+	}
+
+	return s.join(kind, "("+subquery+") "+alias, alias, on)
+}
+
+// join - add the join and register its alias
+func (s *SelectBuilder) join(kind string, table string, alias string, on string) *SelectBuilder {
+	if err := checkExpressions("JOIN table", []string{table}); err != nil {
+		s.setErr(err)
+		return s
+	}
+
+	if kind != "CROSS JOIN" {
+		if err := checkExpressions("JOIN condition", []string{on}); err != nil {
+			s.setErr(err)
+			return s
+		}
+	}
+
+	s.addAlias(alias)
+	s.joins = append(s.joins, selectJoin{kind: kind, table: table, alias: alias, on: on})
+	s.joinTables = append(s.joinTables, table)
+
+	return s
+}
+
+// selectJoin - JOIN of the SELECT query
+type selectJoin struct {
+	// JOIN, LEFT JOIN и т.п.
+	kind  string
+	table string
+	alias string
+	// Условие соединения, пустое для CROSS JOIN
+	on string
+}
+
+// joinSql - sql of the join. For tables with soft deletion (see SetSoftDelete) the condition "alias.column IS NULL"
+// is added to ON, so outer joins keep the rows without a match. For CROSS JOIN it is added to WHERE (see whereConds)
+func (s *SelectBuilder) joinSql(j selectJoin) string {
+	if j.kind == "CROSS JOIN" {
+		return j.kind + " " + j.table
+	}
+
+	if column := s.joinSoftDeleteColumn(j); len(column) > 0 {
+		return j.kind + " " + j.table + " ON (" + j.on + ") AND " + column + " IS NULL"
+	}
+
+	return j.kind + " " + j.table + " ON " + j.on
+}
+
+// joinSoftDeleteColumn - qualified column with the deletion time of the joined table or an empty string
+func (s *SelectBuilder) joinSoftDeleteColumn(j selectJoin) string {
+	if len(j.alias) == 0 {
+		return ""
+	}
+
+	column := s.softDeleteColumn(j.table)
+	if len(column) == 0 {
+		return ""
+	}
+
+	return j.alias + "." + column
+}
+
+// addAlias - register the table alias, the duplicate is an error
+func (s *SelectBuilder) addAlias(alias string) {
+	if len(alias) == 0 {
+		return
+	}
+
+	key := aliasKey(alias)
+	if s.aliases[key] {
+		s.setErr(nerr.New(fmt.Sprintf("table alias %s is defined more than once", alias)))
+		return
+	}
+
+	if s.aliases == nil {
+		s.aliases = map[string]bool{}
+	}
+	s.aliases[key] = true
+}
+
+// tableAlias - alias of the table expression: "users u", "users AS u" -> u, "public.users" -> users.
+// Returns an empty string for expressions that cannot be analyzed (subqueries, functions)
+func tableAlias(table string) string {
+	fields := strings.Fields(table)
+	switch {
+	case len(fields) == 1:
+		if strings.ContainsAny(fields[0], "()") {
+			return ""
+		}
+		return fields[0][strings.LastIndex(fields[0], ".")+1:]
+	case len(fields) == 2 && !strings.ContainsAny(fields[0], "()"):
+		return fields[1]
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS") && !strings.ContainsAny(fields[0], "()"):
+		return fields[2]
+	}
+
+	return ""
+}
+
+// aliasKey - unquoted identifiers are case insensitive in PostgreSql
+func aliasKey(alias string) string {
+	if strings.HasPrefix(alias, `"`) {
+		return alias
+	}
+
+	return strings.ToLower(alias)
+}
@@ -0,0 +1,33 @@
+package sqlb
+
+import "testing"
+
+func TestSelectBuilder_Join(t *testing.T) {
+	sql, err := Select("u.id", "o.total", "l.created_at").From("users u").
+		Join("orders o", "o.user_id = u.id").
+		LeftJoin("public.payments", "payments.order_id = o.id").
+		LeftLateralJoin("SELECT created_at FROM logins WHERE user_id = u.id ORDER BY created_at DESC LIMIT 1", "l", "").
+		CrossJoin("settings AS s").
+		Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := "SELECT u.id, o.total, l.created_at FROM users u JOIN orders o ON o.user_id = u.id " +
+		"LEFT JOIN public.payments ON payments.order_id = o.id " +
+		"LEFT JOIN LATERAL (SELECT created_at FROM logins WHERE user_id = u.id ORDER BY created_at DESC LIMIT 1) l ON TRUE " +
+		"CROSS JOIN settings AS s"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if Select().From("users u").Join("orders U", "U.user_id = u.id").Err() == nil {
+		t.Fatal("expected duplicate alias error")
+	}
+	if Select().From("users").Join("users", "TRUE").Err() == nil {
+		t.Fatal("expected duplicate table error")
+	}
+	if Select().From("users u").Join("orders o", "").Err() == nil {
+		t.Fatal("expected error without condition")
+	}
+}
@@ -0,0 +1,115 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// JsonText - extraction of the jsonb value as text by the path: column #>> '{a,b}'. Path elements are keys or array indexes.
+// The result is an expression, which can be used as a column in conditions: Eq(JsonText("data", "user", "name"), name)
+func JsonText(column string, path ...string) string {
+	return column + " #>> " + jsonPathArray(path)
+}
+
+// JsonValue - extraction of the jsonb value by the path: column #> '{a,b}'. See JsonText
+func JsonValue(column string, path ...string) string {
+	return column + " #> " + jsonPathArray(path)
+}
+
+// jsonPathArray - path as a text array literal
+func jsonPathArray(path []string) string {
+	items := make([]string, len(path))
+	for i, p := range path {
+		items[i] = arrayLiteralItem(p)
+	}
+
+	return prepareString("{"+strings.Join(items, ",")+"}", `'`, true)
+}
+
+// arrayLiteralItem - element of the array literal {a,"b c"}, quoted if necessary
+func arrayLiteralItem(s string) string {
+	if len(s) > 0 && !strings.EqualFold(s, "NULL") && !strings.ContainsAny(s, "{}\",\\ \t\n\r") {
+		return s
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// JsonPathOf - jsonpath of the keys: $."a"."b c". Keys are quoted, so they can contain any characters
+func JsonPathOf(keys ...string) string {
+	var path strings.Builder
+	path.WriteString("$")
+	for _, k := range keys {
+		// строка json является корректной строкой jsonpath
+		data, _ := json.Marshal(k)
+		path.WriteString(".")
+		path.Write(data)
+	}
+
+	return path.String()
+}
+
+// jsonCond - jsonb condition
+type jsonCond struct {
+	op     string
+	column string
+	path   string
+	value  any
+}
+
+// JsonContains - column @> value::jsonb. The value is serialized to json
+func JsonContains(column string, value any) Cond {
+	return &jsonCond{op: "@>", column: column, value: value}
+}
+
+// JsonPathMatch - the jsonpath predicate is true: column @@ '$.age > 18'. See JsonPathOf
+func JsonPathMatch(column string, path string) Cond {
+	return &jsonCond{op: "@@", column: column, path: path}
+}
+
+// JsonPathExists - jsonb_path_exists(column, path, vars). vars are serialized to json and available in the path as $name:
+// JsonPathExists("data", "$.items[*] ? (@.price > $min)", map[string]any{"min": 10}). nil vars are omitted
+func JsonPathExists(column string, path string, vars any) Cond {
+	return &jsonCond{op: "exists", column: column, path: path, value: vars}
+}
+
+func (c *jsonCond) renderCond(r *condRenderer) (string, error) {
+	if err := checkExpressions("column", []string{c.column}); err != nil {
+		return "", err
+	}
+
+	if c.op == "@>" {
+		if c.value == nil {
+			return "", nerr.New(fmt.Sprintf("%s: nil value", c.column))
+		}
+
+		val, err := r.value(V(c.value, Json()))
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+		}
+		return c.column + " @> " + val + "::jsonb", nil
+	}
+
+	if err := checkExpressions("jsonpath", []string{c.path}); err != nil {
+		return "", err
+	}
+	path := prepareString(c.path, `'`, true) + "::jsonpath"
+
+	if c.op == "@@" {
+		return c.column + " @@ " + path, nil
+	}
+
+	if c.value == nil {
+		return "jsonb_path_exists(" + c.column + ", " + path + ")", nil
+	}
+
+	vars, err := r.value(V(c.value, Json()))
+	if err != nil {
+		return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+	}
+
+	return "jsonb_path_exists(" + c.column + ", " + path + ", " + vars + "::jsonb)", nil
+}
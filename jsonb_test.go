@@ -0,0 +1,36 @@
+package sqlb
+
+import "testing"
+
+func TestJsonb(t *testing.T) {
+	if sql, req := JsonText("data", "user", "first name", `a"b`), `data #>> E'{user,"first name","a\\"b"}'`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if sql, req := JsonValue("data", "items", "0"), `data #> E'{items,0}'`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if sql, req := JsonPathOf("user", "first name"), `$."user"."first name"`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	c := And(
+		JsonContains("data", map[string]any{"tags": []string{"a"}}),
+		Eq(JsonText("data", "status"), "new"),
+		JsonPathMatch("data", JsonPathOf("age")+" > 18"),
+		JsonPathExists("data", "$.items[*] ? (@.price > $min)", map[string]int{"min": 10}),
+	)
+	sql, err := CondSql(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := `data @> E'{"tags":["a"]}'::jsonb AND data #>> E'{status}' = E'new' AND ` +
+		`data @@ E'$."age" > 18'::jsonpath AND ` +
+		`jsonb_path_exists(data, E'$.items[*] ? (@.price > $min)'::jsonpath, E'{"min":10}'::jsonb)`
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := CondSql(JsonContains("data", nil)); err == nil {
+		t.Fatal("expected error for nil value")
+	}
+}
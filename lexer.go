@@ -0,0 +1,112 @@
+package sqlb
+
+import "strings"
+
+// sqlToken - kind of the part of sql text found by scanSql
+type sqlToken int
+
+const (
+	// sqlCode - regular sql text
+	sqlCode sqlToken = iota
+	// sqlComment - comment: -- ... or /* ... */
+	sqlComment
+	// sqlString - string constant: '...', E'...', B'...', X'...', $$...$$, $tag$...$tag$
+	sqlString
+	// sqlQuotedIdent - quoted identifier: "..."
+	sqlQuotedIdent
+)
+
+// scanSql - kind and end of the comment, string constant or quoted identifier starting at the position i.
+// For regular sql text returns sqlCode and i. Unterminated constructions last till the end of sql.
+// Common lexer of Parser, ExpandFragments, Fingerprint and StatementType, so :var, {{name}} and literals
+// are recognized by the same rules
+func scanSql(sql string, i int) (sqlToken, int) {
+	c := sql[i]
+
+	switch {
+	case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+		if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+			return sqlComment, i + j + 1
+		}
+		return sqlComment, len(sql)
+
+	case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+		if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+			return sqlComment, i + j + 4
+		}
+		return sqlComment, len(sql)
+
+	case c == '\'':
+		// префикс E мог быть пропущен вызывающим, например как часть переменной :e'...'
+		escaped := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i == 1 || !isAllnum(sql[i-2]))
+		return sqlString, skipQuoted(sql, i, escaped)
+
+	case isStringPrefix(c) && i+1 < len(sql) && sql[i+1] == '\'' && (i == 0 || !isAllnum(sql[i-1])):
+		return sqlString, skipQuoted(sql, i+1, c == 'E' || c == 'e')
+
+	case c == '"':
+		return sqlQuotedIdent, skipQuoted(sql, i, false)
+
+	case c == '$':
+		if tag := dollarTag(sql, i); len(tag) > 0 {
+			if j := strings.Index(sql[i+len(tag):], tag); j >= 0 {
+				return sqlString, i + len(tag) + j + len(tag)
+			}
+			return sqlString, len(sql)
+		}
+	}
+
+	return sqlCode, i
+}
+
+// isStringPrefix - prefix of the string constant: E'...', B'...', X'...'
+func isStringPrefix(c byte) bool {
+	switch c {
+	case 'E', 'e', 'B', 'b', 'X', 'x':
+		return true
+	}
+
+	return false
+}
+
+// skipQuoted - position after the quoted string or identifier starting at i. A doubled quote is an escaped quote,
+// in the escaped mode (E'...') the backslash escapes the next symbol
+func skipQuoted(sql string, i int, escaped bool) int {
+	q := sql[i]
+	for i++; i < len(sql); i++ {
+		if escaped && sql[i] == '\\' {
+			i++
+			continue
+		}
+		if sql[i] == q {
+			if i+1 < len(sql) && sql[i+1] == q {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	return len(sql)
+}
+
+// dollarTag - opening tag $$ or $tag$ of the dollar quoted string at the position i, empty if there is none.
+// $1 is a positional parameter, not a tag
+func dollarTag(sql string, i int) string {
+	if i > 0 && (isAllnum(sql[i-1]) || sql[i-1] == '$') {
+		return ""
+	}
+
+	j := i + 1
+	if j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+		return ""
+	}
+	for j < len(sql) && isAllnum(sql[j]) {
+		j++
+	}
+	if j < len(sql) && sql[j] == '$' {
+		return sql[i : j+1]
+	}
+
+	return ""
+}
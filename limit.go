@@ -0,0 +1,78 @@
+package sqlb
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/n-r-w/nerr"
+)
+
+// DefaultMaxOffsetPages - default upper bound of the offset in pages of maxLimit (see SetMaxOffsetPages)
+const DefaultMaxOffsetPages = 1000
+
+// Максимальное смещение в страницах maxLimit, 0 - DefaultMaxOffsetPages
+var maxOffsetPages atomic.Int64
+
+// SetMaxOffsetPages - upper bound of the offset in BindLimitOffset: offset must not exceed maxLimit * pages.
+// Deep offsets force the database to scan and drop all preceding rows. pages <= 0 restores DefaultMaxOffsetPages
+func SetMaxOffsetPages(pages int) {
+	if pages < 0 {
+		pages = 0
+	}
+	maxOffsetPages.Store(int64(pages))
+}
+
+// BindLimitOffset - bind pagination values. limit is clamped to maxLimit, zero or negative limit,
+// negative offset and offset greater than maxLimit * max offset pages (see SetMaxOffsetPages) are errors
+func (b *SqlBinder) BindLimitOffset(limitVar, offsetVar string, limit, offset, maxLimit int) error {
+	limit, err := checkLimitOffset(limit, offset, maxLimit)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Bind(limitVar, limit); err != nil {
+		return err
+	}
+
+	return b.Bind(offsetVar, offset)
+}
+
+// checkLimitOffset - validate pagination values, returns clamped limit
+func checkLimitOffset(limit, offset, maxLimit int) (int, error) {
+	if maxLimit <= 0 {
+		return 0, nerr.New(fmt.Sprintf("invalid max limit: %d", maxLimit))
+	}
+
+	if limit <= 0 {
+		return 0, nerr.New(fmt.Sprintf("invalid limit: %d", limit))
+	}
+
+	if offset < 0 {
+		return 0, nerr.New(fmt.Sprintf("invalid offset: %d", offset))
+	}
+
+	if maxOffset := maxOffsetFor(maxLimit); offset > maxOffset {
+		return 0, nerr.New(fmt.Sprintf("offset %d exceeds max offset %d", offset, maxOffset))
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return limit, nil
+}
+
+// maxOffsetFor - upper bound of the offset for maxLimit
+func maxOffsetFor(maxLimit int) int {
+	pages := maxOffsetPages.Load()
+	if pages == 0 {
+		pages = DefaultMaxOffsetPages
+	}
+
+	if int64(maxLimit) > math.MaxInt/pages {
+		return math.MaxInt
+	}
+
+	return maxLimit * int(pages)
+}
@@ -0,0 +1,43 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_BindLimitOffset(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t LIMIT :limit OFFSET :offset")
+	if err := binder.BindLimitOffset("limit", "offset", 1000, 20, 100); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM t LIMIT 100 OFFSET 20"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	for _, v := range [][3]int{{0, 0, 100}, {10, -1, 100}, {10, 0, 0}} {
+		if err := NewBinder("SELECT :l, :o").BindLimitOffset("l", "o", v[0], v[1], v[2]); err == nil {
+			t.Errorf("expected error for %v", v)
+		}
+	}
+}
+
+func TestSqlBinder_BindLimitOffset_MaxOffset(t *testing.T) {
+	if err := NewBinder("SELECT :l, :o").BindLimitOffset("l", "o", 10, 100*DefaultMaxOffsetPages, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewBinder("SELECT :l, :o").BindLimitOffset("l", "o", 10, 100*DefaultMaxOffsetPages+1, 100); err == nil {
+		t.Fatal("expected error for offset over the default bound")
+	}
+
+	SetMaxOffsetPages(2)
+	defer SetMaxOffsetPages(0)
+
+	binder := NewBinder("SELECT * FROM t LIMIT :limit OFFSET :offset")
+	if err := binder.BindLimitOffset("limit", "offset", 10, 200, 100); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM t LIMIT 10 OFFSET 200"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if err := NewBinder("SELECT :l, :o").BindLimitOffset("l", "o", 10, 201, 100); err == nil {
+		t.Fatal("expected error for offset over the bound")
+	}
+}
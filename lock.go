@@ -0,0 +1,89 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// LockStrength - strength of the row-level lock
+type LockStrength int
+
+const (
+	// ForUpdate - FOR UPDATE
+	ForUpdate LockStrength = iota
+	// ForNoKeyUpdate - FOR NO KEY UPDATE
+	ForNoKeyUpdate
+	// ForShare - FOR SHARE
+	ForShare
+	// ForKeyShare - FOR KEY SHARE
+	ForKeyShare
+)
+
+// LockWait - behavior when the row is already locked
+type LockWait int
+
+const (
+	// Wait - wait for the lock
+	Wait LockWait = iota
+	// NoWait - NOWAIT: report an error
+	NoWait
+	// SkipLocked - SKIP LOCKED: skip the locked rows, e.g. for job queues
+	SkipLocked
+)
+
+// LockClause - locking clause: FOR UPDATE [OF table, ...] [NOWAIT | SKIP LOCKED].
+// of are table names or aliases, an empty list locks the rows of all tables
+func LockClause(strength LockStrength, wait LockWait, of ...string) (Fragment, error) {
+	var sql strings.Builder
+	switch strength {
+	case ForUpdate:
+		sql.WriteString("FOR UPDATE")
+	case ForNoKeyUpdate:
+		sql.WriteString("FOR NO KEY UPDATE")
+	case ForShare:
+		sql.WriteString("FOR SHARE")
+	case ForKeyShare:
+		sql.WriteString("FOR KEY SHARE")
+	default:
+		return Fragment{}, nerr.New(fmt.Sprintf("unknown lock strength: %d", strength))
+	}
+
+	for i, table := range of {
+		if _, ok := identParts(table); !ok {
+			return Fragment{}, nerr.New(fmt.Sprintf("invalid table name in locking clause: %s", table))
+		}
+
+		if i == 0 {
+			sql.WriteString(" OF ")
+		} else {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(table)
+	}
+
+	switch wait {
+	case Wait:
+	case NoWait:
+		sql.WriteString(" NOWAIT")
+	case SkipLocked:
+		sql.WriteString(" SKIP LOCKED")
+	default:
+		return Fragment{}, nerr.New(fmt.Sprintf("unknown lock wait mode: %d", wait))
+	}
+
+	return Fragment{sql: sql.String()}, nil
+}
+
+// For - add the locking clause. See LockClause. Locking is not allowed with DISTINCT, GROUP BY and HAVING
+func (s *SelectBuilder) For(strength LockStrength, wait LockWait, of ...string) *SelectBuilder {
+	lock, err := LockClause(strength, wait, of...)
+	if err != nil {
+		s.setErr(err)
+		return s
+	}
+
+	s.locks = append(s.locks, lock.sql)
+	return s
+}
@@ -0,0 +1,38 @@
+package sqlb
+
+import "testing"
+
+func TestSelectBuilder_For(t *testing.T) {
+	sql, err := Select("id").From("jobs j").Join("queues q", "q.id = j.queue_id").Where("j.state = 'new'").
+		OrderBy("j.id").Limit(10).For(ForUpdate, SkipLocked, "j").For(ForShare, Wait, "q").Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT id FROM jobs j JOIN queues q ON q.id = j.queue_id WHERE j.state = 'new' ORDER BY j.id LIMIT 10 " +
+		"FOR UPDATE OF j SKIP LOCKED FOR SHARE OF q"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Select("id").From("t").Distinct().For(ForUpdate, NoWait).Template(); err == nil {
+		t.Fatal("expected error for DISTINCT")
+	}
+	if _, err := Select("id").From("t").For(ForUpdate, Wait, "t; DROP").Template(); err == nil {
+		t.Fatal("expected error for invalid table")
+	}
+}
+
+func TestLockClause(t *testing.T) {
+	lock, err := LockClause(ForNoKeyUpdate, NoWait)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binder := NewBinder("SELECT * FROM t :lock")
+	if err := binder.BindSql("lock", lock); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM t FOR NO KEY UPDATE NOWAIT"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
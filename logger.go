@@ -0,0 +1,62 @@
+package sqlb
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// QueryLog - record of the query log
+type QueryLog struct {
+	// Операция: SpanCalculate или SpanExecute
+	Operation string
+	// Ключ шаблона: ключ кэширования или хэш содержимого шаблона
+	Key string
+	// Тип оператора: SELECT, INSERT, UPDATE и т.д.
+	Statement string
+	// Запрос без конфиденциальных значений (SqlRedacted) или с параметрами $1, $2...
+	Sql string
+	// Длительность операции
+	Duration time.Duration
+	// Количество строк, -1 если неизвестно
+	Rows int64
+	// Ошибка операции
+	Err error
+}
+
+// Logger - receiver of the log of calculated and executed queries, e.g. an adapter to slog or zap.
+// Must be safe for concurrent use
+type Logger interface {
+	// LogQuery - the query is calculated or executed
+	LogQuery(ctx context.Context, entry QueryLog)
+}
+
+// LoggerFunc - function as Logger
+type LoggerFunc func(ctx context.Context, entry QueryLog)
+
+// LogQuery - implementation of Logger
+func (f LoggerFunc) LogQuery(ctx context.Context, entry QueryLog) {
+	f(ctx, entry)
+}
+
+var logger atomic.Pointer[Logger]
+
+// SetLogger - set the query logger for the whole package. Sensitive values are replaced with [REDACTED], see SqlRedacted.
+// Calculations by Sql are logged with context.Background, use SqlContext to pass the context. nil disables logging
+func SetLogger(l Logger) {
+	if l == nil {
+		logger.Store(nil)
+		return
+	}
+
+	logger.Store(&l)
+}
+
+// currentLogger - current logger or nil
+func currentLogger() Logger {
+	if l := logger.Load(); l != nil {
+		return *l
+	}
+
+	return nil
+}
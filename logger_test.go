@@ -0,0 +1,39 @@
+package sqlb
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	var logs []QueryLog
+	SetLogger(LoggerFunc(func(_ context.Context, entry QueryLog) {
+		logs = append(logs, entry)
+	}))
+	defer SetLogger(nil)
+
+	b := NewBinder("SELECT * FROM users WHERE login = :login AND password = :password", WithCacheKey("users.login"))
+	b.MustBind("login", "bob").MustBind("password", "secret", Sensitive())
+	if _, err := b.Sql(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Sql(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, end := StartSpan(context.Background(), SpanInfo{Operation: SpanExecute, Template: "DELETE FROM t", Sql: "DELETE FROM t"})
+	end(-1, errors.New("failed"))
+
+	if len(logs) != 2 {
+		t.Fatalf("unexpected number of logs: %d", len(logs))
+	}
+
+	req := "SELECT * FROM users WHERE login = E'bob' AND password = [REDACTED]"
+	if l := logs[0]; l.Operation != SpanCalculate || l.Key != "users.login" || l.Statement != "SELECT" || l.Sql != req || l.Err != nil {
+		t.Fatalf("unexpected log: %+v", l)
+	}
+	if l := logs[1]; l.Operation != SpanExecute || l.Statement != "DELETE" || l.Err == nil || l.Rows != -1 {
+		t.Fatalf("unexpected log: %+v", l)
+	}
+}
@@ -0,0 +1,305 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// MergeBuilder - builder of MERGE queries (PostgreSql 15+). Values of the actions are converted via ToSql,
+// use Raw to refer to the source columns: MergeUpdate().Set("name", Raw("s.name")).
+// Conditions are inserted as is and may contain :variables, which are bound by the binder returned by Binder or Q.
+// The first error is saved and returned by Template
+type MergeBuilder struct {
+	target      string
+	source      string
+	sourceQuery any
+	on          string
+	whens       []mergeWhen
+	// Режим подстановки переменных вместо значений
+	placeholders bool
+	err          error
+}
+
+// mergeWhen - WHEN [NOT] MATCHED [AND condition] THEN action
+type mergeWhen struct {
+	matched bool
+	cond    string
+	action  *MergeAction
+}
+
+// mergeActionKind - kind of the MERGE action
+type mergeActionKind int
+
+const (
+	mergeDoNothing mergeActionKind = iota
+	mergeUpdate
+	mergeDelete
+	mergeInsert
+)
+
+// MergeAction - action of the WHEN branch of MERGE
+type MergeAction struct {
+	kind    mergeActionKind
+	set     []updateValue
+	columns []string
+	values  []any
+	err     error
+}
+
+// MergeUpdate - UPDATE SET action, only for WhenMatched
+func MergeUpdate() *MergeAction {
+	return &MergeAction{kind: mergeUpdate}
+}
+
+// MergeDelete - DELETE action, only for WhenMatched
+func MergeDelete() *MergeAction {
+	return &MergeAction{kind: mergeDelete}
+}
+
+// MergeInsert - INSERT (columns) VALUES action, only for WhenNotMatched. Set the values by Values
+func MergeInsert(columns ...string) *MergeAction {
+	return &MergeAction{kind: mergeInsert, columns: columns, err: checkExpressions("column", columns)}
+}
+
+// MergeDoNothing - DO NOTHING action
+func MergeDoNothing() *MergeAction {
+	return &MergeAction{kind: mergeDoNothing}
+}
+
+// Set - set the column value of the UPDATE action
+func (a *MergeAction) Set(column string, value any, opts ...Option) *MergeAction {
+	if a.err == nil {
+		a.err = checkExpressions("column", []string{column})
+	}
+	a.set = append(a.set, updateValue{column: column, value: value, opts: opts})
+	return a
+}
+
+// Values - values of the INSERT action. The number of values must match the number of columns
+func (a *MergeAction) Values(values ...any) *MergeAction {
+	a.values = values
+	return a
+}
+
+// sql - text of the action, values are rendered by the renderer
+func (a *MergeAction) sql(matched bool, r *condRenderer) (string, error) {
+	if a.err != nil {
+		return "", a.err
+	}
+
+	switch a.kind {
+	case mergeDoNothing:
+		return "DO NOTHING", nil
+
+	case mergeUpdate, mergeDelete:
+		if !matched {
+			return "", nerr.New("UPDATE and DELETE actions are allowed only in WHEN MATCHED")
+		}
+		if a.kind == mergeDelete {
+			return "DELETE", nil
+		}
+		if len(a.set) == 0 {
+			return "", nerr.New("no columns to update")
+		}
+
+		items := make([]string, len(a.set))
+		for i, s := range a.set {
+			val, err := r.value(withOptions(s.value, s.opts))
+			if err != nil {
+				return "", nerr.New(fmt.Sprintf("column %s: %v", s.column, err))
+			}
+			items[i] = s.column + " = " + val
+		}
+		return "UPDATE SET " + strings.Join(items, ", "), nil
+
+	case mergeInsert:
+		if matched {
+			return "", nerr.New("INSERT action is allowed only in WHEN NOT MATCHED")
+		}
+		if len(a.values) != len(a.columns) {
+			return "", nerr.New(fmt.Sprintf("%d values for %d columns", len(a.values), len(a.columns)))
+		}
+		if len(a.columns) == 0 {
+			return "INSERT DEFAULT VALUES", nil
+		}
+
+		var sql strings.Builder
+		sql.WriteString("INSERT (" + strings.Join(a.columns, ", ") + ") VALUES ")
+		if err := writeRow(&sql, a.values, r); err != nil {
+			return "", err
+		}
+		return sql.String(), nil
+	}
+
+	return "", nerr.New(fmt.Sprintf("unknown action: %d", a.kind))
+}
+
+// Merge - create MERGE builder for the target table with an optional alias: "users u"
+func Merge(target string) *MergeBuilder {
+	m := &MergeBuilder{target: target}
+	m.setErr(checkExpressions("table", []string{target}))
+
+	return m
+}
+
+// setErr - save the first error
+func (m *MergeBuilder) setErr(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// When - call fn with the builder, if cond is true, so optional parts can be added without breaking the chain
+func (m *MergeBuilder) When(cond bool, fn func(b *MergeBuilder)) *MergeBuilder {
+	if cond && fn != nil {
+		fn(m)
+	}
+
+	return m
+}
+
+// Using - USING source ON condition. source is a table name with an optional alias: "new_users s"
+func (m *MergeBuilder) Using(source string, on string) *MergeBuilder {
+	m.setErr(checkExpressions("source", []string{source}))
+	m.setErr(checkExpressions("join condition", []string{on}))
+	m.source = source
+	m.sourceQuery = nil
+	m.on = on
+	return m
+}
+
+// UsingQuery - USING (subquery) alias ON condition. See Sub for the supported subqueries
+func (m *MergeBuilder) UsingQuery(query any, alias string, on string) *MergeBuilder {
+	m.Using(alias, on)
+	m.sourceQuery = query
+	return m
+}
+
+// WhenMatched - WHEN MATCHED [AND condition] THEN action. The action is MergeUpdate, MergeDelete or MergeDoNothing.
+// Branches are checked in the order they are added
+func (m *MergeBuilder) WhenMatched(cond string, action *MergeAction) *MergeBuilder {
+	return m.when(true, cond, action)
+}
+
+// WhenNotMatched - WHEN NOT MATCHED [AND condition] THEN action. The action is MergeInsert or MergeDoNothing
+func (m *MergeBuilder) WhenNotMatched(cond string, action *MergeAction) *MergeBuilder {
+	return m.when(false, cond, action)
+}
+
+func (m *MergeBuilder) when(matched bool, cond string, action *MergeAction) *MergeBuilder {
+	if action == nil {
+		m.setErr(nerr.New("nil action"))
+		return m
+	}
+
+	m.whens = append(m.whens, mergeWhen{matched: matched, cond: strings.TrimSpace(cond), action: action})
+	return m
+}
+
+// Placeholders - emit values of the actions as generated variables :sqlb_1, :sqlb_2, ... instead of inline literals.
+// Raw fragments are still inserted as is. The values are returned by TemplateParams and bound by Binder and Q
+func (m *MergeBuilder) Placeholders() *MergeBuilder {
+	m.placeholders = true
+	return m
+}
+
+func (m *MergeBuilder) usesPlaceholders() bool {
+	return m.placeholders
+}
+
+// Err - the first error that occurred
+func (m *MergeBuilder) Err() error {
+	return m.err
+}
+
+// Template - sql template of the query
+func (m *MergeBuilder) Template() (string, error) {
+	return m.template(newCondRenderer(m.placeholders))
+}
+
+// TemplateParams - sql template of the query and the values of the generated variables (see Placeholders)
+func (m *MergeBuilder) TemplateParams() (string, map[string]any, error) {
+	r := newCondRenderer(m.placeholders)
+	sql, err := m.template(r)
+	return sql, r.params, err
+}
+
+func (m *MergeBuilder) template(r *condRenderer) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+
+	if len(m.source) == 0 {
+		return "", nerr.New("MERGE without USING")
+	}
+	if len(m.whens) == 0 {
+		return "", nerr.New("MERGE without WHEN clauses")
+	}
+
+	var sql strings.Builder
+	sql.WriteString("MERGE INTO ")
+	sql.WriteString(m.target)
+	sql.WriteString(" USING ")
+	if m.sourceQuery != nil {
+		query, err := r.subquery(m.sourceQuery)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("USING %s: %v", m.source, err))
+		}
+		sql.WriteString("(" + query + ") ")
+	}
+	sql.WriteString(m.source)
+	sql.WriteString(" ON ")
+	sql.WriteString(m.on)
+
+	for i, w := range m.whens {
+		action, err := w.action.sql(w.matched, r)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("WHEN clause %d: %v", i, err))
+		}
+
+		if w.matched {
+			sql.WriteString(" WHEN MATCHED")
+		} else {
+			sql.WriteString(" WHEN NOT MATCHED")
+		}
+		if len(w.cond) > 0 {
+			sql.WriteString(" AND " + w.cond)
+		}
+		sql.WriteString(" THEN ")
+		sql.WriteString(action)
+	}
+
+	if err := r.embedded.check(sql.String()); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// String - sql template of the query or the error text
+func (m *MergeBuilder) String() string {
+	sql, err := m.Template()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+
+	return sql
+}
+
+// Binder - binder for the query template
+func (m *MergeBuilder) Binder(opts ...BinderOption) *SqlBinder {
+	sql, params, err := m.TemplateParams()
+	return paramsBinder(sql, params, err, opts)
+}
+
+// Q - chainable Query for the query template
+func (m *MergeBuilder) Q(opts ...BinderOption) *Query {
+	return &Query{binder: m.Binder(opts...)}
+}
+
+// Sql - the query, if it has no variables
+func (m *MergeBuilder) Sql() (string, error) {
+	return m.Binder().Sql()
+}
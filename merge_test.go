@@ -0,0 +1,76 @@
+package sqlb
+
+import "testing"
+
+func TestMergeBuilder(t *testing.T) {
+	q := Merge("users u").
+		UsingQuery(Select("id", "name").From("import_users").Where("batch = :batch"), "s", "u.id = s.id").
+		WhenMatched("s.name IS NULL", MergeDelete()).
+		WhenMatched("", MergeUpdate().Set("name", Raw("s.name")).Set("updated", true)).
+		WhenNotMatched("", MergeInsert("id", "name").Values(Raw("s.id"), Raw("s.name")))
+
+	sql, err := q.Binder().MustBind("batch", 5).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "MERGE INTO users u USING (SELECT id, name FROM import_users WHERE batch = 5) s ON u.id = s.id " +
+		"WHEN MATCHED AND s.name IS NULL THEN DELETE " +
+		"WHEN MATCHED THEN UPDATE SET name = s.name, updated = true " +
+		"WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name)"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Merge("users").Using("new_users n", "users.id = n.id").WhenNotMatched("", MergeDoNothing()).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "MERGE INTO users USING new_users n ON users.id = n.id WHEN NOT MATCHED THEN DO NOTHING"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	invalid := []*MergeBuilder{
+		Merge("users").WhenMatched("", MergeDelete()),
+		Merge("users").Using("n", "true"),
+		Merge("users").Using("n", "true").WhenNotMatched("", MergeDelete()),
+		Merge("users").Using("n", "true").WhenMatched("", MergeInsert("id").Values(1)),
+		Merge("users").Using("n", "true").WhenNotMatched("", MergeInsert("id", "name").Values(1)),
+	}
+	for _, m := range invalid {
+		if _, err := m.Template(); err == nil {
+			t.Errorf("expected error for %s", m)
+		}
+	}
+}
+
+func TestMergeBuilder_Placeholders(t *testing.T) {
+	q := Merge("users u").Using("new_users s", "u.id = s.id").
+		WhenMatched("", MergeUpdate().Set("name", Raw("s.name")).Set("updated", true)).
+		WhenNotMatched("", MergeInsert("id", "name").Values(Raw("s.id"), "bob")).
+		Placeholders()
+
+	sql, params, err := q.TemplateParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "MERGE INTO users u USING new_users s ON u.id = s.id " +
+		"WHEN MATCHED THEN UPDATE SET name = s.name, updated = :sqlb_1 " +
+		"WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, :sqlb_2)"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if params["sqlb_1"] != true || params["sqlb_2"] != "bob" || len(params) != 2 {
+		t.Fatalf("unexpected params: %v", params)
+	}
+
+	sql, err = q.Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = "MERGE INTO users u USING new_users s ON u.id = s.id " +
+		"WHEN MATCHED THEN UPDATE SET name = s.name, updated = true " +
+		"WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, E'bob')"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
@@ -0,0 +1,156 @@
+package sqlb
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics - receiver of parse cache and binder metrics, e.g. an adapter to Prometheus.
+// Methods are called synchronously and must be safe for concurrent use
+type Metrics interface {
+	// CacheHit - the template is found in the parse cache
+	CacheHit()
+	// CacheMiss - the template is not found in the parse cache and will be parsed
+	CacheMiss()
+	// Parse - the template is parsed
+	Parse(d time.Duration, err error)
+	// Calculate - the query is calculated
+	Calculate(d time.Duration, err error)
+	// BindError - a value is not bound
+	BindError(variable string, err error)
+}
+
+// TemplateMetrics - optional interface of the Metrics receiver for per-template metrics, e.g. Prometheus histograms
+// with the template key label. The key is the cache key of the template or the hash of its content
+type TemplateMetrics interface {
+	// TemplateCalculate - the query of the template is calculated
+	TemplateCalculate(key string, d time.Duration, err error)
+	// TemplateExecute - the query of the template is executed (see StartSpan). rows is -1 if unknown
+	TemplateExecute(key string, statement string, d time.Duration, rows int64, err error)
+}
+
+var metrics atomic.Pointer[Metrics]
+
+// SetMetrics - set the metrics receiver for the whole package. nil disables metrics
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+
+	metrics.Store(&m)
+}
+
+// currentMetrics - current metrics receiver or nil
+func currentMetrics() Metrics {
+	if m := metrics.Load(); m != nil {
+		return *m
+	}
+
+	return nil
+}
+
+// currentTemplateMetrics - current metrics receiver, if it implements TemplateMetrics, or nil
+func currentTemplateMetrics() TemplateMetrics {
+	if m, ok := currentMetrics().(TemplateMetrics); ok {
+		return m
+	}
+
+	return nil
+}
+
+// ExpvarMetrics - Metrics that publishes counters as an expvar map. Per-template counters are published
+// in the nested map "templates" by the template key
+type ExpvarMetrics struct {
+	vars *expvar.Map
+	// Создание счетчиков шаблона
+	mu sync.Mutex
+}
+
+// NewExpvarMetrics - create metrics published by expvar under the name. Like expvar.Publish, panics if the name is already used
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{vars: expvar.NewMap(name)}
+}
+
+// Vars - published counters
+func (m *ExpvarMetrics) Vars() *expvar.Map {
+	return m.vars
+}
+
+// CacheHit - implementation of Metrics
+func (m *ExpvarMetrics) CacheHit() {
+	m.vars.Add("cache_hits", 1)
+}
+
+// CacheMiss - implementation of Metrics
+func (m *ExpvarMetrics) CacheMiss() {
+	m.vars.Add("cache_misses", 1)
+}
+
+// Parse - implementation of Metrics
+func (m *ExpvarMetrics) Parse(d time.Duration, err error) {
+	m.vars.Add("parses", 1)
+	m.vars.Add("parse_ns", int64(d))
+	if err != nil {
+		m.vars.Add("parse_errors", 1)
+	}
+}
+
+// Calculate - implementation of Metrics
+func (m *ExpvarMetrics) Calculate(d time.Duration, err error) {
+	m.vars.Add("calculations", 1)
+	m.vars.Add("calculate_ns", int64(d))
+	if err != nil {
+		m.vars.Add("calculate_errors", 1)
+	}
+}
+
+// BindError - implementation of Metrics
+func (m *ExpvarMetrics) BindError(_ string, _ error) {
+	m.vars.Add("bind_errors", 1)
+}
+
+// TemplateCalculate - implementation of TemplateMetrics
+func (m *ExpvarMetrics) TemplateCalculate(key string, d time.Duration, err error) {
+	vars := m.template(key)
+	vars.Add("calculations", 1)
+	vars.Add("calculate_ns", int64(d))
+	if err != nil {
+		vars.Add("calculate_errors", 1)
+	}
+}
+
+// TemplateExecute - implementation of TemplateMetrics
+func (m *ExpvarMetrics) TemplateExecute(key string, _ string, d time.Duration, rows int64, err error) {
+	vars := m.template(key)
+	vars.Add("executions", 1)
+	vars.Add("execute_ns", int64(d))
+	if rows > 0 {
+		vars.Add("rows", rows)
+	}
+	if err != nil {
+		vars.Add("execute_errors", 1)
+	}
+}
+
+// template - counters of the template
+func (m *ExpvarMetrics) template(key string) *expvar.Map {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	templates, ok := m.vars.Get("templates").(*expvar.Map)
+	if !ok {
+		templates = new(expvar.Map).Init()
+		m.vars.Set("templates", templates)
+	}
+
+	vars, ok := templates.Get(key).(*expvar.Map)
+	if !ok {
+		vars = new(expvar.Map).Init()
+		templates.Set(key, vars)
+	}
+
+	return vars
+}
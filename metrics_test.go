@@ -0,0 +1,73 @@
+package sqlb
+
+import (
+	"context"
+	"expvar"
+	"testing"
+)
+
+func TestSetMetrics(t *testing.T) {
+	m := NewExpvarMetrics("sqlb_test_metrics")
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	c := NewCache(0)
+	for i := 0; i < 2; i++ {
+		b := NewBinder("SELECT * FROM t WHERE id = :id", WithCacheKey("metrics"), WithCache(c), WithStrictMode())
+		_ = b.Bind("unknown", 1)
+		_, _ = b.Sql()
+	}
+	_, _ = NewBinder("SELECT : FROM t").Sql()
+
+	want := map[string]string{
+		"cache_hits":       "1",
+		"cache_misses":     "1",
+		"parses":           "2",
+		"parse_errors":     "1",
+		"calculations":     "3",
+		"calculate_errors": "3",
+		"bind_errors":      "2",
+	}
+	for name, value := range want {
+		if v := m.Vars().Get(name); v == nil || v.String() != value {
+			t.Errorf("%s: %v, wants: %s", name, v, value)
+		}
+	}
+}
+
+func TestTemplateMetrics(t *testing.T) {
+	m := NewExpvarMetrics("sqlb_test_template_metrics")
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := NewBinder("SELECT * FROM t WHERE id = :id", WithCacheKey("t.by_id")).MustBind("id", i).Sql(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, end := StartSpan(context.Background(), SpanInfo{Operation: SpanExecute, Template: "SELECT * FROM t WHERE id = :id", Key: "t.by_id"})
+	end(5, nil)
+
+	vars, ok := m.Vars().Get("templates").(*expvar.Map)
+	if !ok {
+		t.Fatal("templates map is not published")
+	}
+	tmpl, ok := vars.Get("t.by_id").(*expvar.Map)
+	if !ok {
+		t.Fatal("template counters are not published")
+	}
+
+	want := map[string]string{
+		"calculations": "2",
+		"executions":   "1",
+		"rows":         "5",
+	}
+	for name, value := range want {
+		if v := tmpl.Get(name); v == nil || v.String() != value {
+			t.Errorf("%s: %v, wants: %s", name, v, value)
+		}
+	}
+	if v := tmpl.Get("execute_errors"); v != nil {
+		t.Errorf("unexpected execute_errors: %v", v)
+	}
+}
@@ -0,0 +1,104 @@
+package sqlb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Money - monetary amount in minor currency units (cents).
+// Rendered as '123.45'::numeric without float conversion, so the result doesn't depend on locale or rounding
+type Money struct {
+	// Сумма в минимальных единицах валюты
+	Amount int64
+	// Код валюты ISO 4217. Определяет количество знаков после запятой
+	Currency string
+}
+
+// currencyScales - number of decimal places for currencies which differ from 2
+var currencyScales = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyScale - number of decimal places for the currency (2 if the currency is unknown)
+func CurrencyScale(currency string) int {
+	if scale, ok := currencyScales[strings.ToUpper(currency)]; ok {
+		return scale
+	}
+	return 2
+}
+
+// String - amount as a decimal string, e.g. 123.45
+func (m Money) String() string {
+	return FormatDecimal(m.Amount, CurrencyScale(m.Currency))
+}
+
+// SqlValue - implements SqlValuer
+func (m Money) SqlValue() (string, error) {
+	return `'` + m.String() + `'::numeric`, nil
+}
+
+// FormatDecimal - format integer amount in minor units as a decimal string with the given scale.
+// Only integer arithmetic is used
+func FormatDecimal(amount int64, scale int) string {
+	if scale <= 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	// FormatUint для корректной обработки math.MinInt64
+	sign := ""
+	abs := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		abs = uint64(-amount)
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// ParseMoney - parse decimal string (e.g. "123.45") into Money
+func ParseMoney(amount string, currency string) (Money, error) {
+	scale := CurrencyScale(currency)
+	s := strings.TrimSpace(amount)
+
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	if len(fracPart) > scale {
+		return Money{}, nerr.New(fmt.Sprintf("too many decimal places for %s: %s", currency, amount))
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	if len(intPart) == 0 {
+		intPart = "0"
+	}
+
+	v, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil || strings.ContainsAny(intPart+fracPart, "+-") {
+		return Money{}, nerr.New(fmt.Sprintf("invalid money amount: %s", amount))
+	}
+
+	if neg {
+		v = -v
+	}
+
+	return Money{Amount: v, Currency: currency}, nil
+}
@@ -0,0 +1,41 @@
+package sqlb
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		value  Money
+		result string
+	}{
+		{Money{12345, "USD"}, "'123.45'::numeric"},
+		{Money{-5, "EUR"}, "'-0.05'::numeric"},
+		{Money{500, "JPY"}, "'500'::numeric"},
+		{Money{1234, "KWD"}, "'1.234'::numeric"},
+		{Money{math.MinInt64, "USD"}, "'-92233720368547758.08'::numeric"},
+	}
+
+	for _, test := range tests {
+		sql, err := ToSql(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != test.result {
+			t.Errorf("%s, wants: %s", sql, test.result)
+		}
+	}
+
+	m, err := ParseMoney("-12.3", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != -1230 {
+		t.Errorf("%d, wants: -1230", m.Amount)
+	}
+
+	if _, err := ParseMoney("1.234", "USD"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,50 @@
+package sqlb
+
+// MustBind - same as Bind, but panics on error. Returns the binder for chaining.
+// Intended for static templates, where an error is a programming bug
+func (b *SqlBinder) MustBind(variable string, value any, opts ...Option) *SqlBinder {
+	if err := b.Bind(variable, value, opts...); err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// MustBindValues - same as BindValues, but panics on error. Returns the binder for chaining
+func (b *SqlBinder) MustBindValues(values map[string]any) *SqlBinder {
+	if err := b.BindValues(values); err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// MustSql - same as Sql, but panics on error
+func (b *SqlBinder) MustSql() string {
+	sql, err := b.Sql()
+	if err != nil {
+		panic(err)
+	}
+
+	return sql
+}
+
+// MustBindOne - same as BindOne, but panics on error
+func MustBindOne(template string, variable string, value any, key string) string {
+	sql, err := BindOne(template, variable, value, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return sql
+}
+
+// MustBind - same as Bind, but panics on error
+func MustBind(template string, values map[string]any, key string) string {
+	sql, err := Bind(template, values, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return sql
+}
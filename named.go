@@ -0,0 +1,171 @@
+package sqlb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// FromNamedArgs - convert database/sql named arguments (sql.Named) into a map for BindValues.
+// args may contain sql.NamedArg or *sql.NamedArg only
+func FromNamedArgs(args ...any) (map[string]any, error) {
+	res := make(map[string]any, len(args))
+
+	for i, a := range args {
+		var arg sql.NamedArg
+		switch v := a.(type) {
+		case sql.NamedArg:
+			arg = v
+		case *sql.NamedArg:
+			if v == nil {
+				return nil, nerr.New(fmt.Sprintf("argument %d: nil sql.NamedArg", i))
+			}
+			arg = *v
+		default:
+			return nil, nerr.New(fmt.Sprintf("argument %d: sql.NamedArg expected, got %T", i, a))
+		}
+
+		if len(arg.Name) == 0 {
+			return nil, nerr.New(fmt.Sprintf("argument %d: empty name", i))
+		}
+		if _, ok := res[arg.Name]; ok {
+			return nil, nerr.New(fmt.Sprintf("duplicate argument: %s", arg.Name))
+		}
+
+		res[arg.Name] = arg.Value
+	}
+
+	return res, nil
+}
+
+// ToNamedArgs - convert the bind map into database/sql named arguments, sorted by name.
+// The leading ':' of the variable names is removed
+func ToNamedArgs(values map[string]any) []any {
+	res := make([]any, 0, len(values))
+	for name, value := range values {
+		res = append(res, sql.Named(strings.TrimPrefix(name, ":"), value))
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].(sql.NamedArg).Name < res[j].(sql.NamedArg).Name
+	})
+
+	return res
+}
+
+// BindNamedArgs - bind database/sql named arguments (sql.Named)
+func (b *SqlBinder) BindNamedArgs(args ...any) error {
+	values, err := FromNamedArgs(args...)
+	if err != nil {
+		return err
+	}
+
+	return b.BindValues(values)
+}
+
+// Positional - template with the variables replaced by positional parameters $1, $2... and the arguments for them,
+// for drivers that send the values separately from the query. A repeated variable uses the same parameter.
+// Values are converted via DriverValue. The binder options are applied as by SqlBinder.Positional
+func Positional(template string, values map[string]any, opts ...BinderOption) (string, []any, error) {
+	return NewBinder(template, opts...).Positional(values)
+}
+
+// Positional - template of the binder with the variables replaced by positional parameters $1, $2... and the arguments
+// for them (see the Positional function). Values bound by Bind are not used. The binder options are respected:
+// RequireTenant and WithTenant are checked as for Sql and the tenant identifier is the argument of :tenant_id,
+// in strict mode values of the variables absent in the template are errors, WithValueOptions are applied to the values
+// and WithComment is appended to the query
+func (b *SqlBinder) Positional(values map[string]any) (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	p := b.parcer
+	if err := p.checkParsed(); err != nil {
+		return "", nil, err
+	}
+
+	named := make(map[string]any, len(values)+1)
+	for variable, value := range values {
+		v := variableName(variable)
+		if _, ok := p.parsedMap[v]; !ok && b.strict {
+			return "", nil, nerr.New(fmt.Sprintf("variable not found in template: %s", v))
+		}
+		if b.hasTenant && v == ":"+TenantVariable {
+			return "", nil, nerr.New(v + " is bound by WithTenant")
+		}
+		named[v] = withOptions(value, b.valueOpts)
+	}
+	if b.hasTenant {
+		named[":"+TenantVariable] = withOptions(b.tenant, b.valueOpts)
+	}
+
+	params := make(map[string]string, len(p.parsed))
+	var args []any
+	for _, d := range p.parsed {
+		if _, ok := params[d.name]; ok {
+			continue
+		}
+
+		value, ok := named[d.name]
+		if !ok {
+			return "", nil, nerr.New(fmt.Sprintf("bind value not found for: %s", d.name))
+		}
+
+		arg, err := DriverValue(value)
+		if err != nil {
+			return "", nil, nerr.New(fmt.Sprintf("%s: %v", d.name, err))
+		}
+
+		args = append(args, arg)
+		params[d.name] = fmt.Sprintf("$%d", len(args))
+	}
+
+	sql, err := p.Calculate(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return appendComment(sql, b.comment), args, nil
+}
+
+// DriverValue - argument for the driver, which sends the value separately from the query (see Positional).
+// Value is unwrapped: Json serializes the value, TimeFormat formats time.Time, NullZero turns the zero value into nil.
+// Other values are returned as is
+func DriverValue(v any) (any, error) {
+	val, ok := v.(Value)
+	if !ok {
+		return v, nil
+	}
+
+	o := newOptions(val.opts)
+	if o.xml != xmlNone || o.bits || o.jsonPath {
+		return nil, nerr.New(fmt.Sprintf("options are not supported for positional parameters: %s", o.String()))
+	}
+
+	if o.nullZero && isZero(val.value) {
+		return nil, nil
+	}
+
+	if o.json {
+		if val.value == nil {
+			return nil, nil
+		}
+		data, err := json.Marshal(val.value)
+		if err != nil {
+			return nil, nerr.New(err)
+		}
+		return string(data), nil
+	}
+
+	if t, ok := val.value.(time.Time); ok && len(o.timeFormat) > 0 {
+		return t.Format(o.timeFormat), nil
+	}
+
+	return val.value, nil
+}
@@ -0,0 +1,71 @@
+package sqlb
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestNamedArgs(t *testing.T) {
+	binder := NewBinder("SELECT * FROM t WHERE id = :id AND name = :name")
+	if err := binder.BindNamedArgs(sql.Named("id", 1), sql.Named("name", "a")); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM t WHERE id = 1 AND name = E'a'"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := FromNamedArgs(1); err == nil {
+		t.Fatal("expected error for positional argument")
+	}
+
+	args := ToNamedArgs(map[string]any{":b": 2, "a": 1})
+	if len(args) != 2 || args[0] != sql.Named("a", 1) || args[1] != sql.Named("b", 2) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestPositional(t *testing.T) {
+	sql, args, err := Positional("SELECT * FROM t WHERE id = :id AND data @> :data::jsonb OR parent = :id",
+		map[string]any{"id": 1, ":data": V(map[string]int{"a": 1}, Json())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t WHERE id = $1 AND data @> $2::jsonb OR parent = $1"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if len(args) != 2 || args[0] != 1 || args[1] != `{"a":1}` {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	if _, _, err := Positional("SELECT :a", nil); err == nil {
+		t.Fatal("expected error for missing value")
+	}
+}
+
+func TestSqlBinder_Positional(t *testing.T) {
+	sql, args, err := Positional("SELECT * FROM t WHERE tenant_id = :tenant_id AND id = :id", map[string]any{"id": 1},
+		WithTenant(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t WHERE tenant_id = $1 AND id = $2"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if len(args) != 2 || args[0] != 7 || args[1] != 1 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	if _, _, err := Positional("SELECT :id", map[string]any{"id": 1, "name": 2}, WithStrictMode()); err == nil {
+		t.Fatal("expected error for unknown variable in strict mode")
+	}
+	if _, _, err := Positional("SELECT :id", map[string]any{"id": 1}, WithTenant(7)); err == nil {
+		t.Fatal("expected error for template without :tenant_id")
+	}
+
+	RequireTenant(true)
+	defer RequireTenant(false)
+
+	if _, _, err := Positional("SELECT :id", map[string]any{"id": 1}); err == nil {
+		t.Fatal("expected error without tenant")
+	}
+}
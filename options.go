@@ -0,0 +1,213 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Option - value conversion option for Bind and ToSql
+type Option func(o *options)
+
+// options - value conversion settings
+type options struct {
+	// Преобразование в xml
+	xml xmlMode
+	// Преобразование в битовую строку
+	bits     bool
+	bitWidth int
+	// Сериализация в json
+	json bool
+	// Нулевое значение превращается в null
+	nullZero bool
+	// Формат времени
+	timeFormat string
+	// Преобразование для json_path запроса
+	jsonPath bool
+	// Конфиденциальное значение
+	sensitive bool
+}
+
+// newOptions - collect options
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	return o
+}
+
+// toSql - convert value to sql string taking into account the options
+func (o *options) toSql(v any) (string, error) {
+	if o.nullZero && isZero(v) {
+		return "null", nil
+	}
+
+	if o.json {
+		return jsonToSql(v)
+	}
+
+	if o.jsonPath {
+		return ToJsonPath(v)
+	}
+
+	if len(o.timeFormat) > 0 {
+		if t, ok := v.(time.Time); ok {
+			return prepareString(t.Format(o.timeFormat), `'`, true), nil
+		}
+	}
+
+	if o.xml != xmlNone {
+		return xmlToSql(v, o.xml)
+	}
+
+	if o.bits {
+		return bitsToSql(v, o.bitWidth)
+	}
+
+	val, _, err := toSqlHelper(v, `'`, true)
+	return val, err
+}
+
+// applyInsert - value conversion options are accepted by InsertRows
+func (f Option) applyInsert(o *rowsOptions) {
+	o.values = append(o.values, f)
+}
+
+// applyUpdate - value conversion options are accepted by UpdateRows
+func (f Option) applyUpdate(o *rowsOptions) {
+	o.values = append(o.values, f)
+}
+
+// Json - serialize the value to json and render it as a string literal
+func Json() Option {
+	return func(o *options) {
+		o.json = true
+	}
+}
+
+// JsonPath - convert the value for json_path query. See ToJsonPath
+func JsonPath() Option {
+	return func(o *options) {
+		o.jsonPath = true
+	}
+}
+
+// Value - value with its own conversion options. Allows to pass options through BindValues, Template.Sql
+// and other functions that accept values without options
+type Value struct {
+	value any
+	opts  []Option
+}
+
+// V - create Value. Options of the value are applied after the options of the bind call
+func V(value any, opts ...Option) Value {
+	return Value{
+		value: value,
+		opts:  opts,
+	}
+}
+
+// withOptions - value with the options applied before its own options
+func withOptions(v any, opts []Option) any {
+	if len(opts) == 0 {
+		return v
+	}
+
+	if val, ok := v.(Value); ok {
+		return V(val.value, append(opts[:len(opts):len(opts)], val.opts...)...)
+	}
+
+	return V(v, opts...)
+}
+
+// NullZero - render the zero value of any type as null
+func NullZero() Option {
+	return func(o *options) {
+		o.nullZero = true
+	}
+}
+
+// jsonToSql - convert value to json string literal
+func jsonToSql(v any) (string, error) {
+	if v == nil {
+		return "null", nil
+	}
+
+	var data []byte
+	switch v := v.(type) {
+	case json.RawMessage:
+		data = v
+	case *json.RawMessage:
+		if v == nil {
+			return "null", nil
+		}
+		data = *v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return "", nerr.New(err)
+		}
+	}
+
+	if len(data) == 0 {
+		return "null", nil
+	}
+
+	return prepareString(string(data), `'`, true), nil
+}
+
+// isZero - is the value nil or zero value of its type
+func isZero(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	return reflect.ValueOf(v).IsZero()
+}
+
+// TimeFormat - layout for time.Time values (see time.Layout)
+func TimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = layout
+	}
+}
+
+// String - description of the options for debugging
+func (o *options) String() string {
+	var res []string
+
+	switch o.xml {
+	case xmlDocument:
+		res = append(res, "xmldocument")
+	case xmlCast:
+		res = append(res, "xml")
+	}
+	if o.bits {
+		res = append(res, fmt.Sprintf("bits(%d)", o.bitWidth))
+	}
+	if o.json {
+		res = append(res, "json")
+	}
+	if o.jsonPath {
+		res = append(res, "jsonpath")
+	}
+	if o.nullZero {
+		res = append(res, "nullzero")
+	}
+	if o.sensitive {
+		res = append(res, "sensitive")
+	}
+	if len(o.timeFormat) > 0 {
+		res = append(res, fmt.Sprintf("timeformat(%s)", o.timeFormat))
+	}
+
+	return strings.Join(res, ",")
+}
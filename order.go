@@ -0,0 +1,128 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// NullsOrder - position of null values in the sort order
+type NullsOrder int
+
+const (
+	// NullsDefault - PostgreSql default: last for ascending order, first for descending
+	NullsDefault NullsOrder = iota
+	NullsFirst
+	NullsLast
+)
+
+// OrderSpec - requested sorting by one key
+type OrderSpec struct {
+	// Ключ сортировки, запрошенный пользователем
+	Key string
+	// Сортировка по убыванию
+	Desc bool
+	// Положение null значений
+	Nulls NullsOrder
+}
+
+// ParseOrderSpec - parse sorting request in the form "name,-created_at nulls last", where "-" means descending order.
+// The optional suffix "nulls first" or "nulls last" sets the position of null values
+func ParseOrderSpec(s string) []OrderSpec {
+	var res []OrderSpec
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+
+		spec := OrderSpec{Key: part}
+		if part[0] == '-' || part[0] == '+' {
+			spec.Key = strings.TrimSpace(part[1:])
+			spec.Desc = part[0] == '-'
+		}
+
+		// неизвестный суффикс остается в ключе и не пройдет проверку допустимых ключей
+		if fields := strings.Fields(spec.Key); len(fields) == 3 && strings.EqualFold(fields[1], "nulls") {
+			switch strings.ToLower(fields[2]) {
+			case "first":
+				spec.Key, spec.Nulls = fields[0], NullsFirst
+			case "last":
+				spec.Key, spec.Nulls = fields[0], NullsLast
+			}
+		}
+		res = append(res, spec)
+	}
+
+	return res
+}
+
+// OrderByClause - ORDER BY clause built from the user request.
+// allowed maps sort keys to sql expressions (columns), keys absent from allowed cause an error.
+// Returns "ORDER BY expr1 ASC, expr2 DESC NULLS LAST" or an empty string if nothing is requested
+func OrderByClause(requested []OrderSpec, allowed map[string]string) (string, error) {
+	return orderByToSql(requested, allowed)
+}
+
+// OrderBySpec - add sort expressions built from the user request (see OrderByClause)
+func (s *SelectBuilder) OrderBySpec(requested []OrderSpec, allowed map[string]string) *SelectBuilder {
+	items, err := orderByItems(requested, allowed)
+	if err != nil {
+		s.setErr(err)
+		return s
+	}
+
+	return s.OrderBy(items...)
+}
+
+// BindOrderBy - bind the ORDER BY clause built from the user request.
+// allowed maps sort keys to sql expressions (columns), keys absent from allowed cause an error.
+// The placeholder is replaced with "ORDER BY expr1 ASC, expr2 DESC" or with an empty string if nothing is requested
+func (b *SqlBinder) BindOrderBy(variable string, requested []OrderSpec, allowed map[string]string) error {
+	return b.bindSql(variable, false, requested, nil, func() (string, error) {
+		return orderByToSql(requested, allowed)
+	})
+}
+
+// orderByToSql - ORDER BY clause from the request
+func orderByToSql(requested []OrderSpec, allowed map[string]string) (string, error) {
+	items, err := orderByItems(requested, allowed)
+	if err != nil || len(items) == 0 {
+		return "", err
+	}
+
+	return "ORDER BY " + strings.Join(items, ", "), nil
+}
+
+// orderByItems - sort expressions from the request
+func orderByItems(requested []OrderSpec, allowed map[string]string) ([]string, error) {
+	items := make([]string, 0, len(requested))
+	used := map[string]bool{}
+	for _, spec := range requested {
+		expr, ok := allowed[spec.Key]
+		if !ok || len(strings.TrimSpace(expr)) == 0 {
+			return nil, nerr.New(fmt.Sprintf("sorting by %q is not allowed", spec.Key))
+		}
+
+		if used[spec.Key] {
+			return nil, nerr.New(fmt.Sprintf("duplicate sort key %q", spec.Key))
+		}
+		used[spec.Key] = true
+
+		item := expr + " ASC"
+		if spec.Desc {
+			item = expr + " DESC"
+		}
+
+		switch spec.Nulls {
+		case NullsFirst:
+			item += " NULLS FIRST"
+		case NullsLast:
+			item += " NULLS LAST"
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
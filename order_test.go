@@ -0,0 +1,61 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_BindOrderBy(t *testing.T) {
+	allowed := map[string]string{
+		"name":    "u.name",
+		"created": "u.created_at",
+	}
+
+	binder := NewBinder("SELECT * FROM users u :order")
+	if err := binder.BindOrderBy("order", ParseOrderSpec("-created, name"), allowed); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM users u ORDER BY u.created_at DESC, u.name ASC"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	binder = NewBinder("SELECT * FROM users u :order")
+	if err := binder.BindOrderBy("order", nil, allowed); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := binder.MustSql(), "SELECT * FROM users u "; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	err := NewBinder("SELECT :order").BindOrderBy("order", []OrderSpec{{Key: "password; DROP TABLE users"}}, allowed)
+	if err == nil {
+		t.Fatal("expected error for not allowed key")
+	}
+}
+
+func TestOrderByClause(t *testing.T) {
+	allowed := map[string]string{
+		"name":    "u.name",
+		"created": "u.created_at",
+	}
+
+	sql, err := OrderByClause(ParseOrderSpec("-created NULLS last, name nulls first"), allowed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "ORDER BY u.created_at DESC NULLS LAST, u.name ASC NULLS FIRST"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := OrderByClause(ParseOrderSpec("name nulls somewhere"), allowed); err == nil {
+		t.Fatal("expected error for unknown suffix")
+	}
+	if _, err := OrderByClause(ParseOrderSpec("name,-name"), allowed); err == nil {
+		t.Fatal("expected error for duplicate key")
+	}
+
+	sql, err = Select().From("users u").OrderBySpec(ParseOrderSpec("name"), allowed).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM users u ORDER BY u.name ASC"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
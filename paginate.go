@@ -0,0 +1,157 @@
+package sqlb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Page - OFFSET/LIMIT pagination: page number from 1 and page size
+func (s *SelectBuilder) Page(page int, size int) *SelectBuilder {
+	if page < 1 || size < 1 {
+		s.setErr(nerr.New(fmt.Sprintf("invalid page %d of size %d", page, size)))
+		return s
+	}
+
+	return s.Limit(size).Offset((page - 1) * size)
+}
+
+// Keyset - keyset (cursor) pagination: WHERE (created_at, id) > (v1, v2) ORDER BY created_at, id LIMIT n.
+// The columns must identify a row uniquely, the cursor is built from the values of these columns in the last row of the page
+type Keyset struct {
+	// Columns - sort key columns
+	Columns []string
+	// Desc - descending order
+	Desc bool
+	// Limit - page size
+	Limit int
+}
+
+// Apply - add the condition, the sort order and the limit to the query. An empty cursor means the first page
+func (k Keyset) Apply(s *SelectBuilder, cursor string) *SelectBuilder {
+	cond, err := k.Cond(cursor)
+	if err != nil {
+		s.setErr(err)
+		return s
+	}
+
+	if len(cursor) > 0 {
+		s.WhereCond(cond)
+	}
+
+	return s.OrderBy(k.orderBy()...).Limit(k.Limit)
+}
+
+// Cond - condition for the page after the cursor. For an empty cursor the condition is TRUE
+func (k Keyset) Cond(cursor string) (Cond, error) {
+	if err := k.check(); err != nil {
+		return nil, err
+	}
+
+	if len(cursor) == 0 {
+		return And(), nil
+	}
+
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(k.Columns) {
+		return nil, nerr.New(fmt.Sprintf("cursor has %d values for %d columns", len(values), len(k.Columns)))
+	}
+
+	op := ">"
+	if k.Desc {
+		op = "<"
+	}
+
+	return &rowCompareCond{columns: k.Columns, op: op, values: values}, nil
+}
+
+// Cursor - cursor of the next page from the values of the key columns in the last row
+func (k Keyset) Cursor(values ...any) (string, error) {
+	if err := k.check(); err != nil {
+		return "", err
+	}
+
+	if len(values) != len(k.Columns) {
+		return "", nerr.New(fmt.Sprintf("%d values for %d cursor columns", len(values), len(k.Columns)))
+	}
+
+	return EncodeCursor(values...)
+}
+
+// orderBy - sort expressions
+func (k Keyset) orderBy() []string {
+	res := make([]string, len(k.Columns))
+	for i, c := range k.Columns {
+		if k.Desc {
+			res[i] = c + " DESC"
+		} else {
+			res[i] = c
+		}
+	}
+
+	return res
+}
+
+func (k Keyset) check() error {
+	if len(k.Columns) == 0 {
+		return nerr.New("no keyset columns")
+	}
+
+	return checkExpressions("keyset column", k.Columns)
+}
+
+// EncodeCursor - opaque pagination cursor from the values (base64 of json)
+func EncodeCursor(values ...any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", nerr.New(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor - values of the cursor created by EncodeCursor. Numbers are returned as json.Number,
+// time values as strings, both are accepted by PostgreSql in comparisons with the typed columns
+func DecodeCursor(cursor string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, nerr.New(fmt.Sprintf("invalid cursor: %v", err))
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var values []any
+	if err := d.Decode(&values); err != nil {
+		return nil, nerr.New(fmt.Sprintf("invalid cursor: %v", err))
+	}
+
+	return values, nil
+}
+
+// rowCompareCond - comparison of rows (a, b) > (v1, v2)
+type rowCompareCond struct {
+	columns []string
+	op      string
+	values  []any
+}
+
+func (c *rowCompareCond) renderCond(r *condRenderer) (string, error) {
+	items := make([]string, len(c.values))
+	for i, v := range c.values {
+		var err error
+		if items[i], err = r.value(v); err != nil {
+			return "", nerr.New(fmt.Sprintf("%s: %v", c.columns[i], err))
+		}
+	}
+
+	return "(" + strings.Join(c.columns, ", ") + ") " + c.op + " (" + strings.Join(items, ", ") + ")", nil
+}
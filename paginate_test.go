@@ -0,0 +1,49 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelectBuilder_Page(t *testing.T) {
+	if sql, req := Select().From("t").Page(3, 20).String(), "SELECT * FROM t LIMIT 20 OFFSET 40"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if Select().From("t").Page(0, 20).Err() == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	k := Keyset{Columns: []string{"created_at", "id"}, Desc: true, Limit: 10}
+
+	sql, err := k.Apply(Select().From("t"), "").Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t ORDER BY created_at DESC, id DESC LIMIT 10"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	cursor, err := k.Cursor(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), int64(9007199254740993))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql, err = k.Apply(Select().From("t").Where("a = 1"), cursor).Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT * FROM t WHERE (a = 1) AND (created_at, id) < (E'2024-01-02T03:04:05Z', E'9007199254740993') " +
+		"ORDER BY created_at DESC, id DESC LIMIT 10"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := k.Cond("bad cursor"); err == nil {
+		t.Fatal("expected error for invalid cursor")
+	}
+	if _, err := k.Cursor(1); err == nil {
+		t.Fatal("expected error for values count")
+	}
+}
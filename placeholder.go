@@ -0,0 +1,75 @@
+package sqlb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/n-r-w/nerr"
+)
+
+// ParamPrefix - prefix of the variables generated by the builders in the placeholder mode: :sqlb_1, :sqlb_2, ...
+const ParamPrefix = "sqlb_"
+
+// newCondRenderer - renderer of the builder. In the placeholder mode values are replaced by generated variables
+func newCondRenderer(placeholders bool) *condRenderer {
+	r := &condRenderer{}
+	if placeholders {
+		r.params = map[string]any{}
+	}
+
+	return r
+}
+
+// param - generated variable for the value
+func (r *condRenderer) param(v any) string {
+	opts := r.opts
+	if val, ok := v.(Value); ok {
+		v = val.value
+		opts = append(opts[:len(opts):len(opts)], val.opts...)
+	}
+	if len(opts) > 0 {
+		v = V(v, opts...)
+	}
+
+	name := fmt.Sprintf("%s%d", ParamPrefix, len(r.params)+1)
+	r.params[name] = v
+
+	return ":" + name
+}
+
+// paramsBinder - binder for the builder template with the values of the generated variables bound
+func paramsBinder(template string, params map[string]any, err error, opts []BinderOption) *SqlBinder {
+	b := builderBinder(template, err, opts)
+	if b.err != nil || len(params) == 0 {
+		return b
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := b.Bind(name, params[name]); err != nil {
+			b.err = err
+			break
+		}
+	}
+
+	return b
+}
+
+// placeholderQuery - builder, which can emit placeholders instead of values
+type placeholderQuery interface {
+	usesPlaceholders() bool
+}
+
+// checkEmbeddable - builders in the placeholder mode can't be embedded into other queries, because their values would be lost
+func checkEmbeddable(query any) error {
+	if q, ok := query.(placeholderQuery); ok && q.usesPlaceholders() {
+		return nerr.New("query in the placeholder mode can't be embedded, use Placeholders of the outer query instead")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqlb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuilder_Placeholders(t *testing.T) {
+	q := Select("id").From("users").Placeholders().
+		WhereCond(And(Eq("name", "bob"), In("role", []string{"a", "b"}), JsonContains("data", map[string]int{"x": 1}))).
+		Where("age > :age")
+
+	sql, params, err := q.TemplateParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT id FROM users WHERE (name = :sqlb_1 AND role IN (:sqlb_2, :sqlb_3) AND data @> :sqlb_4::jsonb) AND (age > :age)"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if !reflect.DeepEqual(params["sqlb_1"], "bob") || len(params) != 4 {
+		t.Fatalf("unexpected params: %v", params)
+	}
+
+	sql, err = q.Binder().MustBind("age", 18).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = `SELECT id FROM users WHERE (name = E'bob' AND role IN (E'a', E'b') AND data @> E'{"x":1}'::jsonb) AND (age > 18)`
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Update("users").Set("name", "bob").Set("data", []int{1}, Json()).WhereCond(Eq("id", 1)).Placeholders().Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "UPDATE users SET name = :sqlb_1, data = :sqlb_2 WHERE id = :sqlb_3"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Insert("users").Columns("id", "name").Values(1, "bob").Placeholders().Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "INSERT INTO users (id, name) VALUES (1, E'bob')"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Delete("users").WhereCond(Eq("id", 1)).Placeholders().Binder().Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "DELETE FROM users WHERE id = 1"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Select("*").FromQuery(q, "s").Template(); err == nil {
+		t.Fatal("expected error for embedded query in the placeholder mode")
+	}
+}
@@ -0,0 +1,65 @@
+package sqlb
+
+import (
+	"bytes"
+	"sync"
+)
+
+var binderPool = sync.Pool{
+	New: func() any {
+		return &SqlBinder{
+			values: map[string]string{},
+			buf:    &bytes.Buffer{},
+		}
+	},
+}
+
+// AcquireBinder - get the binder from the pool. Same as NewBinder(template, WithCacheKey(key)),
+// but the memory of a released binder (the values map and the query buffer) is reused. The binder must be returned with ReleaseBinder
+func AcquireBinder(template string, key string) *SqlBinder {
+	b := binderPool.Get().(*SqlBinder)
+	b.parcer, b.err = templateParser(nil, template, key, 0)
+	b.binderOptions = binderOptions{key: key}
+	if b.err == nil {
+		b.err = b.applyTenant()
+	}
+
+	return b
+}
+
+// maxPooledBuffer - max capacity of the query buffer kept by a released binder
+const maxPooledBuffer = 64 << 10
+
+// ReleaseBinder - return the binder to the pool. The binder must not be used after that,
+// strings returned by its Sql remain valid
+func ReleaseBinder(b *SqlBinder) {
+	if b == nil {
+		return
+	}
+
+	for k := range b.values {
+		delete(b.values, k)
+	}
+	if b.values == nil {
+		b.values = map[string]string{}
+	}
+
+	// слишком большой буфер не удерживается пулом
+	if b.buf == nil || b.buf.Cap() > maxPooledBuffer {
+		b.buf = &bytes.Buffer{}
+	}
+	b.buf.Reset()
+
+	b.parcer = nil
+	b.sql = ""
+	b.calculated = false
+	b.defaults = nil
+	b.originals = nil
+	b.defaultOriginals = nil
+	b.sensitive = nil
+	b.trace = nil
+	b.err = nil
+	b.binderOptions = binderOptions{}
+
+	binderPool.Put(b)
+}
@@ -0,0 +1,53 @@
+package sqlb
+
+import "testing"
+
+func TestAcquireBinder(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		b := AcquireBinder("SELECT * FROM t WHERE id = :id", "pool_test")
+		if missing := b.MissingVariables(); len(missing) != 1 {
+			t.Fatalf("released binder is not clean: %v", missing)
+		}
+
+		sql := b.MustBind("id", i).MustSql()
+		ReleaseBinder(b)
+
+		if req := "SELECT * FROM t WHERE id = " + string(rune('0'+i)); sql != req {
+			t.Fatalf("%s, wants: %s", sql, req)
+		}
+	}
+}
+
+func TestReleaseBinder_Buffer(t *testing.T) {
+	b := AcquireBinder("SELECT * FROM t WHERE name = :name", "pool_buf_test")
+	first := b.MustBind("name", "first").MustSql()
+	ReleaseBinder(b)
+
+	// буфер переиспользуется, но ранее полученная строка не меняется
+	b = AcquireBinder("SELECT * FROM t WHERE name = :name", "pool_buf_test")
+	second := b.MustBind("name", "second").MustSql()
+	ReleaseBinder(b)
+
+	if req := "SELECT * FROM t WHERE name = E'first'"; first != req {
+		t.Fatalf("%s, wants: %s", first, req)
+	}
+	if req := "SELECT * FROM t WHERE name = E'second'"; second != req {
+		t.Fatalf("%s, wants: %s", second, req)
+	}
+}
+
+func BenchmarkAcquireBinder(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		binder := AcquireBinder("SELECT * FROM t WHERE id = :id", "pool_bench")
+		_ = binder.MustBind("id", i).MustSql()
+		ReleaseBinder(binder)
+	}
+}
+
+func BenchmarkNewBinder(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewBinder("SELECT * FROM t WHERE id = :id", WithCacheKey("pool_bench")).MustBind("id", i).MustSql()
+	}
+}
@@ -0,0 +1,79 @@
+package sqlb
+
+// Query - chainable facade over SqlBinder: Q(tmpl).Set("id", 5).Set("name", n).Sql().
+// The first error is saved, the following calls are ignored and the error is returned by Sql
+type Query struct {
+	binder *SqlBinder
+	err    error
+}
+
+// Q - create Query for the template
+func Q(template string, opts ...BinderOption) *Query {
+	return &Query{
+		binder: NewBinder(template, opts...),
+	}
+}
+
+// Set - bind the value. See SqlBinder.Bind
+func (q *Query) Set(variable string, value any, opts ...Option) *Query {
+	if q.err == nil {
+		q.err = q.binder.Bind(variable, value, opts...)
+	}
+
+	return q
+}
+
+// SetIf - bind the value only if cond is true. See SqlBinder.BindIf
+func (q *Query) SetIf(cond bool, variable string, value any, opts ...Option) *Query {
+	if q.err == nil {
+		q.err = q.binder.BindIf(cond, variable, value, opts...)
+	}
+
+	return q
+}
+
+// SetIn - bind the list of values for IN. See SqlBinder.BindIn
+func (q *Query) SetIn(variable string, values any, opts ...Option) *Query {
+	if q.err == nil {
+		q.err = q.binder.BindIn(variable, values, opts...)
+	}
+
+	return q
+}
+
+// SetValues - bind all values of the map. See SqlBinder.BindValues
+func (q *Query) SetValues(values map[string]any) *Query {
+	if q.err == nil {
+		q.err = q.binder.BindValues(values)
+	}
+
+	return q
+}
+
+// SetStruct - bind the struct fields. See SqlBinder.BindStruct
+func (q *Query) SetStruct(s any) *Query {
+	if q.err == nil {
+		q.err = q.binder.BindStruct(s)
+	}
+
+	return q
+}
+
+// Err - the first error that occurred
+func (q *Query) Err() error {
+	return q.err
+}
+
+// Binder - the underlying binder
+func (q *Query) Binder() *SqlBinder {
+	return q.binder
+}
+
+// Sql - calculate the query or return the first error that occurred
+func (q *Query) Sql() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+
+	return q.binder.Sql()
+}
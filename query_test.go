@@ -0,0 +1,23 @@
+package sqlb
+
+import "testing"
+
+func TestQuery(t *testing.T) {
+	sql, err := Q("SELECT * FROM t WHERE id = :id AND name = :name AND x IN :x").
+		Set("id", 5).
+		Set("name", "n").
+		SetIn("x", []int{1, 2}).
+		SetIf(false, "y", 1).
+		Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t WHERE id = 5 AND name = E'n' AND x IN (1, 2)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	q := Q("SELECT :a").Set("", 1).Set("a", 1)
+	if _, err := q.Sql(); err == nil || err != q.Err() {
+		t.Fatalf("expected deferred error, got: %v", err)
+	}
+}
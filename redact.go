@@ -0,0 +1,94 @@
+package sqlb
+
+import (
+	"path"
+	"strings"
+)
+
+// redactedValue - replacement of sensitive values in SqlRedacted
+const redactedValue = "[REDACTED]"
+
+// Sensitive - the value is confidential and is replaced with [REDACTED] in SqlRedacted
+func Sensitive() Option {
+	return func(o *options) {
+		o.sensitive = true
+	}
+}
+
+// WithRedactPatterns - patterns of variable names (see path.Match, case insensitive, without ':'),
+// whose values are replaced with [REDACTED] in SqlRedacted. Example: "*password*", "token"
+func WithRedactPatterns(patterns ...string) BinderOption {
+	return func(o *binderOptions) {
+		for _, p := range patterns {
+			o.redact = append(o.redact, strings.ToLower(strings.TrimPrefix(p, ":")))
+		}
+	}
+}
+
+// SqlRedacted - the query with sensitive values (Sensitive option or WithRedactPatterns) replaced with [REDACTED].
+// Intended for logging. Doesn't affect the result of Sql
+func (b *SqlBinder) SqlRedacted() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	values := b.calcValues()
+
+	redacted := make(map[string]string, len(values))
+	for v, val := range values {
+		if b.isSensitive(v) {
+			redacted[v] = redactedValue
+		} else {
+			redacted[v] = val
+		}
+	}
+
+	return b.parcer.Calculate(redacted)
+}
+
+// markSensitive - remember the variable if its value is bound with the Sensitive option
+func (b *SqlBinder) markSensitive(variable string, value any, opts []Option) {
+	if v, ok := value.(Value); ok {
+		opts = append(opts[:len(opts):len(opts)], v.opts...)
+	}
+
+	// отметка не снимается при перезаписи значения, лучше скрыть лишнее
+	if !newOptions(b.valueOptions(opts)).sensitive {
+		return
+	}
+
+	if b.sensitive == nil {
+		b.sensitive = map[string]bool{}
+	}
+	b.sensitive[variableName(variable)] = true
+}
+
+// isSensitive - should the value of the variable be redacted
+func (b *SqlBinder) isSensitive(variable string) bool {
+	if b.sensitive[variable] {
+		return true
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(variable, ":"))
+	for _, p := range b.redact {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copySensitive - copy of the set of sensitive variables
+func copySensitive(s map[string]bool) map[string]bool {
+	if s == nil {
+		return nil
+	}
+
+	res := make(map[string]bool, len(s))
+	for k, v := range s {
+		res[k] = v
+	}
+
+	return res
+}
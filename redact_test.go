@@ -0,0 +1,26 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_SqlRedacted(t *testing.T) {
+	binder := NewBinder("UPDATE users SET password = :user_password, email = :email, name = :name WHERE id = :id",
+		WithRedactPatterns("*password*"))
+	binder.MustBind("user_password", "secret").
+		MustBind("email", "a@b.c", Sensitive()).
+		MustBind("name", "bob").
+		MustBind("id", 1)
+
+	redacted, err := binder.SqlRedacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "UPDATE users SET password = [REDACTED], email = [REDACTED], name = E'bob' WHERE id = 1"
+	if redacted != req {
+		t.Fatalf("%s, wants: %s", redacted, req)
+	}
+
+	req = "UPDATE users SET password = E'secret', email = E'a@b.c', name = E'bob' WHERE id = 1"
+	if sql := binder.MustSql(); sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
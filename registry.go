@@ -0,0 +1,152 @@
+package sqlb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Registry - named sql templates. Each template is parsed once at registration.
+// Registry is safe for concurrent use
+type Registry struct {
+	mu        sync.RWMutex
+	templates map[string]*Template
+}
+
+// NewRegistry - create Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		templates: map[string]*Template{},
+	}
+}
+
+// Register - parse and register the template under the name. Registering the same name twice is an error
+func (r *Registry) Register(name string, template string) error {
+	if len(name) == 0 {
+		return nerr.New("empty template name")
+	}
+
+	t, err := Compile(template)
+	if err != nil {
+		return nerr.New(fmt.Sprintf("template %s: %v", name, err))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.templates[name]; ok {
+		return nerr.New(fmt.Sprintf("template already registered: %s", name))
+	}
+	r.templates[name] = t
+
+	return nil
+}
+
+// MustRegister - same as Register, but panics on error
+func (r *Registry) MustRegister(name string, template string) {
+	if err := r.Register(name, template); err != nil {
+		panic(err)
+	}
+}
+
+// Template - registered template by name
+func (r *Registry) Template(name string) (*Template, error) {
+	r.mu.RLock()
+	t, ok := r.templates[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, nerr.New(fmt.Sprintf("template not registered: %s", name))
+	}
+
+	return t, nil
+}
+
+// Binder - new binder for the registered template
+func (r *Registry) Binder(name string, opts ...BinderOption) (*SqlBinder, error) {
+	t, err := r.Template(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Binder(opts...), nil
+}
+
+// Sql - substitute values into the registered template. See Template.Sql
+func (r *Registry) Sql(name string, values map[string]any) (string, error) {
+	t, err := r.Template(name)
+	if err != nil {
+		return "", err
+	}
+
+	return t.Sql(values)
+}
+
+// Names - sorted names of the registered templates
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// LoadTemplates - create Registry from the files matching glob (see fs.Glob), e.g. "queries/*.sql".
+// The template name is the file path. All files are parsed, errors are reported together
+func LoadTemplates(fsys fs.FS, glob string) (*Registry, error) {
+	r := NewRegistry()
+	if err := r.Load(fsys, glob); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Load - register the files matching glob (see fs.Glob). The template name is the file path.
+// All files are processed, errors are combined by errors.Join
+func (r *Registry) Load(fsys fs.FS, glob string) error {
+	files, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nerr.New(err)
+	}
+
+	var errs []error
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+
+		if err := r.Register(file, string(data)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// set - register or replace the template
+func (r *Registry) set(name string, t *Template) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[name] = t
+}
+
+// remove - unregister the template
+func (r *Registry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.templates, name)
+}
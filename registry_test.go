@@ -0,0 +1,65 @@
+package sqlb
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister("users.by_email", "SELECT * FROM users WHERE email = :email")
+
+	if err := reg.Register("users.by_email", "SELECT 1"); err == nil {
+		t.Fatal("expected duplicate error")
+	}
+	if err := reg.Register("bad", "SELECT : FROM t"); err == nil {
+		t.Fatal("expected parse error")
+	}
+
+	sql, err := reg.Sql("users.by_email", map[string]any{"email": "a@b.c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM users WHERE email = E'a@b.c'"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	b, err := reg.Binder("users.by_email", WithStrictMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Bind("mail", 1); err == nil {
+		t.Fatal("expected strict mode error")
+	}
+
+	if _, err := reg.Binder("unknown"); err == nil {
+		t.Fatal("expected not registered error")
+	}
+	if names := reg.Names(); len(names) != 1 || names[0] != "users.by_email" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/users.sql":  {Data: []byte("SELECT * FROM users WHERE id = :id")},
+		"sql/orders.sql": {Data: []byte("SELECT * FROM orders")},
+		"sql/readme.txt": {Data: []byte("not a template")},
+	}
+
+	reg, err := LoadTemplates(fsys, "sql/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := reg.Names(); len(names) != 2 || names[0] != "sql/orders.sql" || names[1] != "sql/users.sql" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	fsys["sql/bad1.sql"] = &fstest.MapFile{Data: []byte("SELECT * FROM t WHERE a = : AND b = 1")}
+	fsys["sql/bad2.sql"] = &fstest.MapFile{Data: []byte("SELECT : FROM t")}
+	_, err = LoadTemplates(fsys, "sql/*.sql")
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("expected 2 errors, got: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package sqlb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// ScanRows - scan all rows into dest, a pointer to a slice of structs, pointers to structs or single column values.
+// Columns are matched to the fields in the same way as BindStruct: the lowercase field name or the name from the tag db:"name",
+// db:"-" excludes the field, fields of embedded structs are matched as if they were fields of the outer struct.
+// Tag modifiers: json - unmarshal the column from json, nullzero - null is scanned as the zero value.
+// Every column must have a field. rows are closed
+func ScanRows(rows *sql.Rows, dest any) error {
+	defer rows.Close()
+
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Pointer || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return nerr.New(fmt.Sprintf("pointer to slice expected, got %T", dest))
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Pointer
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nerr.New(err)
+	}
+
+	var fields []scanField
+	if isScanStruct(elemType) {
+		if fields, err = scanFields(elemType, columns); err != nil {
+			return err
+		}
+	} else if len(columns) != 1 {
+		return nerr.New(fmt.Sprintf("single column expected for %v, got %d", elemType, len(columns)))
+	}
+
+	for rows.Next() {
+		elem := reflect.New(elemType).Elem()
+
+		targets := make([]any, len(columns))
+		if fields == nil {
+			targets[0] = elem.Addr().Interface()
+		} else {
+			for i, f := range fields {
+				targets[i] = f.target(elem)
+			}
+		}
+
+		if err := rows.Scan(targets...); err != nil {
+			return nerr.New(err)
+		}
+
+		for i, f := range fields {
+			if err := f.assign(elem, targets[i]); err != nil {
+				return nerr.New(fmt.Sprintf("column %s: %v", columns[i], err))
+			}
+		}
+
+		if isPtr {
+			elem = elem.Addr()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nerr.New(err)
+	}
+
+	return nil
+}
+
+// scanField - struct field for the column
+type scanField struct {
+	// Путь к полю, включая встроенные структуры
+	index []int
+	// Десериализация из json
+	json bool
+	// null превращается в нулевое значение
+	nullZero bool
+}
+
+// target - destination for rows.Scan
+func (f scanField) target(elem reflect.Value) any {
+	field := fieldByIndex(elem, f.index)
+
+	switch {
+	case f.json:
+		return new([]byte)
+	case f.nullZero:
+		return reflect.New(reflect.PointerTo(field.Type())).Interface()
+	default:
+		return field.Addr().Interface()
+	}
+}
+
+// assign - copy the scanned value into the field
+func (f scanField) assign(elem reflect.Value, target any) error {
+	if !f.json && !f.nullZero {
+		return nil
+	}
+
+	field := fieldByIndex(elem, f.index)
+
+	if f.json {
+		data := *target.(*[]byte)
+		if data == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		return json.Unmarshal(data, field.Addr().Interface())
+	}
+
+	if ptr := reflect.ValueOf(target).Elem(); ptr.IsNil() {
+		field.Set(reflect.Zero(field.Type()))
+	} else {
+		field.Set(ptr.Elem())
+	}
+
+	return nil
+}
+
+// scanFields - fields of the struct for the columns
+func scanFields(t reflect.Type, columns []string) ([]scanField, error) {
+	byName := map[string]scanField{}
+	collectScanFields(t, nil, byName)
+
+	res := make([]scanField, len(columns))
+	for i, c := range columns {
+		f, ok := byName[strings.ToLower(c)]
+		if !ok {
+			return nil, nerr.New(fmt.Sprintf("no field for column %s in %v", c, t))
+		}
+		res[i] = f
+	}
+
+	return res, nil
+}
+
+// collectScanFields - fields of the struct by the lowercase name according to the db tags (see ScanRows)
+func collectScanFields(t reflect.Type, index []int, res map[string]scanField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := parseStructTag(tag)
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		if field.Anonymous && len(name) == 0 && len(opts) == 0 {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				if !field.IsExported() {
+					// указатель на неэкспортируемую структуру нельзя создать
+					continue
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectScanFields(ft, fieldIndex, res)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		name = strings.ToLower(name)
+
+		// поля внешней структуры имеют приоритет над полями встроенных
+		if _, ok := res[name]; ok && len(fieldIndex) > 1 {
+			continue
+		}
+
+		o := newOptions(opts)
+		res[name] = scanField{
+			index:    fieldIndex,
+			json:     o.json,
+			nullZero: o.nullZero,
+		}
+	}
+}
+
+// fieldByIndex - field of the struct, nil pointers to embedded structs are allocated
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// isScanStruct - struct, which is scanned field by field, not as a single value
+func isScanStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem())
+}
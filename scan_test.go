@@ -0,0 +1,109 @@
+package sqlb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// rowsDriver - driver, which returns the same rows for any query
+type rowsDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *rowsDriver) Open(string) (driver.Conn, error)    { return d, nil }
+func (d *rowsDriver) Prepare(string) (driver.Stmt, error) { return d, nil }
+func (d *rowsDriver) Close() error                        { return nil }
+func (d *rowsDriver) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+func (d *rowsDriver) NumInput() int                       { return -1 }
+func (d *rowsDriver) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (d *rowsDriver) Query([]driver.Value) (driver.Rows, error) {
+	return &driverRows{d: d}, nil
+}
+
+type driverRows struct {
+	d   *rowsDriver
+	pos int
+}
+
+func (r *driverRows) Columns() []string { return r.d.columns }
+func (r *driverRows) Close() error      { return nil }
+func (r *driverRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+type scanBase struct {
+	ID      int
+	Created time.Time `db:"created_at"`
+}
+
+type scanUser struct {
+	scanBase
+	Name  string            `db:"name,nullzero"`
+	Attrs map[string]string `db:"attrs,json"`
+	Note  *string
+	Skip  string `db:"-"`
+}
+
+func TestScanRows(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := &rowsDriver{
+		columns: []string{"id", "created_at", "name", "attrs", "note"},
+		rows: [][]driver.Value{
+			{int64(1), created, "bob", []byte(`{"a":"b"}`), "x"},
+			{int64(2), created, nil, nil, nil},
+		},
+	}
+	sql.Register("sqlb_scan", d)
+	db, err := sql.Open("sqlb_scan", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users []*scanUser
+	if err := ScanRows(rows, &users); err != nil {
+		t.Fatal(err)
+	}
+
+	note := "x"
+	req := []*scanUser{
+		{scanBase: scanBase{ID: 1, Created: created}, Name: "bob", Attrs: map[string]string{"a": "b"}, Note: &note},
+		{scanBase: scanBase{ID: 2, Created: created}},
+	}
+	if !reflect.DeepEqual(users, req) {
+		t.Fatalf("%+v, wants: %+v", users, req)
+	}
+
+	d.columns = []string{"id"}
+	d.rows = [][]driver.Value{{int64(1)}, {int64(2)}}
+	rows, _ = db.Query("SELECT")
+	var ids []int
+	if err := ScanRows(rows, &ids); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	d.columns = []string{"unknown"}
+	rows, _ = db.Query("SELECT")
+	if err := ScanRows(rows, &users); err == nil {
+		t.Fatal("expected error for column without field")
+	}
+}
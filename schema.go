@@ -0,0 +1,487 @@
+package sqlb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/n-r-w/nerr"
+)
+
+// Schema - description of the database tables for query validation: table -> column -> type.
+// Table names may be schema-qualified, types are PostgreSql type names ("bigint", "text", "timestamptz", "uuid[]").
+// Unknown and empty types are not checked. Names in the schema are the names stored in the database (case sensitive),
+// names in the queries follow the PostgreSql rules: unquoted names are folded to lower case
+type Schema map[string]map[string]string
+
+var registeredSchema atomic.Pointer[Schema]
+
+// SetSchema - register the schema for the Validate methods of the builders. nil disables the validation
+func SetSchema(s Schema) {
+	if s == nil {
+		registeredSchema.Store(nil)
+		return
+	}
+
+	registeredSchema.Store(&s)
+}
+
+// currentSchema - registered schema or nil
+func currentSchema() Schema {
+	if s := registeredSchema.Load(); s != nil {
+		return *s
+	}
+
+	return nil
+}
+
+// Validate - check the query builder (SelectBuilder, InsertBuilder, UpdateBuilder, DeleteBuilder) against the schema.
+// Tables and columns must exist, the values of conditions, SET and VALUES must be compatible with the column types.
+// Only plain column names ("name", "u.name") are checked, expressions are skipped
+func (s Schema) Validate(query any) error {
+	switch q := query.(type) {
+	case *SelectBuilder:
+		if _, err := q.Template(); err != nil {
+			return err
+		}
+		return s.validateSelect(q)
+	case *InsertBuilder:
+		if _, err := q.Template(); err != nil {
+			return err
+		}
+		return s.validateInsert(q)
+	case *UpdateBuilder:
+		if _, err := q.Template(); err != nil {
+			return err
+		}
+		return s.validateTable(q.table, q.where, q.returning, func(sc *schemaScope) error {
+			for _, v := range q.set {
+				if err := sc.checkValue(v.column, V(v.value, v.opts...)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case *DeleteBuilder:
+		if _, err := q.Template(); err != nil {
+			return err
+		}
+		return s.validateTable(q.table, q.where, q.returning, nil)
+	}
+
+	return nerr.New(fmt.Sprintf("unsupported query type %T", query))
+}
+
+// validateSchema - check the query against the registered schema, if any
+func validateSchema(query any) error {
+	s := currentSchema()
+	if s == nil {
+		return nil
+	}
+
+	return s.Validate(query)
+}
+
+func (s Schema) validateSelect(q *SelectBuilder) error {
+	sc := &schemaScope{schema: s, tables: map[string]map[string]string{}, ctes: map[string]bool{}}
+	for _, c := range q.ctes {
+		sc.ctes[aliasKey(c.name)] = true
+	}
+
+	if len(q.from) > 0 {
+		var err error
+		if q.fromQuery != nil {
+			sc.addUnknown(q.fromAlias)
+		} else {
+			err = sc.addTable(q.from)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range q.joinTables {
+		if err := sc.addTable(t); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range q.columns {
+		if c.query != nil {
+			continue
+		}
+		if _, err := sc.checkColumn(columnExpr(c.expr)); err != nil {
+			return err
+		}
+	}
+
+	if err := sc.checkConds(q.where); err != nil {
+		return err
+	}
+
+	return sc.checkConds(q.having)
+}
+
+func (s Schema) validateInsert(q *InsertBuilder) error {
+	return s.validateTable(q.table, nil, q.returning, func(sc *schemaScope) error {
+		for _, c := range q.columns {
+			if _, err := sc.checkColumn(c); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range q.rows {
+			for i, v := range row {
+				if i < len(q.columns) {
+					if err := sc.checkValue(q.columns[i], v); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// validateTable - check the query for one table: conditions, RETURNING and the specific part
+func (s Schema) validateTable(table string, where []Cond, returning []string, fn func(sc *schemaScope) error) error {
+	sc := &schemaScope{schema: s, tables: map[string]map[string]string{}}
+	if err := sc.addTable(table); err != nil {
+		return err
+	}
+
+	if fn != nil {
+		if err := fn(sc); err != nil {
+			return err
+		}
+	}
+
+	if err := sc.checkConds(where); err != nil {
+		return err
+	}
+
+	for _, r := range returning {
+		if _, err := sc.checkColumn(columnExpr(r)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schemaScope - tables of the query by aliases
+type schemaScope struct {
+	schema Schema
+	// Колонки таблиц по псевдонимам. nil - источник с неизвестными колонками (подзапрос, CTE)
+	tables map[string]map[string]string
+	ctes   map[string]bool
+	// Есть источники с неизвестными колонками
+	unknown bool
+}
+
+// addUnknown - source with unknown columns
+func (sc *schemaScope) addUnknown(alias string) {
+	sc.unknown = true
+	if len(alias) > 0 {
+		sc.tables[aliasKey(alias)] = nil
+	}
+}
+
+// addTable - table expression "users u"
+func (sc *schemaScope) addTable(expr string) error {
+	alias := tableAlias(expr)
+	fields := strings.Fields(expr)
+	if len(fields) == 0 || strings.ContainsAny(fields[0], "()") || sc.ctes[aliasKey(fields[0])] {
+		sc.addUnknown(alias)
+		return nil
+	}
+
+	columns, ok := sc.schema.table(fields[0])
+	if !ok {
+		return nerr.New(fmt.Sprintf("table %s not found in schema", fields[0]))
+	}
+
+	if len(alias) > 0 {
+		sc.tables[aliasKey(alias)] = columns
+	}
+
+	return nil
+}
+
+// checkColumn - check the column name ("name", "u.name") and return its type. Expressions are not checked
+func (sc *schemaScope) checkColumn(expr string) (string, error) {
+	parts, ok := identParts(expr)
+	if !ok {
+		return "", nil
+	}
+
+	column := parts[len(parts)-1]
+	if len(parts) > 1 {
+		columns, found := sc.tables[identKey(parts[len(parts)-2])]
+		if !found || columns == nil {
+			// внешний запрос или источник с неизвестными колонками
+			return "", nil
+		}
+
+		typ, found := findColumn(columns, column)
+		if !found {
+			return "", nerr.New(fmt.Sprintf("column %s not found in schema", expr))
+		}
+		return typ, nil
+	}
+
+	for _, columns := range sc.tables {
+		if typ, found := findColumn(columns, column); found {
+			return typ, nil
+		}
+	}
+
+	if sc.unknown {
+		return "", nil
+	}
+
+	return "", nerr.New(fmt.Sprintf("column %s not found in schema", expr))
+}
+
+// checkValue - check the column and the compatibility of the value with its type
+func (sc *schemaScope) checkValue(column string, v any) error {
+	typ, err := sc.checkColumn(column)
+	if err != nil || len(typ) == 0 {
+		return err
+	}
+
+	if !typeAccepts(typ, v) {
+		if val, ok := v.(Value); ok {
+			v = val.value
+		}
+		return nerr.New(fmt.Sprintf("column %s of type %s: incompatible value of type %T", column, typ, v))
+	}
+
+	return nil
+}
+
+// checkConds - check the columns and values of the conditions
+func (sc *schemaScope) checkConds(conds []Cond) error {
+	for _, c := range conds {
+		var err error
+		switch c := c.(type) {
+		case *compareCond:
+			err = sc.checkValue(c.column, c.value)
+		case *betweenCond:
+			if err = sc.checkValue(c.column, c.from); err == nil {
+				err = sc.checkValue(c.column, c.to)
+			}
+		case *inCond:
+			err = sc.checkInValues(c.column, c.values)
+		case *logicCond:
+			err = sc.checkConds(c.conds)
+		case *notCond:
+			err = sc.checkConds([]Cond{c.cond})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sc *schemaScope) checkInValues(column string, values any) error {
+	if _, err := sc.checkColumn(column); err != nil {
+		return err
+	}
+
+	e := reflect.ValueOf(values)
+	if e.Kind() != reflect.Slice && e.Kind() != reflect.Array {
+		return nil
+	}
+
+	for i := 0; i < e.Len(); i++ {
+		if err := sc.checkValue(column, e.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// table - columns of the table
+func (s Schema) table(name string) (map[string]string, bool) {
+	for key, columns := range s {
+		if sameTable(key, name) {
+			return columns, true
+		}
+	}
+
+	return nil, false
+}
+
+// sameTable - the table name from the query matches the name stored in the database.
+// An unqualified name matches a schema-qualified one and vice versa
+func sameTable(stored, name string) bool {
+	parts, ok := identParts(name)
+	if !ok {
+		return false
+	}
+
+	storedParts := strings.Split(stored, ".")
+	if storedParts[len(storedParts)-1] != identKey(parts[len(parts)-1]) {
+		return false
+	}
+
+	return len(storedParts) == 1 || len(parts) == 1 || storedParts[0] == identKey(parts[0])
+}
+
+// findColumn - column type by name
+func findColumn(columns map[string]string, name string) (string, bool) {
+	typ, ok := columns[identKey(name)]
+	return typ, ok
+}
+
+// columnExpr - expression without the alias: "name AS n" -> name
+func columnExpr(expr string) string {
+	fields := strings.Fields(expr)
+	switch {
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		return fields[0]
+	case len(fields) == 2:
+		if _, ok := identParts(fields[0]); ok {
+			return fields[0]
+		}
+	}
+
+	return strings.TrimSpace(expr)
+}
+
+// identParts - parts of the qualified name "schema.table.column". false for expressions
+func identParts(expr string) ([]string, bool) {
+	parts := strings.Split(strings.TrimSpace(expr), ".")
+	for _, p := range parts {
+		if strings.HasPrefix(p, `"`) && strings.HasSuffix(p, `"`) && len(p) > 2 {
+			continue
+		}
+
+		for i, r := range p {
+			if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && (r >= '0' && r <= '9' || r == '$')) {
+				return nil, false
+			}
+		}
+		if len(p) == 0 {
+			return nil, false
+		}
+	}
+
+	return parts, true
+}
+
+// identKey - name for comparison: quoted names are case sensitive
+func identKey(name string) string {
+	if strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) && len(name) > 1 {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+
+	return strings.ToLower(name)
+}
+
+// typeAccepts - is the Go value compatible with the PostgreSql type. Strings are accepted by any type
+func typeAccepts(typ string, v any) bool {
+	if val, ok := v.(Value); ok {
+		if newOptions(val.opts).json {
+			switch pgTypeCategory(typ) {
+			case "json", "string", "":
+				return true
+			}
+			return false
+		}
+		v = val.value
+	}
+
+	pg := pgTypeCategory(typ)
+	goType := goTypeCategory(v)
+	switch {
+	case len(pg) == 0 || len(goType) == 0 || goType == "string" || pg == "json":
+		return true
+	case pg == "string":
+		return goType == "uuid"
+	}
+
+	return pg == goType
+}
+
+// pgTypeCategory - category of the PostgreSql type. Empty for unknown types
+func pgTypeCategory(typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if strings.HasSuffix(typ, "[]") || strings.HasPrefix(typ, "_") {
+		return "array"
+	}
+	if i := strings.Index(typ, "("); i >= 0 {
+		typ = strings.TrimSpace(typ[:i])
+	}
+
+	switch typ {
+	case "smallint", "int2", "integer", "int", "int4", "bigint", "int8", "smallserial", "serial", "bigserial",
+		"real", "float4", "double precision", "float8", "numeric", "decimal", "money":
+		return "number"
+	case "text", "varchar", "character varying", "char", "character", "bpchar", "citext", "name":
+		return "string"
+	case "boolean", "bool":
+		return "bool"
+	case "timestamp", "timestamptz", "timestamp with time zone", "timestamp without time zone", "date",
+		"time", "timetz", "time with time zone", "time without time zone":
+		return "time"
+	case "interval":
+		return "interval"
+	case "uuid":
+		return "uuid"
+	case "json", "jsonb":
+		return "json"
+	case "bytea":
+		return "bytea"
+	}
+
+	return ""
+}
+
+// goTypeCategory - category of the Go value matching pgTypeCategory. Empty for unknown types and null
+func goTypeCategory(v any) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return "time"
+	case time.Duration:
+		return "interval"
+	case uuid.UUID:
+		return "uuid"
+	case []byte:
+		return "bytea"
+	case json.RawMessage:
+		return "json"
+	}
+
+	e := reflect.ValueOf(v)
+	switch e.Kind() {
+	case reflect.Pointer:
+		if e.IsNil() {
+			return ""
+		}
+		return goTypeCategory(e.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "number"
+	}
+
+	return ""
+}
@@ -0,0 +1,64 @@
+package sqlb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSchema = Schema{
+	"public.users": {"id": "bigint", "name": "text", "created_at": "timestamptz", "Active": "boolean"},
+	"orders":       {"id": "bigint", "user_id": "bigint", "amount": "numeric(10,2)", "tags": "text[]"},
+}
+
+func TestSchema_Validate(t *testing.T) {
+	valid := []any{
+		Select("u.id", "u.name AS n", "count(*)", "o.*").From("users u").
+			Join("orders o", "o.user_id = u.id").
+			WhereCond(And(Eq("u.name", "bob"), In("o.id", []int{1, 2}), Gt("created_at", time.Now()))),
+		Select(`"Active"`).From("public.users").WhereCond(Eq("id", "42")),
+		Select("x.total").FromQuery("SELECT 1 AS total", "x"),
+		Insert("users").Columns("id", "name").Values(1, "bob").Returning("id"),
+		Update("orders").Set("amount", 10.5).Set("tags", []string{"a"}).WhereCond(Eq("id", 1)),
+		Delete("users").WhereCond(Between("created_at", time.Now(), time.Now())),
+	}
+	for _, q := range valid {
+		if err := testSchema.Validate(q); err != nil {
+			t.Errorf("%v: %v", q, err)
+		}
+	}
+
+	invalid := map[string]any{
+		"table users_old not found":       Select("id").From("users_old"),
+		"column u.login not found":        Select("u.login").From("users u"),
+		"column login not found":          Select("id").From("users").WhereCond(Eq("login", "bob")),
+		"column active not found":         Select("active").From("users"),
+		"column user_id of type bigint":   Select("id").From("orders").WhereCond(In("user_id", []bool{true})),
+		"column name of type text":        Insert("users").Columns("id", "name").Values(1, 2),
+		"column amount of type numeric":   Update("orders").Set("amount", true).WhereCond(Eq("id", 1)),
+		"column created_at of type times": Delete("users").WhereCond(Not(Lt("created_at", 5))),
+	}
+	for want, q := range invalid {
+		err := testSchema.Validate(q)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%v, wants: %s", err, want)
+		}
+	}
+}
+
+func TestSetSchema(t *testing.T) {
+	q := Select("login").From("users")
+	if err := q.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	SetSchema(testSchema)
+	defer SetSchema(nil)
+
+	if err := q.Validate(); err == nil {
+		t.Fatal("expected error for unknown column")
+	}
+	if err := Delete("users").AllRows().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,521 @@
+package sqlb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// SelectBuilder - builder of SELECT query templates. Expressions are inserted as is and may contain :variables,
+// which are bound by the binder returned by Binder or Q, so the query is built once and the values are converted via ToSql.
+// The first error is saved and returned by Template
+type SelectBuilder struct {
+	ctes      []cte
+	recursive bool
+	distinct  bool
+	// Выражения DISTINCT ON
+	distinctOn []string
+	columns    []selectColumn
+	from       string
+	fromQuery  any
+	joins      []selectJoin
+	// Таблицы соединений (для проверки по схеме)
+	joinTables []string
+	// Определенные псевдонимы таблиц
+	aliases   map[string]bool
+	fromAlias string
+	where     []Cond
+	groupBy   []string
+	having    []Cond
+	orderBy   []string
+	// Отрицательное значение - не задано
+	limit  int
+	offset int
+	// Блокировки строк
+	locks []string
+	// Не добавлять условие мягкого удаления
+	includeDeleted bool
+	// Режим подстановки переменных вместо значений
+	placeholders bool
+	err          error
+}
+
+// Select - create SELECT builder. Without columns "*" is selected
+func Select(columns ...string) *SelectBuilder {
+	s := &SelectBuilder{
+		limit:  -1,
+		offset: -1,
+	}
+	s.addColumns(columns)
+
+	return s
+}
+
+// setErr - save the first error
+func (s *SelectBuilder) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// When - call fn with the builder, if cond is true, so optional parts can be added without breaking the chain
+func (s *SelectBuilder) When(cond bool, fn func(b *SelectBuilder)) *SelectBuilder {
+	if cond && fn != nil {
+		fn(s)
+	}
+
+	return s
+}
+
+// selectColumn - column expression or scalar subquery
+type selectColumn struct {
+	expr  string
+	query any
+	alias string
+}
+
+func (s *SelectBuilder) addColumns(columns []string) {
+	s.setErr(checkExpressions("column", columns))
+	for _, c := range columns {
+		s.columns = append(s.columns, selectColumn{expr: c})
+	}
+}
+
+// ColumnQuery - add the scalar subquery as a column: (subquery) AS alias. See Sub for the supported subqueries
+func (s *SelectBuilder) ColumnQuery(query any, alias string) *SelectBuilder {
+	s.setErr(checkExpressions("column alias", []string{alias}))
+	s.columns = append(s.columns, selectColumn{query: query, alias: alias})
+	return s
+}
+
+// FromQuery - FROM (subquery) alias. See Sub for the supported subqueries
+func (s *SelectBuilder) FromQuery(query any, alias string) *SelectBuilder {
+	s.From(alias)
+	s.fromQuery = query
+	return s
+}
+
+// Distinct - SELECT DISTINCT
+func (s *SelectBuilder) Distinct() *SelectBuilder {
+	s.distinct = true
+	return s
+}
+
+// DistinctOn - SELECT DISTINCT ON (exprs). PostgreSql requires the ORDER BY to start with the same expressions
+// (in any order), this is checked by Template
+func (s *SelectBuilder) DistinctOn(exprs ...string) *SelectBuilder {
+	if len(exprs) == 0 {
+		s.setErr(nerr.New("empty DISTINCT ON"))
+	}
+	s.setErr(checkExpressions("DISTINCT ON expression", exprs))
+	s.distinctOn = append(s.distinctOn, exprs...)
+	return s
+}
+
+// Columns - add columns
+func (s *SelectBuilder) Columns(columns ...string) *SelectBuilder {
+	s.addColumns(columns)
+	return s
+}
+
+// From - FROM clause: table name with an optional alias ("users u") or any table expression
+func (s *SelectBuilder) From(from string) *SelectBuilder {
+	s.setErr(checkExpressions("FROM", []string{from}))
+	s.from = from
+	s.fromQuery = nil
+
+	if len(s.fromAlias) > 0 {
+		delete(s.aliases, aliasKey(s.fromAlias))
+	}
+	s.fromAlias = tableAlias(from)
+	s.addAlias(s.fromAlias)
+
+	return s
+}
+
+// Where - add the condition as is, it may contain :variables. Conditions are combined by AND
+func (s *SelectBuilder) Where(cond string) *SelectBuilder {
+	s.setErr(checkExpressions("condition", []string{cond}))
+	s.where = append(s.where, Expr(cond))
+	return s
+}
+
+// WhereCond - add the condition (see Cond). Conditions are combined by AND
+func (s *SelectBuilder) WhereCond(c Cond) *SelectBuilder {
+	if c == nil {
+		s.setErr(nerr.New("nil condition"))
+		return s
+	}
+
+	s.where = append(s.where, c)
+	return s
+}
+
+// GroupBy - add grouping expressions
+func (s *SelectBuilder) GroupBy(exprs ...string) *SelectBuilder {
+	s.setErr(checkExpressions("grouping expression", exprs))
+	s.groupBy = append(s.groupBy, exprs...)
+	return s
+}
+
+// Having - add the condition for groups as is, it may contain :variables. Conditions are combined by AND
+func (s *SelectBuilder) Having(cond string) *SelectBuilder {
+	s.setErr(checkExpressions("condition", []string{cond}))
+	s.having = append(s.having, Expr(cond))
+	return s
+}
+
+// HavingCond - add the condition for groups (see Cond). Conditions are combined by AND
+func (s *SelectBuilder) HavingCond(c Cond) *SelectBuilder {
+	if c == nil {
+		s.setErr(nerr.New("nil condition"))
+		return s
+	}
+
+	s.having = append(s.having, c)
+	return s
+}
+
+// OrderBy - add sort expressions, e.g. "created_at DESC"
+func (s *SelectBuilder) OrderBy(exprs ...string) *SelectBuilder {
+	s.setErr(checkExpressions("sort expression", exprs))
+	s.orderBy = append(s.orderBy, exprs...)
+	return s
+}
+
+// Limit - LIMIT clause
+func (s *SelectBuilder) Limit(n int) *SelectBuilder {
+	if n < 0 {
+		s.setErr(nerr.New("negative limit"))
+	}
+	s.limit = n
+	return s
+}
+
+// Offset - OFFSET clause
+func (s *SelectBuilder) Offset(n int) *SelectBuilder {
+	if n < 0 {
+		s.setErr(nerr.New("negative offset"))
+	}
+	s.offset = n
+	return s
+}
+
+// IncludeDeleted - don't exclude soft deleted rows. See SetSoftDelete
+func (s *SelectBuilder) IncludeDeleted() *SelectBuilder {
+	s.includeDeleted = true
+	return s
+}
+
+// Placeholders - emit values as generated variables :sqlb_1, :sqlb_2, ... instead of inline literals,
+// including the values of the builders used as subqueries. The values are returned by TemplateParams and bound by Binder and Q
+func (s *SelectBuilder) Placeholders() *SelectBuilder {
+	s.placeholders = true
+	return s
+}
+
+func (s *SelectBuilder) usesPlaceholders() bool {
+	return s.placeholders
+}
+
+// Err - the first error that occurred
+func (s *SelectBuilder) Err() error {
+	return s.err
+}
+
+// Validate - check the query and, if the schema is registered by SetSchema, check it against the schema. See Schema.Validate
+func (s *SelectBuilder) Validate() error {
+	if _, err := s.Template(); err != nil {
+		return err
+	}
+
+	return validateSchema(s)
+}
+
+// Template - sql template of the query
+func (s *SelectBuilder) Template() (string, error) {
+	return s.template(newCondRenderer(s.placeholders))
+}
+
+// TemplateParams - sql template of the query and the values of the generated variables (see Placeholders)
+func (s *SelectBuilder) TemplateParams() (string, map[string]any, error) {
+	r := newCondRenderer(s.placeholders)
+	sql, err := s.template(r)
+	return sql, r.params, err
+}
+
+func (s *SelectBuilder) template(r *condRenderer) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	with, err := s.withToSql(r)
+	if err != nil {
+		return "", err
+	}
+
+	var sql strings.Builder
+	sql.WriteString(with)
+	sql.WriteString("SELECT ")
+	if s.distinct {
+		sql.WriteString("DISTINCT ")
+	}
+	if len(s.distinctOn) > 0 {
+		if err := s.checkDistinctOn(); err != nil {
+			return "", err
+		}
+		sql.WriteString("DISTINCT ON (" + strings.Join(s.distinctOn, ", ") + ") ")
+	}
+
+	if len(s.columns) == 0 {
+		sql.WriteString("*")
+	}
+	for i, c := range s.columns {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+
+		if c.query == nil {
+			sql.WriteString(c.expr)
+			continue
+		}
+
+		query, err := r.subquery(c.query)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("column %s: %v", c.alias, err))
+		}
+		sql.WriteString("(" + query + ") AS " + c.alias)
+	}
+
+	if len(s.from) > 0 {
+		sql.WriteString(" FROM ")
+		if s.fromQuery != nil {
+			query, err := r.subquery(s.fromQuery)
+			if err != nil {
+				return "", nerr.New(fmt.Sprintf("FROM %s: %v", s.from, err))
+			}
+			sql.WriteString("(" + query + ") ")
+		}
+		sql.WriteString(s.from)
+	}
+
+	for _, join := range s.joins {
+		sql.WriteString(" ")
+		sql.WriteString(s.joinSql(join))
+	}
+
+	if conds := s.whereConds(); len(conds) > 0 {
+		where, err := conditionsToSql(conds, r)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" WHERE ")
+		sql.WriteString(where)
+	}
+
+	if len(s.groupBy) > 0 {
+		sql.WriteString(" GROUP BY ")
+		sql.WriteString(strings.Join(s.groupBy, ", "))
+	}
+
+	if len(s.having) > 0 {
+		having, err := conditionsToSql(s.having, r)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" HAVING ")
+		sql.WriteString(having)
+	}
+
+	if len(s.orderBy) > 0 {
+		sql.WriteString(" ORDER BY ")
+		sql.WriteString(strings.Join(s.orderBy, ", "))
+	}
+
+	if s.limit >= 0 {
+		sql.WriteString(" LIMIT ")
+		sql.WriteString(strconv.Itoa(s.limit))
+	}
+
+	if s.offset >= 0 {
+		sql.WriteString(" OFFSET ")
+		sql.WriteString(strconv.Itoa(s.offset))
+	}
+
+	if len(s.locks) > 0 {
+		if s.distinct || len(s.distinctOn) > 0 || len(s.groupBy) > 0 || len(s.having) > 0 {
+			return "", nerr.New("locking clause is not allowed with DISTINCT, GROUP BY or HAVING")
+		}
+		sql.WriteString(" ")
+		sql.WriteString(strings.Join(s.locks, " "))
+	}
+
+	if err := r.embedded.check(sql.String()); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// checkDistinctOn - the leftmost ORDER BY expressions must match the DISTINCT ON expressions
+func (s *SelectBuilder) checkDistinctOn() error {
+	if s.distinct {
+		return nerr.New("both DISTINCT and DISTINCT ON")
+	}
+
+	pending := make(map[string]bool, len(s.distinctOn))
+	for _, e := range s.distinctOn {
+		pending[normalizeExpr(e)] = true
+	}
+
+	for _, o := range s.orderBy {
+		for _, item := range splitTopLevel(o) {
+			if len(pending) == 0 {
+				return nil
+			}
+
+			key := normalizeExpr(sortExpr(item))
+			if !pending[key] {
+				return nerr.New(fmt.Sprintf("DISTINCT ON expressions must match the leftmost ORDER BY expressions, got %s", strings.TrimSpace(item)))
+			}
+			delete(pending, key)
+		}
+	}
+
+	return nil
+}
+
+// sortExpr - sort expression without ASC, DESC and NULLS FIRST|LAST
+func sortExpr(item string) string {
+	fields := strings.Fields(item)
+	if n := len(fields); n > 2 && strings.EqualFold(fields[n-2], "NULLS") {
+		fields = fields[:n-2]
+	}
+	if n := len(fields); n > 1 && (strings.EqualFold(fields[n-1], "ASC") || strings.EqualFold(fields[n-1], "DESC")) {
+		fields = fields[:n-1]
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// normalizeExpr - expression for comparison: whitespace is collapsed, unquoted text is case insensitive
+func normalizeExpr(expr string) string {
+	expr = strings.Join(strings.Fields(expr), " ")
+	if strings.Contains(expr, `"`) {
+		return expr
+	}
+
+	return strings.ToLower(expr)
+}
+
+// splitTopLevel - split the list by commas outside parentheses and quotes
+func splitTopLevel(list string) []string {
+	var (
+		res   []string
+		depth int
+		quote rune
+		start int
+	)
+	for i, c := range list {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			res = append(res, list[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(res, list[start:])
+}
+
+// whereConds - WHERE conditions including the soft deletion conditions of the FROM table and CROSS JOIN tables
+func (s *SelectBuilder) whereConds() []Cond {
+	conds := s.where[:len(s.where):len(s.where)]
+
+	if s.fromQuery == nil {
+		if column := s.softDeleteColumn(s.from); len(column) > 0 {
+			if len(s.fromAlias) > 0 {
+				column = s.fromAlias + "." + column
+			}
+			conds = append(conds, IsNull(column))
+		}
+	}
+
+	for _, j := range s.joins {
+		if j.kind != "CROSS JOIN" {
+			continue
+		}
+		if column := s.joinSoftDeleteColumn(j); len(column) > 0 {
+			conds = append(conds, IsNull(column))
+		}
+	}
+
+	return conds
+}
+
+// softDeleteColumn - column with the deletion time of the table, if soft deletion is on for the query.
+// Tables shadowed by CTE are not soft deleted
+func (s *SelectBuilder) softDeleteColumn(table string) string {
+	if s.includeDeleted || len(strings.TrimSpace(table)) == 0 {
+		return ""
+	}
+
+	if s.isCte(table) {
+		return ""
+	}
+
+	return softDeleteColumn(table)
+}
+
+// isCte - the table expression refers to a CTE of the query
+func (s *SelectBuilder) isCte(table string) bool {
+	fields := strings.Fields(table)
+	if len(fields) == 0 {
+		return false
+	}
+
+	for _, c := range s.ctes {
+		if aliasKey(c.name) == aliasKey(fields[0]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String - sql template of the query or the error text
+func (s *SelectBuilder) String() string {
+	sql, err := s.Template()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+
+	return sql
+}
+
+// Binder - binder for the query template. Use WithAutoKey or WithCacheKey to cache the parsing result
+func (s *SelectBuilder) Binder(opts ...BinderOption) *SqlBinder {
+	q := s
+	if sql, err := s.Template(); err == nil && needTenantCond(sql, opts) {
+		q = s.withTenant()
+	}
+
+	sql, params, err := q.TemplateParams()
+	return paramsBinder(sql, params, err, opts)
+}
+
+// Q - chainable Query for the query template
+func (s *SelectBuilder) Q(opts ...BinderOption) *Query {
+	return &Query{binder: s.Binder(opts...)}
+}
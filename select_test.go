@@ -0,0 +1,99 @@
+package sqlb
+
+import "testing"
+
+func TestSelectBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *SelectBuilder
+		result  string
+	}{
+		{"all", Select().From("users"), "SELECT * FROM users"},
+		{"no from", Select("1"), "SELECT 1"},
+		{"full", Select("id", "name").Distinct().From("users u").Where("u.age > :age").Where("u.name LIKE :name").
+			OrderBy("name", "id DESC").Limit(10).Offset(20),
+			"SELECT DISTINCT id, name FROM users u WHERE (u.age > :age) AND (u.name LIKE :name) ORDER BY name, id DESC LIMIT 10 OFFSET 20"},
+		{"single where", Select("id").Columns("name").From("users").Where("id = :id"), "SELECT id, name FROM users WHERE id = :id"},
+	}
+
+	for _, test := range tests {
+		sql, err := test.builder.Template()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if sql != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, sql, test.result)
+		}
+	}
+}
+
+func TestSelectBuilder_Binder(t *testing.T) {
+	sql, err := Select("id").From("users").Where("id = :id").Q(WithAutoKey()).Set("id", 5).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT id FROM users WHERE id = 5"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	b := Select("id").From("users").Where(" ").Limit(1).Binder()
+	if b.Err() == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := b.Sql(); err == nil {
+		t.Fatal("expected error from Sql")
+	}
+}
+
+func TestSelectBuilder_GroupBy(t *testing.T) {
+	sql, err := Select("user_id", "count(*)").From("orders").Where("status = :status").
+		GroupBy("user_id").HavingCond(Gt("count(*)", 5)).Having("sum(total) > :total").OrderBy("user_id").
+		Q().Set("status", "paid").Set("total", 1000).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := "SELECT user_id, count(*) FROM orders WHERE status = E'paid' GROUP BY user_id " +
+		"HAVING count(*) > 5 AND (sum(total) > 1000) ORDER BY user_id"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestSelectBuilder_DistinctOn(t *testing.T) {
+	sql, err := Select("user_id", "created_at").From("events").DistinctOn("user_id", "Kind").
+		OrderBy("kind, user_id ASC", "created_at DESC NULLS LAST").Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT DISTINCT ON (user_id, Kind) user_id, created_at FROM events ORDER BY kind, user_id ASC, created_at DESC NULLS LAST"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Select("*").From("events").DistinctOn("user_id").Template(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Select("*").From("events").DistinctOn("user_id").OrderBy("created_at", "user_id").Template(); err == nil {
+		t.Fatal("expected error for ORDER BY mismatch")
+	}
+	if _, err := Select("*").From("events").Distinct().DistinctOn("user_id").Template(); err == nil {
+		t.Fatal("expected error for DISTINCT and DISTINCT ON")
+	}
+}
+
+func TestSelectBuilder_When(t *testing.T) {
+	build := func(name string, withOrders bool) string {
+		return Select("u.id").From("users u").
+			When(name != "", func(b *SelectBuilder) { b.WhereCond(Eq("u.name", name)) }).
+			When(withOrders, func(b *SelectBuilder) { b.Join("orders o", "o.user_id = u.id").Columns("o.id") }).
+			String()
+	}
+
+	if sql, req := build("", false), "SELECT u.id FROM users u"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if sql, req := build("bob", true), "SELECT u.id, o.id FROM users u JOIN orders o ON o.user_id = u.id WHERE u.name = E'bob'"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
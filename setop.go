@@ -0,0 +1,232 @@
+package sqlb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// SetOpBuilder - combination of queries by UNION, INTERSECT and EXCEPT. Queries are SelectBuilder, Template
+// or any other query supported by Sub, each one is enclosed in parentheses. Operators are applied from left to right:
+// Union(a, b).Intersect(c) is (a UNION b) INTERSECT c. ORDER BY, LIMIT and OFFSET apply to the combined result.
+// A variable can't be used in several queries, unless they are wrapped by Sub(...).Shared() or Sub(...).Prefix
+type SetOpBuilder struct {
+	parts   []setOpPart
+	orderBy []string
+	// Отрицательное значение - не задано
+	limit  int
+	offset int
+	// Режим подстановки переменных вместо значений
+	placeholders bool
+	err          error
+}
+
+// setOpPart - query and the operator, which joins it to the previous ones
+type setOpPart struct {
+	op    string
+	query any
+}
+
+// Union - queries combined by UNION
+func Union(queries ...any) *SetOpBuilder {
+	return newSetOp().add("UNION", queries)
+}
+
+// UnionAll - queries combined by UNION ALL
+func UnionAll(queries ...any) *SetOpBuilder {
+	return newSetOp().add("UNION ALL", queries)
+}
+
+// Intersect - queries combined by INTERSECT
+func Intersect(queries ...any) *SetOpBuilder {
+	return newSetOp().add("INTERSECT", queries)
+}
+
+// Except - queries combined by EXCEPT
+func Except(queries ...any) *SetOpBuilder {
+	return newSetOp().add("EXCEPT", queries)
+}
+
+func newSetOp() *SetOpBuilder {
+	return &SetOpBuilder{
+		limit:  -1,
+		offset: -1,
+	}
+}
+
+// setErr - save the first error
+func (s *SetOpBuilder) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// When - call fn with the builder, if cond is true, so optional parts can be added without breaking the chain
+func (s *SetOpBuilder) When(cond bool, fn func(b *SetOpBuilder)) *SetOpBuilder {
+	if cond && fn != nil {
+		fn(s)
+	}
+
+	return s
+}
+
+func (s *SetOpBuilder) add(op string, queries []any) *SetOpBuilder {
+	for _, q := range queries {
+		if q == nil {
+			s.setErr(nerr.New("nil query"))
+			continue
+		}
+		s.parts = append(s.parts, setOpPart{op: op, query: q})
+	}
+
+	return s
+}
+
+// Union - add queries by UNION
+func (s *SetOpBuilder) Union(queries ...any) *SetOpBuilder {
+	return s.add("UNION", queries)
+}
+
+// UnionAll - add queries by UNION ALL
+func (s *SetOpBuilder) UnionAll(queries ...any) *SetOpBuilder {
+	return s.add("UNION ALL", queries)
+}
+
+// Intersect - add queries by INTERSECT
+func (s *SetOpBuilder) Intersect(queries ...any) *SetOpBuilder {
+	return s.add("INTERSECT", queries)
+}
+
+// Except - add queries by EXCEPT
+func (s *SetOpBuilder) Except(queries ...any) *SetOpBuilder {
+	return s.add("EXCEPT", queries)
+}
+
+// OrderBy - add sort expressions of the combined result
+func (s *SetOpBuilder) OrderBy(exprs ...string) *SetOpBuilder {
+	s.setErr(checkExpressions("sort expression", exprs))
+	s.orderBy = append(s.orderBy, exprs...)
+	return s
+}
+
+// Limit - LIMIT of the combined result
+func (s *SetOpBuilder) Limit(n int) *SetOpBuilder {
+	if n < 0 {
+		s.setErr(nerr.New("negative limit"))
+	}
+	s.limit = n
+	return s
+}
+
+// Offset - OFFSET of the combined result
+func (s *SetOpBuilder) Offset(n int) *SetOpBuilder {
+	if n < 0 {
+		s.setErr(nerr.New("negative offset"))
+	}
+	s.offset = n
+	return s
+}
+
+// Placeholders - emit values of the builder queries as generated variables :sqlb_1, :sqlb_2, ... instead of inline literals.
+// The values are returned by TemplateParams and bound by Binder and Q
+func (s *SetOpBuilder) Placeholders() *SetOpBuilder {
+	s.placeholders = true
+	return s
+}
+
+func (s *SetOpBuilder) usesPlaceholders() bool {
+	return s.placeholders
+}
+
+// Err - the first error that occurred
+func (s *SetOpBuilder) Err() error {
+	return s.err
+}
+
+// Template - sql template of the query
+func (s *SetOpBuilder) Template() (string, error) {
+	return s.template(newCondRenderer(s.placeholders))
+}
+
+// TemplateParams - sql template of the query and the values of the generated variables (see Placeholders)
+func (s *SetOpBuilder) TemplateParams() (string, map[string]any, error) {
+	r := newCondRenderer(s.placeholders)
+	sql, err := s.template(r)
+	return sql, r.params, err
+}
+
+func (s *SetOpBuilder) template(r *condRenderer) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	if len(s.parts) < 2 {
+		return "", nerr.New("at least two queries are required")
+	}
+
+	var (
+		sql string
+		// В левой части есть операторы с меньшим приоритетом, чем INTERSECT
+		lowerOps bool
+	)
+	for i, p := range s.parts {
+		query, err := r.subquery(p.query)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("query %d: %v", i, err))
+		}
+
+		if i == 0 {
+			sql = "(" + query + ")"
+			continue
+		}
+
+		if p.op == "INTERSECT" {
+			if lowerOps {
+				sql = "(" + sql + ")"
+				lowerOps = false
+			}
+		} else {
+			lowerOps = true
+		}
+		sql += " " + p.op + " (" + query + ")"
+	}
+
+	if len(s.orderBy) > 0 {
+		sql += " ORDER BY " + strings.Join(s.orderBy, ", ")
+	}
+	if s.limit >= 0 {
+		sql += " LIMIT " + strconv.Itoa(s.limit)
+	}
+	if s.offset >= 0 {
+		sql += " OFFSET " + strconv.Itoa(s.offset)
+	}
+
+	if err := r.embedded.check(sql); err != nil {
+		return "", err
+	}
+
+	return sql, nil
+}
+
+// String - sql template of the query or the error text
+func (s *SetOpBuilder) String() string {
+	sql, err := s.Template()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+
+	return sql
+}
+
+// Binder - binder for the query template
+func (s *SetOpBuilder) Binder(opts ...BinderOption) *SqlBinder {
+	sql, params, err := s.TemplateParams()
+	return paramsBinder(sql, params, err, opts)
+}
+
+// Q - chainable Query for the query template
+func (s *SetOpBuilder) Q(opts ...BinderOption) *Query {
+	return &Query{binder: s.Binder(opts...)}
+}
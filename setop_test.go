@@ -0,0 +1,66 @@
+package sqlb
+
+import "testing"
+
+func TestSetOpBuilder(t *testing.T) {
+	q := UnionAll(
+		Select("id").From("users").Where("active = :active").OrderBy("id").Limit(5),
+		"SELECT id FROM admins",
+	).Intersect(Select("id").From("allowed")).OrderBy("id DESC").Limit(10).Offset(20)
+
+	sql, err := q.Binder().MustBind("active", true).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "((SELECT id FROM users WHERE active = true ORDER BY id LIMIT 5) UNION ALL (SELECT id FROM admins)) " +
+		"INTERSECT (SELECT id FROM allowed) ORDER BY id DESC LIMIT 10 OFFSET 20"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Except("SELECT 1", "SELECT 2").Union("SELECT 3").Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "(SELECT 1) EXCEPT (SELECT 2) UNION (SELECT 3)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Union("SELECT 1").Template(); err == nil {
+		t.Fatal("expected error for one query")
+	}
+	if _, err := Union("SELECT :a", "SELECT :a").Template(); err == nil {
+		t.Fatal("expected error for variable in several queries")
+	}
+	if _, err := Union(Sub("SELECT :a").Shared(), Sub("SELECT :a").Shared()).Template(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetOpBuilder_Placeholders(t *testing.T) {
+	q := Union(
+		Select("id").From("users").WhereCond(Eq("role", "admin")),
+		Select("id").From("guests").WhereCond(Eq("role", "guest")).Where("active = :active"),
+	).Placeholders()
+
+	sql, params, err := q.TemplateParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "(SELECT id FROM users WHERE role = :sqlb_1) UNION (SELECT id FROM guests WHERE role = :sqlb_2 AND (active = :active))"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if params["sqlb_1"] != "admin" || params["sqlb_2"] != "guest" || len(params) != 2 {
+		t.Fatalf("unexpected params: %v", params)
+	}
+
+	sql, err = q.Binder().MustBind("active", true).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = "(SELECT id FROM users WHERE role = E'admin') UNION (SELECT id FROM guests WHERE role = E'guest' AND (active = true))"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
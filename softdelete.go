@@ -0,0 +1,53 @@
+package sqlb
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultSoftDeleteColumn - column with the deletion time, if the column is not specified in SetSoftDelete
+const DefaultSoftDeleteColumn = "deleted_at"
+
+var softDeleteTables atomic.Pointer[map[string]string]
+
+// SetSoftDelete - register tables with soft deletion: table -> column with the deletion time (empty - DefaultSoftDeleteColumn).
+// For these tables SELECT (the FROM table and joined tables, for joins the condition is added to ON), UPDATE
+// and DELETE builders add the condition "column IS NULL" and DELETE is rendered as UPDATE table SET column = NOW(). IncludeDeleted of the builder disables this.
+// Table names are the names stored in the database, see Schema. nil disables soft deletion
+func SetSoftDelete(tables map[string]string) {
+	if tables == nil {
+		softDeleteTables.Store(nil)
+		return
+	}
+
+	m := make(map[string]string, len(tables))
+	for table, column := range tables {
+		if len(column) == 0 {
+			column = DefaultSoftDeleteColumn
+		}
+		m[table] = column
+	}
+
+	softDeleteTables.Store(&m)
+}
+
+// softDeleteColumn - column with the deletion time for the table expression ("users u") or an empty string
+func softDeleteColumn(table string) string {
+	tables := softDeleteTables.Load()
+	if tables == nil {
+		return ""
+	}
+
+	fields := strings.Fields(table)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	for t, column := range *tables {
+		if sameTable(t, fields[0]) {
+			return column
+		}
+	}
+
+	return ""
+}
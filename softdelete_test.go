@@ -0,0 +1,48 @@
+package sqlb
+
+import "testing"
+
+func TestSetSoftDelete(t *testing.T) {
+	SetSoftDelete(map[string]string{"public.users": "", "orders": "removed_at"})
+	defer SetSoftDelete(nil)
+
+	tests := []struct {
+		b   interface{ Template() (string, error) }
+		req string
+	}{
+		{Select("id").From("users u").Where("u.id = :id"), "SELECT id FROM users u WHERE (u.id = :id) AND u.deleted_at IS NULL"},
+		{Select("id").From("users").IncludeDeleted(), "SELECT id FROM users"},
+		{
+			Select("u.id").From("users u").LeftJoin("orders o", "o.user_id = u.id").Join("items i", "i.id = o.item_id"),
+			"SELECT u.id FROM users u LEFT JOIN orders o ON (o.user_id = u.id) AND o.removed_at IS NULL " +
+				"JOIN items i ON i.id = o.item_id WHERE u.deleted_at IS NULL",
+		},
+		{
+			Select("o.id").From("orders o").CrossJoin("public.users"),
+			"SELECT o.id FROM orders o CROSS JOIN public.users WHERE o.removed_at IS NULL AND users.deleted_at IS NULL",
+		},
+		{
+			Select("u.id").From("users u").Join("orders o", "o.user_id = u.id").IncludeDeleted(),
+			"SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id",
+		},
+		{Select("id").From("items"), "SELECT id FROM items"},
+		{Select("*").With("orders", "SELECT 1").From("orders"), "WITH orders AS (SELECT 1) SELECT * FROM orders"},
+		{Update("orders").Set("total", 1), "UPDATE orders SET total = 1 WHERE removed_at IS NULL"},
+		{Delete("users").WhereCond(Eq("id", 1)), "UPDATE users SET deleted_at = NOW() WHERE id = 1 AND deleted_at IS NULL"},
+		{Delete("users").WhereCond(Eq("id", 1)).IncludeDeleted(), "DELETE FROM users WHERE id = 1"},
+	}
+
+	for _, test := range tests {
+		sql, err := test.b.Template()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != test.req {
+			t.Errorf("%s, wants: %s", sql, test.req)
+		}
+	}
+
+	if _, err := Delete("users").Template(); err == nil {
+		t.Fatal("expected error for DELETE without WHERE")
+	}
+}
@@ -0,0 +1,19 @@
+module github.com/n-r-w/sqlb/sqlbotel
+
+go 1.20
+
+require (
+	github.com/n-r-w/sqlb v0.1.0
+	go.opentelemetry.io/otel v1.19.0
+	go.opentelemetry.io/otel/sdk v1.19.0
+	go.opentelemetry.io/otel/trace v1.19.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/n-r-w/nerr v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.19.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
@@ -0,0 +1,117 @@
+// Package sqlbotel - OpenTelemetry tracing of sqlb queries
+package sqlbotel
+
+import (
+	"context"
+
+	"github.com/n-r-w/sqlb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName - name of the tracer
+const instrumentationName = "github.com/n-r-w/sqlb"
+
+// Span attributes
+const (
+	// TemplateKeyAttr - key of the template (cache key or hash of the template)
+	TemplateKeyAttr = attribute.Key("sqlb.template_key")
+	// RowsAttr - number of the returned or affected rows
+	RowsAttr = attribute.Key("sqlb.rows")
+	// OperationAttr - type of the statement: SELECT, INSERT etc.
+	OperationAttr = attribute.Key("db.operation")
+	// SystemAttr - database system
+	SystemAttr = attribute.Key("db.system")
+	// StatementAttr - template of the query, see WithStatement
+	StatementAttr = attribute.Key("db.statement")
+)
+
+// Option - tracer option
+type Option func(t *Tracer)
+
+// WithTracerProvider - tracer provider instead of the global one
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.provider = tp
+	}
+}
+
+// WithStatement - add the query template to the span attributes. Values are not included, because only the template is traced
+func WithStatement() Option {
+	return func(t *Tracer) {
+		t.statement = true
+	}
+}
+
+// Tracer - sqlb.Tracer, which creates OpenTelemetry spans
+type Tracer struct {
+	provider  trace.TracerProvider
+	tracer    trace.Tracer
+	statement bool
+}
+
+// NewTracer - create Tracer
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(t)
+		}
+	}
+
+	if t.provider == nil {
+		t.provider = otel.GetTracerProvider()
+	}
+	t.tracer = t.provider.Tracer(instrumentationName)
+
+	return t
+}
+
+// Install - trace all calculations and executions of the package sqlb. See sqlb.SetTracer
+func Install(opts ...Option) {
+	sqlb.SetTracer(NewTracer(opts...))
+}
+
+// Start - implementation of sqlb.Tracer
+func (t *Tracer) Start(ctx context.Context, info sqlb.SpanInfo) (context.Context, sqlb.SpanEnd) {
+	attrs := []attribute.KeyValue{
+		SystemAttr.String("postgresql"),
+		TemplateKeyAttr.String(info.Key),
+	}
+	if len(info.Statement) > 0 {
+		attrs = append(attrs, OperationAttr.String(info.Statement))
+	}
+	if t.statement {
+		attrs = append(attrs, StatementAttr.String(info.Template))
+	}
+
+	kind := trace.SpanKindInternal
+	if info.Operation == sqlb.SpanExecute {
+		kind = trace.SpanKindClient
+	}
+
+	ctx, span := t.tracer.Start(ctx, spanName(info), trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+
+	return ctx, func(rows int64, err error) {
+		if rows >= 0 {
+			span.SetAttributes(RowsAttr.Int64(rows))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// spanName - name of the span: sqlb.execute SELECT
+func spanName(info sqlb.SpanInfo) string {
+	name := "sqlb." + info.Operation
+	if len(info.Statement) > 0 {
+		name += " " + info.Statement
+	}
+
+	return name
+}
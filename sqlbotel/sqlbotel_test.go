@@ -0,0 +1,58 @@
+package sqlbotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	Install(WithTracerProvider(tp))
+	defer sqlb.SetTracer(nil)
+
+	ctx := context.Background()
+	b := sqlb.NewBinder("SELECT * FROM t WHERE id = :id", sqlb.WithCacheKey("users.by_id"))
+	if _, err := b.MustBind("id", 1).SqlContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	_, end := sqlb.StartSpan(ctx, sqlb.SpanInfo{Operation: sqlb.SpanExecute, Template: "DELETE FROM t"})
+	end(3, errors.New("failed"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of spans: %d", len(spans))
+	}
+
+	calc := spans[0]
+	if calc.Name != "sqlb.calculate SELECT" {
+		t.Fatalf("unexpected name: %s", calc.Name)
+	}
+	if attrs := attrMap(calc); attrs[TemplateKeyAttr] != "users.by_id" || attrs[OperationAttr] != "SELECT" {
+		t.Fatalf("unexpected attributes: %v", attrs)
+	}
+
+	exec := spans[1]
+	if exec.Name != "sqlb.execute DELETE" || exec.Status.Code != codes.Error {
+		t.Fatalf("unexpected span: %s %v", exec.Name, exec.Status)
+	}
+	if attrs := attrMap(exec); attrs[RowsAttr] != "3" || len(attrs[TemplateKeyAttr]) == 0 {
+		t.Fatalf("unexpected attributes: %v", attrs)
+	}
+}
+
+func attrMap(s tracetest.SpanStub) map[any]string {
+	res := map[any]string{}
+	for _, a := range s.Attributes {
+		res[a.Key] = a.Value.Emit()
+	}
+
+	return res
+}
@@ -0,0 +1,17 @@
+module github.com/n-r-w/sqlb/sqlbpgx
+
+go 1.20
+
+require (
+	github.com/jackc/pgx/v5 v5.5.5
+	github.com/n-r-w/nerr v1.1.0
+	github.com/n-r-w/sqlb v0.1.0
+)
+
+require (
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
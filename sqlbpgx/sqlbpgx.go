@@ -0,0 +1,200 @@
+// Package sqlbpgx - execution of sqlb queries with pgx v5
+package sqlbpgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/nerr"
+	"github.com/n-r-w/sqlb"
+)
+
+// DB - *pgx.Conn, *pgxpool.Pool or pgx.Tx
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Exec - execute the statement of the binder (template or builder)
+func Exec(ctx context.Context, db DB, b *sqlb.SqlBinder) (pgconn.CommandTag, error) {
+	sql, err := b.SqlContext(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	return exec(ctx, db, binderSpan(b), sql)
+}
+
+// Query - run the query of the binder (template or builder)
+func Query(ctx context.Context, db DB, b *sqlb.SqlBinder) (pgx.Rows, error) {
+	sql, err := b.SqlContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return query(ctx, db, binderSpan(b), sql)
+}
+
+// QueryRow - run the query of the binder, which returns a single row. The error of the binder is returned by Scan
+func QueryRow(ctx context.Context, db DB, b *sqlb.SqlBinder) pgx.Row {
+	sql, err := b.SqlContext(ctx)
+	if err != nil {
+		return errRow{err}
+	}
+
+	ctx, end := sqlb.StartSpan(ctx, binderSpan(b))
+	row := db.QueryRow(ctx, sql)
+	end(-1, nil)
+
+	return row
+}
+
+// NamedArgs - template with the variables :name replaced by pgx named arguments @name and the arguments for them.
+// Values are converted via sqlb.DriverValue, so Value with Json, TimeFormat and NullZero options can be used
+func NamedArgs(template string, values map[string]any) (string, pgx.NamedArgs, error) {
+	p := sqlb.NewParser(template)
+	if err := p.Parse(); err != nil {
+		return "", nil, err
+	}
+
+	named := make(map[string]any, len(values))
+	for variable, value := range values {
+		named[variableName(variable)] = value
+	}
+
+	params := map[string]string{}
+	args := pgx.NamedArgs{}
+	for _, v := range p.ParcedVariables() {
+		if _, ok := params[v]; ok {
+			continue
+		}
+
+		value, ok := named[v]
+		if !ok {
+			return "", nil, nerr.New(fmt.Sprintf("bind value not found for: %s", v))
+		}
+
+		arg, err := sqlb.DriverValue(value)
+		if err != nil {
+			return "", nil, nerr.New(fmt.Sprintf("%s: %v", v, err))
+		}
+
+		args[v[1:]] = arg
+		params[v] = "@" + v[1:]
+	}
+
+	sql, err := p.Calculate(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sql, args, nil
+}
+
+// ExecNamed - execute the template with the values passed as pgx named arguments. See NamedArgs
+func ExecNamed(ctx context.Context, db DB, template string, values map[string]any) (pgconn.CommandTag, error) {
+	sql, args, err := NamedArgs(template, values)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	return exec(ctx, db, namedSpan(template, sql), sql, args)
+}
+
+// QueryNamed - run the template with the values passed as pgx named arguments. See NamedArgs
+func QueryNamed(ctx context.Context, db DB, template string, values map[string]any) (pgx.Rows, error) {
+	sql, args, err := NamedArgs(template, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return query(ctx, db, namedSpan(template, sql), sql, args)
+}
+
+// Queue - add the query of the binder to the batch
+func Queue(batch *pgx.Batch, b *sqlb.SqlBinder) (*pgx.QueuedQuery, error) {
+	sql, err := b.Sql()
+	if err != nil {
+		return nil, err
+	}
+
+	return batch.Queue(sql), nil
+}
+
+// QueueNamed - add the template with the values passed as pgx named arguments to the batch. See NamedArgs
+func QueueNamed(batch *pgx.Batch, template string, values map[string]any) (*pgx.QueuedQuery, error) {
+	sql, args, err := NamedArgs(template, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return batch.Queue(sql, args), nil
+}
+
+// exec - execute the statement in the span of the tracer and the logger (see sqlb.StartSpan)
+func exec(ctx context.Context, db DB, span sqlb.SpanInfo, sql string, args ...any) (pgconn.CommandTag, error) {
+	ctx, end := sqlb.StartSpan(ctx, span)
+	tag, err := db.Exec(ctx, sql, args...)
+	if err != nil {
+		end(-1, err)
+	} else {
+		end(tag.RowsAffected(), nil)
+	}
+
+	return tag, err
+}
+
+// query - run the query in the span of the tracer and the logger (see sqlb.StartSpan)
+func query(ctx context.Context, db DB, span sqlb.SpanInfo, sql string, args ...any) (pgx.Rows, error) {
+	ctx, end := sqlb.StartSpan(ctx, span)
+	rows, err := db.Query(ctx, sql, args...)
+	end(-1, err)
+
+	return rows, err
+}
+
+// binderSpan - span of the execution of the binder query
+func binderSpan(b *sqlb.SqlBinder) sqlb.SpanInfo {
+	info := sqlb.SpanInfo{
+		Operation: sqlb.SpanExecute,
+		Template:  b.SqlTemplate(),
+		Key:       b.CacheKey(),
+	}
+	if sqlb.Instrumented() {
+		// значения не попадают в журнал, если они отмечены как конфиденциальные
+		info.Sql, _ = b.SqlRedacted()
+	}
+
+	return info
+}
+
+// namedSpan - span of the execution of the template with the named arguments
+func namedSpan(template string, sql string) sqlb.SpanInfo {
+	return sqlb.SpanInfo{
+		Operation: sqlb.SpanExecute,
+		Template:  template,
+		Sql:       sql,
+	}
+}
+
+// errRow - row, which returns the error
+type errRow struct {
+	err error
+}
+
+// Scan - returns the error
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// variableName - variable name with leading ':'
+func variableName(variable string) string {
+	if len(variable) > 0 && variable[0] == ':' {
+		return variable
+	}
+
+	return ":" + variable
+}
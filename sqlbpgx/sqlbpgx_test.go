@@ -0,0 +1,49 @@
+package sqlbpgx
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/n-r-w/sqlb"
+)
+
+func TestNamedArgs(t *testing.T) {
+	sql, args, err := NamedArgs("SELECT * FROM t WHERE id = :id AND data @> :data::jsonb OR parent = :id",
+		map[string]any{"id": 1, "data": sqlb.V([]int{1}, sqlb.Json())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t WHERE id = @id AND data @> @data::jsonb OR parent = @id"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if len(args) != 2 || args["id"] != 1 || args["data"] != "[1]" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	if _, _, err := NamedArgs("SELECT :a", nil); err == nil {
+		t.Fatal("expected error for missing value")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	batch := &pgx.Batch{}
+	if _, err := Queue(batch, sqlb.Delete("t").WhereCond(sqlb.Eq("id", 1)).Binder()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := QueueNamed(batch, "DELETE FROM t WHERE id = :id", map[string]any{"id": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Queue(batch, sqlb.NewBinder("DELETE FROM t WHERE id = :id")); err == nil {
+		t.Fatal("expected error for unbound variable")
+	}
+
+	if len(batch.QueuedQueries) != 2 {
+		t.Fatalf("unexpected batch size: %d", len(batch.QueuedQueries))
+	}
+	if sql, req := batch.QueuedQueries[0].SQL, "DELETE FROM t WHERE id = 1"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+	if sql, req := batch.QueuedQueries[1].SQL, "DELETE FROM t WHERE id = @id"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
@@ -0,0 +1,78 @@
+package sqlbtest
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+)
+
+// UpdateFlag - flag to rewrite the golden files instead of comparing: go test ./... -sqlbtest.update.
+// The name is namespaced, so packages with their own -update flag can import sqlbtest. If the tested package
+// defines a boolean -update flag itself, it is honored as well
+const UpdateFlag = "sqlbtest.update"
+
+// UpdateEnv - environment variable to rewrite the golden files, for runs where flags can't be passed: SQLBTEST_UPDATE=1 go test ./...
+const UpdateEnv = "SQLBTEST_UPDATE"
+
+var update = flag.Bool(UpdateFlag, false, "rewrite sqlbtest golden files")
+
+// updateGolden - the golden files must be rewritten
+func updateGolden() bool {
+	if *update {
+		return true
+	}
+
+	// флаг -update тестируемого пакета
+	if f := flag.Lookup("update"); f != nil {
+		if v, err := strconv.ParseBool(f.Value.String()); err == nil && v {
+			return true
+		}
+	}
+
+	v, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return v
+}
+
+// AssertSQL - compare the calculated query of the binder with the golden file. Whitespace is normalized before the comparison,
+// so the golden file can be formatted for readability. Run the tests with -sqlbtest.update (or -update of the tested package,
+// or SQLBTEST_UPDATE=1) to create or rewrite the golden files
+func AssertSQL(t testing.TB, b *sqlb.SqlBinder, goldenPath string) {
+	t.Helper()
+
+	sql, err := b.Sql()
+	if err != nil {
+		t.Fatalf("%s: %v", goldenPath, err)
+		return
+	}
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("%s: %v", goldenPath, err)
+			return
+		}
+		if err := os.WriteFile(goldenPath, []byte(sql+"\n"), 0o644); err != nil {
+			t.Fatalf("%s: %v", goldenPath, err)
+		}
+		return
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("%s: golden file not found, run the test with -%s", goldenPath, UpdateFlag)
+		return
+	}
+	if err != nil {
+		t.Fatalf("%s: %v", goldenPath, err)
+		return
+	}
+
+	if normalizeSpace(sql) != normalizeSpace(string(golden)) {
+		t.Errorf("%s: %s, wants: %s", goldenPath, normalizeSpace(sql), normalizeSpace(string(golden)))
+	}
+}
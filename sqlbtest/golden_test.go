@@ -0,0 +1,76 @@
+package sqlbtest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+)
+
+// fakeT - testing.TB, which records the failures
+type fakeT struct {
+	testing.TB
+	failed string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func TestAssertSQL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "users.sql")
+	b := func() *sqlb.SqlBinder {
+		return sqlb.Select("id", "name").From("users").WhereCond(sqlb.Eq("id", 1)).Binder()
+	}
+
+	ft := &fakeT{TB: t}
+	AssertSQL(ft, b(), path)
+	if len(ft.failed) == 0 {
+		t.Fatal("expected failure for missing golden file")
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	AssertSQL(t, b(), path)
+	t.Setenv(UpdateEnv, "")
+
+	if err := os.WriteFile(path, []byte("SELECT id, name\n  FROM users\n  WHERE id = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	AssertSQL(t, b(), path)
+
+	ft = &fakeT{TB: t}
+	AssertSQL(ft, sqlb.Select("id").From("users").Binder(), path)
+	if len(ft.failed) == 0 {
+		t.Fatal("expected failure for different query")
+	}
+}
+
+func TestAssertSQL_UpdateFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.sql")
+	b := sqlb.Select("id").From("users").Binder()
+
+	if err := flag.Set(UpdateFlag, "true"); err != nil {
+		t.Fatal(err)
+	}
+	AssertSQL(t, b, path)
+	if err := flag.Set(UpdateFlag, "false"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := string(data), "SELECT id FROM users\n"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
@@ -0,0 +1,392 @@
+// Package sqlbtest - helpers for testing of sqlb queries without a database
+package sqlbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/n-r-w/nerr"
+	"github.com/n-r-w/sqlb"
+)
+
+// Call - query executed through the Mock
+type Call struct {
+	// Ключ шаблона: ключ кэширования или хэш шаблона. Пустой, если запрос выполнен не через sqlbx или sqlbpgx
+	Key string
+	// Шаблон запроса, если известен
+	Template string
+	// Выполненный запрос
+	Sql string
+	// Аргументы, преобразованные database/sql (int в int64 и т.д.)
+	Args []any
+}
+
+// Mock - database/sql backend, which records the executed queries and checks the expectations.
+// Without expectations every query succeeds with an empty result. With expectations a query, which doesn't match
+// any of the unmet expectations, returns an error. Safe for concurrent use
+type Mock struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	calls    []Call
+	expected []*Expectation
+}
+
+// NewMock - create Mock
+func NewMock() *Mock {
+	m := &Mock{}
+	m.db = sql.OpenDB(mockConnector{m})
+
+	return m
+}
+
+// DB - database, which executes the queries through the mock
+func (m *Mock) DB() *sql.DB {
+	return m.db
+}
+
+// Calls - executed queries in the order of execution
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// ExpectSql - expect the query. Whitespace is normalized before the comparison
+func (m *Mock) ExpectSql(sql string) *Expectation {
+	return m.expect(&Expectation{sql: normalizeSpace(sql)})
+}
+
+// ExpectKey - expect the query of the template with the key (see Call)
+func (m *Mock) ExpectKey(key string) *Expectation {
+	return m.expect(&Expectation{key: key})
+}
+
+// ExpectationsWereMet - error if some of the expectations are not met
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unmet []string
+	for _, e := range m.expected {
+		if !e.met {
+			unmet = append(unmet, e.String())
+		}
+	}
+
+	if len(unmet) > 0 {
+		return nerr.New(fmt.Sprintf("unmet expectations: %s", strings.Join(unmet, "; ")))
+	}
+
+	return nil
+}
+
+// AssertExpectations - fail the test if some of the expectations are not met
+func (m *Mock) AssertExpectations(t testing.TB) {
+	t.Helper()
+
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// expect - add the expectation
+func (m *Mock) expect(e *Expectation) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expected = append(m.expected, e)
+
+	return e
+}
+
+// record - record the query and find the expectation. nil expectation without an error means an empty result
+func (m *Mock) record(ctx context.Context, query string, args []driver.NamedValue) (*Expectation, error) {
+	call := Call{Sql: query}
+	for _, a := range args {
+		call.Args = append(call.Args, a.Value)
+	}
+	if info, ok := sqlb.SpanFromContext(ctx); ok {
+		call.Template = info.Template
+		call.Key = info.Key
+		if len(call.Key) == 0 && len(call.Template) > 0 {
+			call.Key = sqlb.TemplateKey(call.Template)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, call)
+
+	if len(m.expected) == 0 {
+		return nil, nil
+	}
+
+	for _, e := range m.expected {
+		if !e.met && e.match(call) {
+			e.met = true
+			return e, e.err
+		}
+	}
+
+	return nil, nerr.New(fmt.Sprintf("unexpected query: %s", query))
+}
+
+// Expectation - expected query and its result
+type Expectation struct {
+	sql string
+	key string
+
+	args    []any
+	hasArgs bool
+
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+	err          error
+
+	met bool
+}
+
+// WithArgs - expected arguments of the query in the positional parameters mode. Values are compared after conversion by database/sql
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.hasArgs = true
+	e.args = make([]any, len(args))
+	for i, a := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(a)
+		if err != nil {
+			v = a
+		}
+		e.args[i] = v
+	}
+
+	return e
+}
+
+// WillReturnRows - rows returned by the query
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]any) *Expectation {
+	e.columns = columns
+	e.rows = make([][]driver.Value, len(rows))
+
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			e.err = nerr.New(fmt.Sprintf("row %d: %d values for %d columns", i, len(row), len(columns)))
+			return e
+		}
+
+		e.rows[i] = make([]driver.Value, len(row))
+		for j, v := range row {
+			var err error
+			if e.rows[i][j], err = driver.DefaultParameterConverter.ConvertValue(v); err != nil {
+				e.err = nerr.New(fmt.Sprintf("row %d, column %s: %v", i, columns[j], err))
+				return e
+			}
+		}
+	}
+
+	return e
+}
+
+// WillReturnResult - number of the rows affected by the statement
+func (e *Expectation) WillReturnResult(rowsAffected int64) *Expectation {
+	e.rowsAffected = rowsAffected
+	return e
+}
+
+// WillReturnError - error of the query
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// String - description of the expectation
+func (e *Expectation) String() string {
+	if len(e.key) > 0 {
+		return "key " + e.key
+	}
+
+	return e.sql
+}
+
+// match - does the call match the expectation
+func (e *Expectation) match(c Call) bool {
+	if len(e.key) > 0 && e.key != c.Key {
+		return false
+	}
+	if len(e.sql) > 0 && e.sql != normalizeSpace(c.Sql) {
+		return false
+	}
+	if e.hasArgs && !reflect.DeepEqual(e.args, append([]any{}, c.Args...)) {
+		return false
+	}
+
+	return true
+}
+
+// normalizeSpace - sequences of whitespace are replaced with a single space
+func normalizeSpace(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// mockConnector - connector of the Mock
+type mockConnector struct {
+	m *Mock
+}
+
+// Connect - implementation of driver.Connector
+func (c mockConnector) Connect(context.Context) (driver.Conn, error) {
+	return mockConn(c), nil
+}
+
+// Driver - implementation of driver.Connector
+func (c mockConnector) Driver() driver.Driver {
+	return c
+}
+
+// Open - implementation of driver.Driver
+func (c mockConnector) Open(string) (driver.Conn, error) {
+	return mockConn(c), nil
+}
+
+// mockConn - connection of the Mock
+type mockConn struct {
+	m *Mock
+}
+
+// Prepare - implementation of driver.Conn
+func (c mockConn) Prepare(query string) (driver.Stmt, error) {
+	return mockStmt{m: c.m, query: query}, nil
+}
+
+// Close - implementation of driver.Conn
+func (c mockConn) Close() error {
+	return nil
+}
+
+// Begin - implementation of driver.Conn
+func (c mockConn) Begin() (driver.Tx, error) {
+	return mockTx{}, nil
+}
+
+// QueryContext - implementation of driver.QueryerContext
+func (c mockConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return mockStmt{m: c.m, query: query}.QueryContext(ctx, args)
+}
+
+// ExecContext - implementation of driver.ExecerContext
+func (c mockConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return mockStmt{m: c.m, query: query}.ExecContext(ctx, args)
+}
+
+// mockTx - transaction of the Mock
+type mockTx struct{}
+
+// Commit - implementation of driver.Tx
+func (mockTx) Commit() error {
+	return nil
+}
+
+// Rollback - implementation of driver.Tx
+func (mockTx) Rollback() error {
+	return nil
+}
+
+// mockStmt - prepared statement of the Mock
+type mockStmt struct {
+	m     *Mock
+	query string
+}
+
+// Close - implementation of driver.Stmt
+func (s mockStmt) Close() error {
+	return nil
+}
+
+// NumInput - implementation of driver.Stmt
+func (s mockStmt) NumInput() int {
+	return -1
+}
+
+// Exec - implementation of driver.Stmt
+func (s mockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query - implementation of driver.Stmt
+func (s mockStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext - implementation of driver.StmtExecContext
+func (s mockStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	e, err := s.m.record(ctx, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return driver.RowsAffected(0), nil
+	}
+
+	return driver.RowsAffected(e.rowsAffected), nil
+}
+
+// QueryContext - implementation of driver.StmtQueryContext
+func (s mockStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := s.m.record(ctx, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return &mockRows{}, nil
+	}
+
+	return &mockRows{columns: e.columns, rows: e.rows}, nil
+}
+
+// namedValues - positional values as named values
+func namedValues(args []driver.Value) []driver.NamedValue {
+	res := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		res[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return res
+}
+
+// mockRows - rows of the expectation
+type mockRows struct {
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+// Columns - implementation of driver.Rows
+func (r *mockRows) Columns() []string {
+	return r.columns
+}
+
+// Close - implementation of driver.Rows
+func (r *mockRows) Close() error {
+	return nil
+}
+
+// Next - implementation of driver.Rows
+func (r *mockRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.pos])
+	r.pos++
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package sqlbtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+	"github.com/n-r-w/sqlb/sqlbx"
+)
+
+func TestMock(t *testing.T) {
+	m := NewMock()
+	ctx := context.Background()
+
+	m.ExpectKey("users.by_id").WillReturnRows([]string{"id", "name"}, []any{1, "bob"})
+	m.ExpectSql("DELETE FROM users   WHERE id = $1").WithArgs(1).WillReturnResult(1)
+	m.ExpectSql("DELETE FROM users WHERE id = 2").WillReturnError(errors.New("failed"))
+
+	var users []struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+	err := sqlbx.Select(ctx, m.DB(), "SELECT id, name FROM users WHERE id = :id", map[string]any{"id": 1}, &users,
+		sqlbx.WithBinderOptions(sqlb.WithCacheKey("users.by_id")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Name != "bob" {
+		t.Fatalf("unexpected users: %v", users)
+	}
+
+	res, err := sqlbx.Exec(ctx, m.DB(), "DELETE FROM users WHERE id = :id", map[string]any{"id": 1}, sqlbx.Parameterized())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("unexpected rows affected: %d", n)
+	}
+
+	if _, err := sqlbx.ExecBinder(ctx, m.DB(), sqlb.Delete("users").WhereCond(sqlb.Eq("id", 2)).Binder()); err == nil {
+		t.Fatal("expected error")
+	}
+	m.AssertExpectations(t)
+
+	if _, err := m.DB().ExecContext(ctx, "DELETE FROM t"); err == nil {
+		t.Fatal("expected error for unexpected query")
+	}
+
+	calls := m.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("unexpected number of calls: %d", len(calls))
+	}
+	if c := calls[1]; c.Key != sqlb.TemplateKey("DELETE FROM users WHERE id = :id") || c.Sql != "DELETE FROM users WHERE id = $1" || c.Args[0] != int64(1) {
+		t.Fatalf("unexpected call: %+v", c)
+	}
+	if c := calls[3]; len(c.Key) != 0 || c.Sql != "DELETE FROM t" {
+		t.Fatalf("unexpected call: %+v", c)
+	}
+
+	m.ExpectSql("SELECT 1")
+	if err := m.ExpectationsWereMet(); err == nil {
+		t.Fatal("expected error for unmet expectation")
+	}
+}
@@ -0,0 +1,37 @@
+package sqlbx
+
+import (
+	"context"
+
+	"github.com/n-r-w/nerr"
+	"github.com/n-r-w/sqlb"
+)
+
+// ExplainAnalyze - run EXPLAIN (ANALYZE, FORMAT JSON) for the query of the binder and parse the plan.
+// Only read queries are allowed (see sqlb.SqlBinder.ExplainSql), because ANALYZE executes the statement
+func ExplainAnalyze(ctx context.Context, db DB, b *sqlb.SqlBinder) (*sqlb.ExplainPlan, error) {
+	sql, err := b.ExplainSql(true, "JSON")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nerr.New("explain returned no rows")
+	}
+
+	var data []byte
+	if err := rows.Scan(&data); err != nil {
+		return nil, err
+	}
+
+	return sqlb.ParseExplain(data)
+}
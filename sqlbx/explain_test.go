@@ -0,0 +1,38 @@
+package sqlbx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+)
+
+func TestExplainAnalyze(t *testing.T) {
+	rec := &recorder{values: []driver.Value{
+		[]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "t", "Actual Rows": 2}, "Planning Time": 0.1, "Execution Time": 0.2}]`),
+	}}
+	sql.Register("sqlbx_explain", rec)
+	db, err := sql.Open("sqlbx_explain", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	plan, err := ExplainAnalyze(ctx, db, sqlb.Select("*").From("t").WhereCond(sqlb.Eq("id", 1)).Binder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.HasNode("Seq Scan") || plan.Rows() != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if req := "EXPLAIN (ANALYZE, FORMAT JSON) SELECT * FROM t WHERE id = 1"; rec.queries[0] != req {
+		t.Fatalf("%s, wants: %s", rec.queries[0], req)
+	}
+
+	if _, err := ExplainAnalyze(ctx, db, sqlb.Delete("t").Binder()); err == nil {
+		t.Fatal("expected error for data modification")
+	}
+}
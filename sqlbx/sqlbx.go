@@ -0,0 +1,214 @@
+// Package sqlbx - execution of sqlb queries with database/sql
+package sqlbx
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/n-r-w/sqlb"
+)
+
+// DB - *sql.DB, *sql.Tx or *sql.Conn
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Option - execution option
+type Option func(o *options)
+
+// options - execution settings
+type options struct {
+	// Передача значений параметрами $1, $2... вместо подстановки в запрос
+	parameterized bool
+	// Опции создания SqlBinder
+	binderOpts []sqlb.BinderOption
+}
+
+// Parameterized - send the values as positional parameters $1, $2... instead of substituting them into the query.
+// See SqlBinder.Positional
+func Parameterized() Option {
+	return func(o *options) {
+		o.parameterized = true
+	}
+}
+
+// WithBinderOptions - options of the SqlBinder creation (cache key, comment, tenant etc.), also used by Parameterized
+func WithBinderOptions(opts ...sqlb.BinderOption) Option {
+	return func(o *options) {
+		o.binderOpts = append(o.binderOpts, opts...)
+	}
+}
+
+// Query - bind the values to the template and run the query
+func Query(ctx context.Context, db DB, template string, values map[string]any, opts ...Option) (*sql.Rows, error) {
+	st, err := build(ctx, template, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return query(ctx, db, st)
+}
+
+// Exec - bind the values to the template and execute the statement
+func Exec(ctx context.Context, db DB, template string, values map[string]any, opts ...Option) (sql.Result, error) {
+	st, err := build(ctx, template, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return exec(ctx, db, st)
+}
+
+// Select - run the query and scan all rows into dest, a pointer to a slice of structs or single column values. See sqlb.ScanRows
+func Select(ctx context.Context, db DB, template string, values map[string]any, dest any, opts ...Option) error {
+	st, err := build(ctx, template, values, opts)
+	if err != nil {
+		return err
+	}
+
+	return selectRows(ctx, db, st, dest)
+}
+
+// QueryBinder - run the query of the binder, e.g. of a builder: QueryBinder(ctx, db, sqlb.Select("id").From("t").Binder())
+func QueryBinder(ctx context.Context, db DB, b *sqlb.SqlBinder) (*sql.Rows, error) {
+	st, err := binderStatement(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return query(ctx, db, st)
+}
+
+// ExecBinder - execute the statement of the binder. See QueryBinder
+func ExecBinder(ctx context.Context, db DB, b *sqlb.SqlBinder) (sql.Result, error) {
+	st, err := binderStatement(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return exec(ctx, db, st)
+}
+
+// statement - query to run
+type statement struct {
+	template string
+	// Ключ кэширования шаблона, если задан
+	key  string
+	sql  string
+	args []any
+	// Запрос для журнала, см. sqlb.SpanInfo
+	logSql string
+}
+
+// span - span of the execution
+func (s statement) span() sqlb.SpanInfo {
+	return sqlb.SpanInfo{
+		Operation: sqlb.SpanExecute,
+		Template:  s.template,
+		Key:       s.key,
+		Sql:       s.logSql,
+	}
+}
+
+// query - run the query in the span of the tracer and the logger (see sqlb.StartSpan)
+func query(ctx context.Context, db DB, st statement) (*sql.Rows, error) {
+	ctx, end := sqlb.StartSpan(ctx, st.span())
+	rows, err := db.QueryContext(ctx, st.sql, st.args...)
+	end(-1, err)
+
+	return rows, err
+}
+
+// exec - execute the statement in the span of the tracer and the logger (see sqlb.StartSpan)
+func exec(ctx context.Context, db DB, st statement) (sql.Result, error) {
+	ctx, end := sqlb.StartSpan(ctx, st.span())
+	res, err := db.ExecContext(ctx, st.sql, st.args...)
+	end(rowsAffected(res, err), err)
+
+	return res, err
+}
+
+// selectRows - run the query and scan the rows in the span of the tracer and the logger (see sqlb.StartSpan)
+func selectRows(ctx context.Context, db DB, st statement, dest any) error {
+	ctx, end := sqlb.StartSpan(ctx, st.span())
+
+	rows, err := db.QueryContext(ctx, st.sql, st.args...)
+	if err == nil {
+		err = sqlb.ScanRows(rows, dest)
+	}
+	end(sliceLen(dest), err)
+
+	return err
+}
+
+// rowsAffected - number of the affected rows or -1
+func rowsAffected(res sql.Result, err error) int64 {
+	if err != nil {
+		return -1
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// sliceLen - length of the slice by the pointer or -1
+func sliceLen(dest any) int64 {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return -1
+	}
+
+	return int64(v.Elem().Len())
+}
+
+// build - statement of the template with the values
+func build(ctx context.Context, template string, values map[string]any, opts []Option) (statement, error) {
+	o := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	b := sqlb.NewBinder(template, o.binderOpts...)
+
+	if o.parameterized {
+		sql, args, err := b.Positional(values)
+		if err != nil {
+			return statement{}, err
+		}
+		return statement{template: b.SqlTemplate(), key: b.CacheKey(), sql: sql, args: args, logSql: sql}, nil
+	}
+
+	if err := b.BindValues(values); err != nil {
+		return statement{}, err
+	}
+
+	return binderStatement(ctx, b)
+}
+
+// binderStatement - statement of the binder
+func binderStatement(ctx context.Context, b *sqlb.SqlBinder) (statement, error) {
+	sql, err := b.SqlContext(ctx)
+	if err != nil {
+		return statement{}, err
+	}
+
+	st := statement{
+		template: b.SqlTemplate(),
+		key:      b.CacheKey(),
+		sql:      sql,
+	}
+	if sqlb.Instrumented() {
+		// значения не попадают в журнал, если они отмечены как конфиденциальные
+		st.logSql, _ = b.SqlRedacted()
+	}
+
+	return st, nil
+}
@@ -0,0 +1,170 @@
+package sqlbx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+)
+
+// recorder - driver, which records the executed queries
+type recorder struct {
+	prepared []string
+	closed   []string
+	queries  []string
+	args     [][]driver.Value
+	// Значения единственной колонки результата, по умолчанию 1 и 2
+	values []driver.Value
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return &conn{r}, nil }
+
+type conn struct{ r *recorder }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	c.r.prepared = append(c.r.prepared, query)
+	return &stmt{c.r, query}, nil
+}
+func (c *conn) Close() error              { return nil }
+func (c *conn) Begin() (driver.Tx, error) { return c, nil }
+func (c *conn) Commit() error             { return nil }
+func (c *conn) Rollback() error           { return nil }
+
+type stmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *stmt) Close() error {
+	s.r.closed = append(s.r.closed, s.query)
+	return nil
+}
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.r.queries = append(s.r.queries, s.query)
+	s.r.args = append(s.r.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.r.queries = append(s.r.queries, s.query)
+	s.r.args = append(s.r.args, args)
+	values := s.r.values
+	if values == nil {
+		values = []driver.Value{int64(1), int64(2)}
+	}
+	return &rows{values: values}, nil
+}
+
+type rows struct {
+	values []driver.Value
+	pos    int
+}
+
+func (r *rows) Columns() []string { return []string{"id"} }
+func (r *rows) Close() error      { return nil }
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos == len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.pos]
+	r.pos++
+	return nil
+}
+
+func TestExec(t *testing.T) {
+	rec := &recorder{}
+	sql.Register("sqlbx_recorder", rec)
+	db, err := sql.Open("sqlbx_recorder", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	values := map[string]any{"id": 1, "name": "bob"}
+
+	if _, err := Exec(ctx, db, "UPDATE t SET name = :name WHERE id = :id", values); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Exec(ctx, db, "UPDATE t SET name = :name WHERE id = :id", values, Parameterized()); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := QueryBinder(ctx, db, sqlb.Select("id").From("t").WhereCond(sqlb.Eq("id", 2)).Binder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	req := []string{
+		"UPDATE t SET name = E'bob' WHERE id = 1",
+		"UPDATE t SET name = $1 WHERE id = $2",
+		"SELECT id FROM t WHERE id = 2",
+	}
+	if !reflect.DeepEqual(rec.queries, req) {
+		t.Fatalf("%v, wants: %v", rec.queries, req)
+	}
+	if args := rec.args[1]; len(args) != 2 || args[0] != "bob" || args[1] != int64(1) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	if _, err := Query(ctx, db, "SELECT :id", nil); err == nil {
+		t.Fatal("expected error for missing value")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	sql.Register("sqlbx_select", &recorder{})
+	db, err := sql.Open("sqlbx_select", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var dest []struct {
+		ID int `db:"id"`
+	}
+	if err := Select(context.Background(), db, "SELECT id FROM t WHERE id > :id", map[string]any{"id": 0}, &dest); err != nil {
+		t.Fatal(err)
+	}
+	if len(dest) != 2 || dest[0].ID != 1 || dest[1].ID != 2 {
+		t.Fatalf("unexpected rows: %v", dest)
+	}
+}
+
+func TestParameterized_Tenant(t *testing.T) {
+	rec := &recorder{}
+	sql.Register("sqlbx_tenant", rec)
+	db, err := sql.Open("sqlbx_tenant", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sqlb.RequireTenant(true)
+	defer sqlb.RequireTenant(false)
+
+	ctx := context.Background()
+	values := map[string]any{"id": 1}
+
+	if _, err := Exec(ctx, db, "DELETE FROM t WHERE id = :id", values, Parameterized()); err == nil {
+		t.Fatal("expected error without tenant")
+	}
+
+	_, err = Exec(ctx, db, "DELETE FROM t WHERE tenant_id = :tenant_id AND id = :id", values,
+		Parameterized(), WithBinderOptions(sqlb.WithTenant(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := []string{"DELETE FROM t WHERE tenant_id = $1 AND id = $2"}; !reflect.DeepEqual(rec.queries, req) {
+		t.Fatalf("%v, wants: %v", rec.queries, req)
+	}
+	if args := rec.args[0]; len(args) != 2 || args[0] != int64(7) || args[1] != int64(1) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
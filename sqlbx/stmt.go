@@ -0,0 +1,268 @@
+package sqlbx
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/n-r-w/sqlb"
+)
+
+// Preparer - *sql.DB or *sql.Conn
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// DefaultStmtCacheSize - default maximum number of prepared statements in StmtCache
+const DefaultStmtCacheSize = 1000
+
+// StmtCache - prepared statements of the templates in the positional parameters mode (see sqlb.SqlBinder.Positional).
+// The binder options are passed by WithBinderOptions of each call. Each query is prepared once, database/sql prepares the statement on each connection of the pool on first use
+// and reuses it afterwards. The number of statements is limited (see SetSize), the least recently used statements
+// are closed. Safe for concurrent use
+type StmtCache struct {
+	db Preparer
+
+	mu sync.Mutex
+	// Максимальное количество запросов, <= 0 - без ограничений
+	maxEntries int
+	// Список запросов, в начале - последние использованные
+	ll *list.List
+	// Ключ - sqlb.TemplateKey запроса с параметрами $1, $2...
+	items map[string]*list.Element
+}
+
+// stmtEntry - prepared statement in the cache
+type stmtEntry struct {
+	key  string
+	stmt *sql.Stmt
+	// Количество выполняемых в данный момент запросов
+	refs int
+	// Запрос вытеснен из кэша, он закрывается после завершения выполняемых запросов
+	evicted bool
+}
+
+// NewStmtCache - create StmtCache with the DefaultStmtCacheSize limit
+func NewStmtCache(db Preparer) *StmtCache {
+	return &StmtCache{
+		db:         db,
+		maxEntries: DefaultStmtCacheSize,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// SetSize - maximum number of the prepared statements. n <= 0 means no limit
+func (c *StmtCache) SetSize(n int) {
+	c.mu.Lock()
+	c.maxEntries = n
+	closing := c.evict()
+	c.mu.Unlock()
+
+	closeStmts(closing)
+}
+
+// Exec - execute the prepared statement of the template
+func (c *StmtCache) Exec(ctx context.Context, template string, values map[string]any, opts ...Option) (sql.Result, error) {
+	e, st, err := c.prepare(ctx, template, values, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(e)
+
+	return exec(ctx, stmtDB{e.stmt}, st)
+}
+
+// Query - run the prepared statement of the template
+func (c *StmtCache) Query(ctx context.Context, template string, values map[string]any, opts ...Option) (*sql.Rows, error) {
+	e, st, err := c.prepare(ctx, template, values, opts)
+	if err != nil {
+		return nil, err
+	}
+	// database/sql закрывает запрос только после закрытия полученных из него rows
+	defer c.release(e)
+
+	return query(ctx, stmtDB{e.stmt}, st)
+}
+
+// Select - run the prepared statement of the template and scan all rows into dest. See sqlb.ScanRows
+func (c *StmtCache) Select(ctx context.Context, template string, values map[string]any, dest any, opts ...Option) error {
+	e, st, err := c.prepare(ctx, template, values, opts)
+	if err != nil {
+		return err
+	}
+	defer c.release(e)
+
+	return selectRows(ctx, stmtDB{e.stmt}, st, dest)
+}
+
+// Stmt - prepared statement of the template and its arguments. Use tx.StmtContext to run it in a transaction.
+// The cache doesn't close the statement until PreparedStmt.Close is called, even if it is evicted
+func (c *StmtCache) Stmt(ctx context.Context, template string, values map[string]any, opts ...Option) (*PreparedStmt, error) {
+	e, st, err := c.prepare(ctx, template, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedStmt{Stmt: e.stmt, Args: st.args, cache: c, entry: e}, nil
+}
+
+// PreparedStmt - prepared statement of StmtCache with the arguments. Must be closed after use
+type PreparedStmt struct {
+	Stmt *sql.Stmt
+	Args []any
+
+	cache *StmtCache
+	entry *stmtEntry
+	once  sync.Once
+}
+
+// Close - the statement is not used anymore, the cache may close it. Doesn't close the statement itself,
+// it stays in the cache. Repeated calls do nothing
+func (p *PreparedStmt) Close() error {
+	p.once.Do(func() {
+		p.cache.release(p.entry)
+	})
+
+	return nil
+}
+
+// Len - number of the prepared statements
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// Close - close all prepared statements. The cache can be used further, statements will be prepared again
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	var closing []*sql.Stmt
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		if stmt := c.markEvicted(e.Value.(*stmtEntry)); stmt != nil {
+			closing = append(closing, stmt)
+		}
+	}
+	c.ll.Init()
+	c.items = map[string]*list.Element{}
+	c.mu.Unlock()
+
+	return closeStmts(closing)
+}
+
+// prepare - prepared statement from the cache or a new one. The statement must be released by release
+func (c *StmtCache) prepare(ctx context.Context, template string, values map[string]any, opts []Option) (*stmtEntry, statement, error) {
+	st, err := build(ctx, template, values, append(opts[:len(opts):len(opts)], Parameterized()))
+	if err != nil {
+		return nil, statement{}, err
+	}
+	query := st.sql
+
+	// ключ по тексту запроса: опции (например WithComment) могут изменить его для одного и того же шаблона
+	key := sqlb.TemplateKey(query)
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		entry := e.Value.(*stmtEntry)
+		entry.refs++
+		c.mu.Unlock()
+		return entry, st, nil
+	}
+	c.mu.Unlock()
+
+	// подготовка вне блокировки, чтобы не задерживать другие шаблоны
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, statement{}, err
+	}
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		// шаблон уже подготовлен параллельно
+		c.ll.MoveToFront(e)
+		entry := e.Value.(*stmtEntry)
+		entry.refs++
+		c.mu.Unlock()
+		_ = stmt.Close()
+		return entry, st, nil
+	}
+	entry := &stmtEntry{key: key, stmt: stmt, refs: 1}
+	c.items[key] = c.ll.PushFront(entry)
+	closing := c.evict()
+	c.mu.Unlock()
+
+	closeStmts(closing)
+
+	return entry, st, nil
+}
+
+// release - the statement is not used by the caller anymore. The evicted statement is closed after the last use
+func (c *StmtCache) release(entry *stmtEntry) {
+	c.mu.Lock()
+	entry.refs--
+	closing := entry.evicted && entry.refs == 0
+	c.mu.Unlock()
+
+	if closing {
+		_ = entry.stmt.Close()
+	}
+}
+
+// evict - remove the least recently used statements over the limit. Returns the statements to close,
+// they are closed by the caller without the lock. The caller must hold the lock
+func (c *StmtCache) evict() []*sql.Stmt {
+	var closing []*sql.Stmt
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		entry := e.Value.(*stmtEntry)
+		delete(c.items, entry.key)
+
+		if stmt := c.markEvicted(entry); stmt != nil {
+			closing = append(closing, stmt)
+		}
+	}
+
+	return closing
+}
+
+// markEvicted - mark the statement as evicted. Returns the statement if it is not used and can be closed right away.
+// The caller must hold the lock
+func (c *StmtCache) markEvicted(entry *stmtEntry) *sql.Stmt {
+	entry.evicted = true
+	if entry.refs > 0 {
+		return nil
+	}
+
+	return entry.stmt
+}
+
+// closeStmts - close the statements, returns the first error
+func closeStmts(stmts []*sql.Stmt) error {
+	var res error
+	for _, stmt := range stmts {
+		if err := stmt.Close(); err != nil && res == nil {
+			res = err
+		}
+	}
+
+	return res
+}
+
+// stmtDB - prepared statement as DB, the query text is ignored
+type stmtDB struct {
+	stmt *sql.Stmt
+}
+
+// ExecContext - implementation of DB
+func (s stmtDB) ExecContext(ctx context.Context, _ string, args ...any) (sql.Result, error) {
+	return s.stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext - implementation of DB
+func (s stmtDB) QueryContext(ctx context.Context, _ string, args ...any) (*sql.Rows, error) {
+	return s.stmt.QueryContext(ctx, args...)
+}
@@ -0,0 +1,156 @@
+package sqlbx
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/n-r-w/sqlb"
+)
+
+func TestStmtCache(t *testing.T) {
+	rec := &recorder{}
+	sql.Register("sqlbx_stmt", rec)
+	db, err := sql.Open("sqlbx_stmt", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	c := NewStmtCache(db)
+	defer c.Close()
+
+	for i := 1; i <= 3; i++ {
+		if _, err := c.Exec(ctx, "DELETE FROM t WHERE id = :id", map[string]any{"id": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var ids []int
+	if err := c.Select(ctx, "SELECT id FROM t WHERE id > :id", map[string]any{"id": 0}, &ids); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Len() != 2 {
+		t.Fatalf("unexpected number of statements: %d", c.Len())
+	}
+	if req := []string{"DELETE FROM t WHERE id = $1", "SELECT id FROM t WHERE id > $1"}; !reflect.DeepEqual(rec.prepared, req) {
+		t.Fatalf("unexpected prepared statements: %v", rec.prepared)
+	}
+	if len(rec.args) != 4 || rec.args[2][0] != int64(3) {
+		t.Fatalf("unexpected args: %v", rec.args)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	if _, err := c.Exec(ctx, "DELETE FROM t WHERE id = :id", nil); err == nil {
+		t.Fatal("expected error for missing value")
+	}
+}
+
+func TestStmtCache_Evict(t *testing.T) {
+	rec := &recorder{}
+	sql.Register("sqlbx_stmt_evict", rec)
+	db, err := sql.Open("sqlbx_stmt_evict", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	c := NewStmtCache(db)
+	c.SetSize(2)
+
+	for _, template := range []string{"DELETE FROM a WHERE id = :id", "DELETE FROM b WHERE id = :id", "DELETE FROM a WHERE id = :id", "DELETE FROM c WHERE id = :id"} {
+		if _, err := c.Exec(ctx, template, map[string]any{"id": 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// вытеснен давно не использованный запрос b
+	if c.Len() != 2 {
+		t.Fatalf("unexpected number of statements: %d", c.Len())
+	}
+	if req := []string{"DELETE FROM b WHERE id = $1"}; !reflect.DeepEqual(rec.closed, req) {
+		t.Fatalf("unexpected closed statements: %v", rec.closed)
+	}
+
+	c.SetSize(1)
+	if c.Len() != 1 || len(rec.closed) != 2 || rec.closed[1] != "DELETE FROM a WHERE id = $1" {
+		t.Fatalf("unexpected closed statements: %v", rec.closed)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != 0 || len(rec.closed) != 3 {
+		t.Fatalf("unexpected closed statements: %v", rec.closed)
+	}
+}
+
+func TestStmtCache_Stmt(t *testing.T) {
+	rec := &recorder{}
+	sql.Register("sqlbx_stmt_handle", rec)
+	db, err := sql.Open("sqlbx_stmt_handle", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	c := NewStmtCache(db)
+	c.SetSize(1)
+
+	p, err := c.Stmt(ctx, "DELETE FROM a WHERE id = :id", map[string]any{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// вытесненный запрос не закрывается, пока он используется
+	if _, err := c.Exec(ctx, "DELETE FROM b WHERE id = :id", map[string]any{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.closed) != 0 {
+		t.Fatalf("statement in use is closed: %v", rec.closed)
+	}
+	if _, err := p.Stmt.ExecContext(ctx, p.Args...); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = p.Close()
+	_ = p.Close()
+	if req := []string{"DELETE FROM a WHERE id = $1"}; !reflect.DeepEqual(rec.closed, req) {
+		t.Fatalf("unexpected closed statements: %v", rec.closed)
+	}
+}
+
+func TestStmtCache_BinderOptions(t *testing.T) {
+	rec := &recorder{}
+	sql.Register("sqlbx_stmt_tenant", rec)
+	db, err := sql.Open("sqlbx_stmt_tenant", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sqlb.RequireTenant(true)
+	defer sqlb.RequireTenant(false)
+
+	ctx := context.Background()
+	c := NewStmtCache(db)
+	defer c.Close()
+
+	template := "DELETE FROM t WHERE tenant_id = :tenant_id AND id = :id"
+	if _, err := c.Exec(ctx, template, map[string]any{"id": 1, "tenant_id": 7}); err == nil {
+		t.Fatal("expected error without tenant")
+	}
+
+	if _, err := c.Exec(ctx, template, map[string]any{"id": 1}, WithBinderOptions(sqlb.WithTenant(7))); err != nil {
+		t.Fatal(err)
+	}
+	if args := rec.args[0]; len(args) != 2 || args[0] != int64(7) || args[1] != int64(1) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
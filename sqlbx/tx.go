@@ -0,0 +1,133 @@
+package sqlbx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy - retries of WithTx on serialization failure (40001) and deadlock (40P01)
+type RetryPolicy struct {
+	// Максимальное количество попыток, включая первую
+	Attempts int
+	// Задержка перед первым повтором, удваивается с каждой попыткой
+	Backoff time.Duration
+	// Максимальная задержка
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy - retry policy of WithTx, unless changed by SetRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   5,
+	Backoff:    10 * time.Millisecond,
+	MaxBackoff: time.Second,
+}
+
+var retryPolicy atomic.Pointer[RetryPolicy]
+
+// SetRetryPolicy - retry policy of WithTx. nil restores DefaultRetryPolicy.
+// Invalid values are normalized: at least one attempt, non-negative Backoff not greater than MaxBackoff
+func SetRetryPolicy(p *RetryPolicy) {
+	if p == nil {
+		retryPolicy.Store(nil)
+		return
+	}
+
+	c := p.normalize()
+	retryPolicy.Store(&c)
+}
+
+// currentRetryPolicy - retry policy set by SetRetryPolicy or the default one
+func currentRetryPolicy() RetryPolicy {
+	if p := retryPolicy.Load(); p != nil {
+		return *p
+	}
+
+	return DefaultRetryPolicy.normalize()
+}
+
+// normalize - policy with valid values. MaxBackoff <= 0 means no limit
+func (p RetryPolicy) normalize() RetryPolicy {
+	if p.Attempts < 1 {
+		p.Attempts = 1
+	}
+	if p.Backoff < 0 {
+		p.Backoff = 0
+	}
+	if p.MaxBackoff < 0 {
+		p.MaxBackoff = 0
+	}
+	if p.MaxBackoff > 0 && p.Backoff > p.MaxBackoff {
+		p.Backoff = p.MaxBackoff
+	}
+
+	return p
+}
+
+// WithTx - run fn in a transaction: commit if fn returns nil, otherwise roll back.
+// On serialization failure (40001) or deadlock (40P01) of fn or commit the whole transaction is repeated with backoff
+// (see SetRetryPolicy), so fn must not have side effects outside the transaction. The error code is taken from the driver error
+// with the method SQLState() string (pgx, lib/pq). A panic in fn rolls back the transaction and is propagated
+func WithTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	p := currentRetryPolicy()
+	backoff := p.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, opts, fn)
+		if err == nil || attempt >= p.Attempts || !IsRetryable(err) {
+			return err
+		}
+
+		// случайная составляющая, чтобы конфликтующие транзакции не повторялись одновременно
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff)+1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		if backoff < math.MaxInt64/2 {
+			backoff *= 2
+		}
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// IsRetryable - the error is a serialization failure (40001) or a deadlock (40P01)
+func IsRetryable(err error) bool {
+	var e interface{ SQLState() string }
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	code := e.SQLState()
+	return code == "40001" || code == "40P01"
+}
+
+// runTx - single attempt of the transaction
+func runTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
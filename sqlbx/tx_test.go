@@ -0,0 +1,92 @@
+package sqlbx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stateError - driver error with the sql state
+type stateError string
+
+func (e stateError) Error() string    { return "sql state " + string(e) }
+func (e stateError) SQLState() string { return string(e) }
+
+func TestWithTx(t *testing.T) {
+	sql.Register("sqlbx_tx", &recorder{})
+	db, err := sql.Open("sqlbx_tx", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	SetRetryPolicy(&RetryPolicy{Attempts: 3, Backoff: time.Millisecond})
+	defer SetRetryPolicy(nil)
+
+	ctx := context.Background()
+	calls := 0
+	err = WithTx(ctx, db, nil, func(tx *sql.Tx) error {
+		calls++
+		if calls < 3 {
+			return stateError("40001")
+		}
+		_, err := Exec(ctx, tx, "DELETE FROM t WHERE id = :id", map[string]any{"id": 1})
+		return err
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("unexpected result: %v, calls: %d", err, calls)
+	}
+
+	calls = 0
+	err = WithTx(ctx, db, nil, func(tx *sql.Tx) error {
+		calls++
+		return stateError("40P01")
+	})
+	if !IsRetryable(err) || calls != 3 {
+		t.Fatalf("unexpected result: %v, calls: %d", err, calls)
+	}
+
+	calls = 0
+	failed := errors.New("failed")
+	err = WithTx(ctx, db, nil, func(tx *sql.Tx) error {
+		calls++
+		return failed
+	})
+	if !errors.Is(err, failed) || calls != 1 {
+		t.Fatalf("unexpected result: %v, calls: %d", err, calls)
+	}
+}
+
+func TestRetryPolicy_Normalize(t *testing.T) {
+	SetRetryPolicy(&RetryPolicy{Attempts: 0, Backoff: -time.Second, MaxBackoff: -1})
+	defer SetRetryPolicy(nil)
+
+	if p := currentRetryPolicy(); p != (RetryPolicy{Attempts: 1}) {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+
+	SetRetryPolicy(&RetryPolicy{Attempts: 2, Backoff: time.Minute, MaxBackoff: time.Millisecond})
+	if p := currentRetryPolicy(); p.Backoff != time.Millisecond {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+
+	sql.Register("sqlbx_tx_normalize", &recorder{})
+	db, err := sql.Open("sqlbx_tx_normalize", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// нулевая задержка не приводит к панике
+	SetRetryPolicy(&RetryPolicy{Attempts: 2, Backoff: -1})
+	calls := 0
+	err = WithTx(context.Background(), db, nil, func(tx *sql.Tx) error {
+		calls++
+		return stateError("40001")
+	})
+	if !IsRetryable(err) || calls != 2 {
+		t.Fatalf("unexpected result: %v, calls: %d", err, calls)
+	}
+}
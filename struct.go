@@ -0,0 +1,122 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// BindStruct - bind all exported fields of the struct (or pointer to struct).
+// The variable name is the lowercase field name or the name from the tag db:"name".
+// Tag modifiers: json - serialize the field to json, nullzero - zero value is rendered as null.
+// db:"-" excludes the field. Fields of embedded structs are bound as if they were fields of the outer struct.
+// In strict mode fields absent from the template are skipped
+func (b *SqlBinder) BindStruct(s any) error {
+	v, err := structValue(s)
+	if err != nil {
+		return err
+	}
+
+	if b.strict {
+		if err := b.parcer.checkParsed(); err != nil {
+			return err
+		}
+	}
+
+	return b.bindStructValue(v)
+}
+
+func (b *SqlBinder) bindStructValue(v reflect.Value) error {
+	return walkStruct(v, func(name string, value any, opts []Option) error {
+		// в строгом режиме поля, отсутствующие в шаблоне, пропускаются
+		if b.strict {
+			if _, ok := b.parcer.parsedMap[":"+name]; !ok {
+				return nil
+			}
+		}
+
+		return b.Bind(name, value, opts...)
+	})
+}
+
+// structValue - struct from the struct or pointer to struct
+func structValue(s any) (reflect.Value, error) {
+	v := reflect.ValueOf(s)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, nerr.New("nil struct pointer")
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, nerr.New(fmt.Sprintf("struct expected, got %T", s))
+	}
+
+	return v, nil
+}
+
+// walkStruct - call fn for the exported fields of the struct according to the db tags (see BindStruct)
+func walkStruct(v reflect.Value, fn func(name string, value any, opts []Option) error) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := parseStructTag(tag)
+
+		if field.Anonymous && len(name) == 0 {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && len(opts) == 0 {
+				if err := walkStruct(fv, fn); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = strings.ToLower(field.Name)
+		}
+
+		if err := fn(name, v.Field(i).Interface(), opts); err != nil {
+			return nerr.New(fmt.Sprintf("field %s: %v", field.Name, err))
+		}
+	}
+
+	return nil
+}
+
+// parseStructTag - variable name and options from the db tag
+func parseStructTag(tag string) (string, []Option) {
+	parts := strings.Split(tag, ",")
+	name := strings.TrimSpace(parts[0])
+
+	var opts []Option
+	for _, p := range parts[1:] {
+		switch strings.TrimSpace(p) {
+		case "json":
+			opts = append(opts, Json())
+		case "nullzero":
+			opts = append(opts, NullZero())
+		}
+	}
+
+	return name, opts
+}
@@ -0,0 +1,38 @@
+package sqlb
+
+import "testing"
+
+func TestSqlBinder_BindStruct(t *testing.T) {
+	type Base struct {
+		ID int `db:"id"`
+	}
+
+	type User struct {
+		Base
+		Name    string
+		Email   string            `db:"mail,nullzero"`
+		Attrs   map[string]string `db:"attrs,json"`
+		Ignored string            `db:"-"`
+		hidden  string
+	}
+
+	binder := NewBinder("INSERT INTO users VALUES (:id, :name, :mail, :attrs)")
+	err := binder.BindStruct(&User{
+		Base:  Base{ID: 7},
+		Name:  "bob",
+		Attrs: map[string]string{"a": "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql, err := binder.Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := `INSERT INTO users VALUES (7, E'bob', null, E'{"a":"b"}')`
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
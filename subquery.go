@@ -0,0 +1,213 @@
+package sqlb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// SubQuery - subquery embedded into a builder. The variables of the subquery become variables of the outer query,
+// so a variable used both in the subquery and outside of it (or in another subquery) is reported as an error.
+// Use Prefix to rename the variables of the subquery or Shared to allow the common variables
+type SubQuery struct {
+	query  any
+	prefix string
+	shared bool
+}
+
+// Sub - subquery from a template string, a compiled *Template, a builder, or a *SqlBinder / *Query
+// with bound values (it is embedded already calculated)
+func Sub(query any) *SubQuery {
+	if q, ok := query.(*SubQuery); ok {
+		return q
+	}
+
+	return &SubQuery{query: query}
+}
+
+// Prefix - rename the variables of the subquery: :id -> :prefix_id
+func (q *SubQuery) Prefix(prefix string) *SubQuery {
+	q.prefix = prefix
+	return q
+}
+
+// Shared - the variables of the subquery may be used in the outer query, they get the same values
+func (q *SubQuery) Shared() *SubQuery {
+	q.shared = true
+	return q
+}
+
+// Template - sql template of the subquery with renamed variables
+func (q *SubQuery) Template() (string, error) {
+	sql, err := subquerySql(q.query)
+	if err != nil || len(q.prefix) == 0 {
+		return sql, err
+	}
+
+	for i := 0; i < len(q.prefix); i++ {
+		if !isAllnum(q.prefix[i]) {
+			return "", nerr.New(fmt.Sprintf("invalid variable prefix: %s", q.prefix))
+		}
+	}
+
+	p := NewParser(sql)
+	if err := p.Parse(); err != nil {
+		return "", err
+	}
+
+	var res strings.Builder
+	shift := 0
+	for _, d := range p.parsed {
+		res.WriteString(sql[shift:d.pos])
+		res.WriteString(":" + q.prefix + "_" + d.name[1:])
+		shift = d.pos + len(d.name)
+	}
+	res.WriteString(sql[shift:])
+
+	return res.String(), nil
+}
+
+// embeddedVars - variables brought into the query by subqueries
+type embeddedVars struct {
+	// Подзапрос, которому принадлежит переменная
+	owners map[string]*SubQuery
+	// Количество вхождений переменной в подзапросы
+	counts map[string]int
+}
+
+// embed - sql template of the subquery, its variables are registered
+func (e *embeddedVars) embed(query any) (string, error) {
+	q := Sub(query)
+
+	sql, err := q.Template()
+	if err != nil {
+		return "", err
+	}
+
+	return e.register(q, sql)
+}
+
+// register - register the variables of the subquery sql
+func (e *embeddedVars) register(q *SubQuery, sql string) (string, error) {
+	if q.shared {
+		return sql, nil
+	}
+
+	p := NewParser(sql)
+	if err := p.Parse(); err != nil {
+		return "", err
+	}
+
+	if e.owners == nil {
+		e.owners = map[string]*SubQuery{}
+		e.counts = map[string]int{}
+	}
+
+	for _, d := range p.parsed {
+		if owner, ok := e.owners[d.name]; ok && owner != q {
+			return "", nerr.New(fmt.Sprintf("variable %s is used in several subqueries, use Sub(...).Prefix", d.name))
+		}
+		e.owners[d.name] = q
+		e.counts[d.name]++
+	}
+
+	return sql, nil
+}
+
+// check - the variables of the subqueries are not used elsewhere in the resulting template
+func (e *embeddedVars) check(template string) error {
+	if len(e.owners) == 0 {
+		return nil
+	}
+
+	p := NewParser(template)
+	if err := p.Parse(); err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	for _, d := range p.parsed {
+		counts[d.name]++
+	}
+
+	for name, n := range e.counts {
+		if counts[name] != n {
+			return nerr.New(fmt.Sprintf("variable %s of the subquery is also used in the outer query, use Sub(...).Prefix or Sub(...).Shared", name))
+		}
+	}
+
+	return nil
+}
+
+// subCond - comparison of the column with the subquery
+type subCond struct {
+	column string
+	op     string
+	query  any
+}
+
+// InQuery - column IN (subquery). See Sub for the supported subqueries
+func InQuery(column string, query any) Cond {
+	return &subCond{column: column, op: "IN", query: query}
+}
+
+// NotInQuery - column NOT IN (subquery)
+func NotInQuery(column string, query any) Cond {
+	return &subCond{column: column, op: "NOT IN", query: query}
+}
+
+// Exists - EXISTS (subquery). The subquery can refer to the tables of the outer query by their aliases (correlated subquery):
+// Exists(Select("1").From("orders o").Where("o.user_id = u.id")). See Sub for the supported subqueries
+func Exists(query any) Cond {
+	return &subCond{op: "EXISTS", query: query}
+}
+
+// NotExists - NOT EXISTS (subquery). See Exists
+func NotExists(query any) Cond {
+	return &subCond{op: "NOT EXISTS", query: query}
+}
+
+func (c *subCond) renderCond(r *condRenderer) (string, error) {
+	exists := c.op == "EXISTS" || c.op == "NOT EXISTS"
+	if !exists {
+		if err := checkExpressions("column", []string{c.column}); err != nil {
+			return "", err
+		}
+	}
+
+	sql, err := r.subquery(c.query)
+	if err != nil {
+		if exists {
+			return "", nerr.New(fmt.Sprintf("%s: %v", c.op, err))
+		}
+		return "", nerr.New(fmt.Sprintf("%s: %v", c.column, err))
+	}
+
+	if exists {
+		return c.op + " (" + sql + ")", nil
+	}
+
+	return c.column + " " + c.op + " (" + sql + ")", nil
+}
+
+// BindSubquery - bind the subquery in parentheses. The subquery must not have unbound variables:
+// use a *SqlBinder or *Query with bound values, a builder or a template without variables
+func (b *SqlBinder) BindSubquery(variable string, query any) error {
+	return b.bindSql(variable, false, query, nil, func() (string, error) {
+		sql, err := subquerySql(query)
+		if err != nil {
+			return "", err
+		}
+
+		p := NewParser(sql)
+		if err := p.Parse(); err != nil {
+			return "", err
+		}
+		if len(p.parsed) > 0 {
+			return "", nerr.New(fmt.Sprintf("subquery has unbound variables: %s", strings.Join(p.ParcedVariables(), ", ")))
+		}
+
+		return "(" + sql + ")", nil
+	})
+}
@@ -0,0 +1,72 @@
+package sqlb
+
+import "testing"
+
+func TestSelectBuilder_Subquery(t *testing.T) {
+	orders := Select("user_id").From("orders").Where("total > :total")
+	last := MustCompile("SELECT max(created_at) FROM logins WHERE user_id = u.id AND ip <> :ip")
+
+	sql, err := Select("u.id").ColumnQuery(Sub(last).Prefix("login"), "last_login").
+		FromQuery(Select("*").From("users").Where("active = :active"), "u").
+		WhereCond(InQuery("u.id", orders)).
+		Q().Set("total", 100).Set("login_ip", "127.0.0.1").Set("active", true).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := "SELECT u.id, (SELECT max(created_at) FROM logins WHERE user_id = u.id AND ip <> E'127.0.0.1') AS last_login " +
+		"FROM (SELECT * FROM users WHERE active = true) u WHERE u.id IN (SELECT user_id FROM orders WHERE total > 100)"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	// переменная подзапроса используется во внешнем запросе
+	if _, err := Select().From("users").Where("total = :total").WhereCond(InQuery("id", orders)).Template(); err == nil {
+		t.Fatal("expected variable collision error")
+	}
+	// одна переменная в двух подзапросах
+	if _, err := Select().From("users").WhereCond(InQuery("id", orders)).WhereCond(NotInQuery("id", orders)).Template(); err == nil {
+		t.Fatal("expected variable collision error")
+	}
+	// общая переменная разрешена явно
+	if _, err := Select().From("users").Where("total = :total").WhereCond(InQuery("id", Sub(orders).Shared())).Template(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSqlBinder_BindSubquery(t *testing.T) {
+	sub := MustCompile("SELECT id FROM orders WHERE total > :total").Binder()
+	sub.MustBind("total", 100)
+
+	b := NewBinder("SELECT * FROM users WHERE id IN :sub")
+	if err := b.BindSubquery("sub", sub); err != nil {
+		t.Fatal(err)
+	}
+	if sql, req := b.MustSql(), "SELECT * FROM users WHERE id IN (SELECT id FROM orders WHERE total > 100)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if err := NewBinder("SELECT :sub").BindSubquery("sub", "SELECT :x"); err == nil {
+		t.Fatal("expected error for unbound variables")
+	}
+}
+
+func TestExists(t *testing.T) {
+	q := Select("u.id").From("users u").
+		WhereCond(Exists(Select("1").From("orders o").Where("o.user_id = u.id AND o.total > :min"))).
+		WhereCond(NotExists(Sub("SELECT 1 FROM bans b WHERE b.user_id = u.id AND b.reason = :min").Prefix("ban")))
+
+	sql, err := q.Binder().MustBind("min", 10).MustBind("ban_min", "spam").Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "SELECT u.id FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id AND o.total > 10) AND " +
+		"NOT EXISTS (SELECT 1 FROM bans b WHERE b.user_id = u.id AND b.reason = E'spam')"
+	if sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := CondSql(Exists(nil)); err == nil {
+		t.Fatal("expected error for nil subquery")
+	}
+}
@@ -0,0 +1,85 @@
+package sqlb
+
+import (
+	"fmt"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Template - compiled sql template. The template is parsed once and is immutable after that,
+// so it is safe for concurrent use from multiple goroutines. Values are passed on each call
+type Template struct {
+	parser *Parser
+}
+
+// Compile - parse the sql template
+func Compile(template string) (*Template, error) {
+	parser := NewParser(template)
+	if err := parser.Parse(); err != nil {
+		return nil, err
+	}
+
+	return &Template{parser: parser}, nil
+}
+
+// MustCompile - same as Compile, but panics on error
+func MustCompile(template string) *Template {
+	t, err := Compile(template)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// SqlTemplate - source sql template
+func (t *Template) SqlTemplate() string {
+	return t.parser.SqlTemplate()
+}
+
+// ParcedVariables - list of variables in the template
+func (t *Template) ParcedVariables() []string {
+	return t.parser.ParcedVariables()
+}
+
+// Sql - substitute values into the template. Values are converted via ToSql
+func (t *Template) Sql(values map[string]any) (string, error) {
+	prepared := make(map[string]string, len(values))
+	for variable, value := range values {
+		if len(variable) == 0 {
+			return "", nerr.New("empty variable")
+		}
+
+		val, err := ToSql(value)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("%s: %v", variable, err))
+		}
+		prepared[variableName(variable)] = val
+	}
+
+	return calculate(t.parser, prepared, nil, nil)
+}
+
+// CalculateBatch - substitute each set of values into the template, one statement per set.
+// The template is parsed only once. The error contains the index of the failed set
+func (t *Template) CalculateBatch(valueSets []map[string]any) ([]string, error) {
+	res := make([]string, len(valueSets))
+	for i, values := range valueSets {
+		sql, err := t.Sql(values)
+		if err != nil {
+			return nil, nerr.New(fmt.Sprintf("value set %d: %v", i, err))
+		}
+		res[i] = sql
+	}
+
+	return res, nil
+}
+
+// Binder - new binder for the template. The binder itself is not safe for concurrent use,
+// but any number of binders can be created from the same template. WithCacheKey is ignored
+func (t *Template) Binder(opts ...BinderOption) *SqlBinder {
+	b := newBinder(t.parser, newBinderOptions(opts))
+	b.err = b.applyTenant()
+
+	return b
+}
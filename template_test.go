@@ -0,0 +1,56 @@
+package sqlb
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTemplate_Concurrent(t *testing.T) {
+	tmpl := MustCompile("SELECT * FROM t WHERE id = :id AND name = :name")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sql, err := tmpl.Sql(map[string]any{"id": i, ":name": "n"})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if req := fmt.Sprintf("SELECT * FROM t WHERE id = %d AND name = E'n'", i); sql != req {
+				t.Errorf("%s, wants: %s", sql, req)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := tmpl.Sql(map[string]any{"id": 1}); err == nil {
+		t.Fatal("expected error for unbound variable")
+	}
+}
+
+func TestTemplate_CalculateBatch(t *testing.T) {
+	tmpl := MustCompile("INSERT INTO t VALUES (:id, :name)")
+
+	res, err := tmpl.CalculateBatch([]map[string]any{
+		{"id": 1, "name": "a"},
+		{"id": 2, "name": nil},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := []string{"INSERT INTO t VALUES (1, E'a')", "INSERT INTO t VALUES (2, null)"}
+	for i := range req {
+		if res[i] != req[i] {
+			t.Errorf("%s, wants: %s", res[i], req[i])
+		}
+	}
+
+	if _, err := tmpl.CalculateBatch([]map[string]any{{"id": 1}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
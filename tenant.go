@@ -0,0 +1,101 @@
+package sqlb
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/n-r-w/nerr"
+)
+
+// TenantVariable - variable of the tenant identifier, bound by WithTenant
+const TenantVariable = "tenant_id"
+
+var tenantRequired atomic.Bool
+
+// RequireTenant - every binder must be created with WithTenant (or WithoutTenant for cross-tenant queries),
+// otherwise the binder returns an error. Enforces multi-tenant isolation for the whole package
+func RequireTenant(required bool) {
+	tenantRequired.Store(required)
+}
+
+// WithTenant - bind the tenant identifier to :tenant_id. The template must reference :tenant_id, otherwise the binder returns an error.
+// SELECT, UPDATE and DELETE builders add the condition "tenant_id = :tenant_id" for their table (FROM table and joined tables
+// of SELECT, for joins the condition is added to ON), INSERT adds the column tenant_id = :tenant_id to every row,
+// if the query doesn't reference :tenant_id itself. Reference :tenant_id explicitly to join tables without tenant_id
+func WithTenant(tenant any) BinderOption {
+	return func(o *binderOptions) {
+		o.tenant = tenant
+		o.hasTenant = true
+	}
+}
+
+// WithoutTenant - explicitly allow the query without tenant scoping, when RequireTenant is on
+func WithoutTenant() BinderOption {
+	return func(o *binderOptions) {
+		o.noTenant = true
+	}
+}
+
+// applyTenant - check the tenant scoping and bind the tenant identifier
+func (b *SqlBinder) applyTenant() error {
+	if !b.hasTenant {
+		if tenantRequired.Load() && !b.noTenant {
+			return nerr.New("tenant is required, use WithTenant or WithoutTenant")
+		}
+		return nil
+	}
+
+	if !referencesTenant(b.parcer) {
+		return nerr.New("template doesn't reference :" + TenantVariable)
+	}
+
+	return b.Bind(TenantVariable, b.tenant)
+}
+
+// referencesTenant - the template contains :tenant_id
+func referencesTenant(p *Parser) bool {
+	if err := p.checkParsed(); err != nil {
+		return false
+	}
+
+	_, ok := p.parsedMap[":"+TenantVariable]
+	return ok
+}
+
+// needTenantCond - the builder must add the tenant condition to the template
+func needTenantCond(template string, opts []BinderOption) bool {
+	return newBinderOptions(opts).hasTenant && !referencesTenant(NewParser(template))
+}
+
+// tenantCond - condition "tenant_id = :tenant_id" for the table alias
+func tenantCond(alias string) Cond {
+	column := TenantVariable
+	if len(alias) > 0 {
+		column = alias + "." + column
+	}
+
+	return Expr(column + " = :" + TenantVariable)
+}
+
+// withTenant - copy of the builder with the tenant conditions for the FROM table and joined tables.
+// Joined subqueries and CTE are not scoped
+func (s *SelectBuilder) withTenant() *SelectBuilder {
+	c := *s
+	c.where = append(s.where[:len(s.where):len(s.where)], tenantCond(s.fromAlias))
+	c.joins = make([]selectJoin, len(s.joins))
+
+	for n, j := range s.joins {
+		c.joins[n] = j
+		if len(j.alias) == 0 || strings.HasPrefix(j.table, "(") || s.isCte(j.table) {
+			continue
+		}
+
+		if j.kind == "CROSS JOIN" {
+			c.where = append(c.where, tenantCond(j.alias))
+		} else {
+			c.joins[n].on = "(" + j.on + ") AND " + j.alias + "." + TenantVariable + " = :" + TenantVariable
+		}
+	}
+
+	return &c
+}
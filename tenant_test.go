@@ -0,0 +1,100 @@
+package sqlb
+
+import "testing"
+
+func TestWithTenant(t *testing.T) {
+	sql, err := NewBinder("SELECT * FROM t WHERE tenant_id = :tenant_id", WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT * FROM t WHERE tenant_id = 7"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := NewBinder("SELECT * FROM t", WithTenant(7)).Sql(); err == nil {
+		t.Fatal("expected error for template without :tenant_id")
+	}
+
+	sql, err = Select("id").From("users u").Where("u.id = 1").Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT id FROM users u WHERE (u.id = 1) AND (u.tenant_id = 7)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Delete("users").Where("tenant_id = :tenant_id").Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "DELETE FROM users WHERE tenant_id = 7"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestRequireTenant(t *testing.T) {
+	RequireTenant(true)
+	defer RequireTenant(false)
+
+	if _, err := NewBinder("SELECT 1").Sql(); err == nil {
+		t.Fatal("expected error without tenant")
+	}
+	if _, err := NewBinder("SELECT 1", WithoutTenant()).Sql(); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, err := Update("users").Set("name", 1).Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "UPDATE users SET name = 1 WHERE users.tenant_id = 7"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestWithTenant_Joins(t *testing.T) {
+	sql, err := Select("u.id").From("users u").LeftJoin("orders o", "o.user_id = u.id").CrossJoin("groups g").
+		LateralJoin("SELECT 1", "l", "").Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT u.id FROM users u LEFT JOIN orders o ON (o.user_id = u.id) AND o.tenant_id = 7 CROSS JOIN groups g " +
+		"JOIN LATERAL (SELECT 1) l ON TRUE WHERE (u.tenant_id = 7) AND (g.tenant_id = 7)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	// явная ссылка на :tenant_id отключает автоматические условия
+	sql, err = Select("u.id").From("users u").Join("countries c", "c.id = u.country_id").
+		Where("u.tenant_id = :tenant_id").Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "SELECT u.id FROM users u JOIN countries c ON c.id = u.country_id WHERE u.tenant_id = 7"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
+
+func TestWithTenant_Insert(t *testing.T) {
+	sql, err := Insert("users").Columns("name").Values("a").Values("b").Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "INSERT INTO users (name, tenant_id) VALUES (E'a', 7), (E'b', 7)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Insert("users").Columns("name").Values("a").Placeholders().Binder(WithTenant(7)).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "INSERT INTO users (name, tenant_id) VALUES (E'a', 7)"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Insert("users").Columns("name", "tenant_id").Values("a", 8).Binder(WithTenant(7)).Sql(); err == nil {
+		t.Fatal("expected error for explicit tenant_id column")
+	}
+	if _, err := Insert("users").Select(Select("name").From("tmp")).Binder(WithTenant(7)).Sql(); err == nil {
+		t.Fatal("expected error for INSERT ... SELECT without :tenant_id")
+	}
+}
@@ -0,0 +1,158 @@
+package sqlb
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Operations of SpanInfo
+const (
+	// SpanCalculate - substitution of the values into the template
+	SpanCalculate = "calculate"
+	// SpanExecute - execution of the query
+	SpanExecute = "execute"
+)
+
+// SpanInfo - traced operation
+type SpanInfo struct {
+	// Операция: SpanCalculate или SpanExecute
+	Operation string
+	// Шаблон запроса
+	Template string
+	// Ключ шаблона: ключ кэширования или хэш содержимого шаблона
+	Key string
+	// Тип оператора: SELECT, INSERT, UPDATE и т.д.
+	Statement string
+	// Запрос для журнала: без конфиденциальных значений (SqlRedacted) или с параметрами $1, $2...
+	Sql string
+}
+
+// SpanEnd - end of the span with the number of rows (-1 if unknown) and the error of the operation
+type SpanEnd func(rows int64, err error)
+
+// Tracer - receiver of the spans of calculation and execution, e.g. an adapter to OpenTelemetry (see package sqlbotel).
+// Must be safe for concurrent use
+type Tracer interface {
+	// Start - start the span, the returned context contains the span
+	Start(ctx context.Context, info SpanInfo) (context.Context, SpanEnd)
+}
+
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer - set the tracer for the whole package. nil disables tracing
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+
+	tracer.Store(&t)
+}
+
+// Instrumented - a tracer (SetTracer), a logger (SetLogger) or TemplateMetrics (SetMetrics) is set, so StartSpan is not a no-op
+func Instrumented() bool {
+	return tracer.Load() != nil || logger.Load() != nil || currentTemplateMetrics() != nil
+}
+
+// StartSpan - start the span of the tracer set by SetTracer, the end of the span is also passed to the logger set by SetLogger
+// and to TemplateMetrics (see SetMetrics). Key and Statement are filled from the template if empty.
+// Without the tracer, the logger and the metrics the end function is a no-op. The returned context contains info, see SpanFromContext
+func StartSpan(ctx context.Context, info SpanInfo) (context.Context, SpanEnd) {
+	ctx, s := startSpan(ctx, info, true)
+	if s != nil {
+		info = s.info
+	}
+
+	return context.WithValue(ctx, spanInfoKey{}, info), s.end
+}
+
+type spanInfoKey struct{}
+
+// SpanFromContext - operation started by StartSpan, e.g. for a test driver to get the template of the executed query
+func SpanFromContext(ctx context.Context) (SpanInfo, bool) {
+	info, ok := ctx.Value(spanInfoKey{}).(SpanInfo)
+	return info, ok
+}
+
+// span - started span of the tracer and the logger
+type span struct {
+	ctx   context.Context
+	info  SpanInfo
+	start time.Time
+	// Завершение span трассировщика
+	traceEnd SpanEnd
+	logger   Logger
+	metrics  TemplateMetrics
+}
+
+// startSpan - start the span, nil if there is neither the logger, the metrics nor the tracer (or trace is false)
+func startSpan(ctx context.Context, info SpanInfo, trace bool) (context.Context, *span) {
+	var t Tracer
+	if p := tracer.Load(); p != nil && trace {
+		t = *p
+	}
+	l := currentLogger()
+	m := currentTemplateMetrics()
+	if t == nil && l == nil && m == nil {
+		return ctx, nil
+	}
+
+	if len(info.Key) == 0 && len(info.Template) > 0 {
+		info.Key = templateKey(info.Template)
+	}
+	if len(info.Statement) == 0 {
+		info.Statement = StatementType(info.Template)
+	}
+
+	s := &span{
+		info:    info,
+		start:   time.Now(),
+		logger:  l,
+		metrics: m,
+	}
+	if t != nil {
+		ctx, s.traceEnd = t.Start(ctx, info)
+	}
+	s.ctx = ctx
+
+	return ctx, s
+}
+
+// end - end of the span
+func (s *span) end(rows int64, err error) {
+	if s == nil {
+		return
+	}
+
+	if s.traceEnd != nil {
+		s.traceEnd(rows, err)
+	}
+
+	d := time.Since(s.start)
+
+	if s.metrics != nil {
+		if s.info.Operation == SpanCalculate {
+			s.metrics.TemplateCalculate(s.info.Key, d, err)
+		} else {
+			s.metrics.TemplateExecute(s.info.Key, s.info.Statement, d, rows, err)
+		}
+	}
+
+	if s.logger != nil {
+		s.logger.LogQuery(s.ctx, QueryLog{
+			Operation: s.info.Operation,
+			Key:       s.info.Key,
+			Statement: s.info.Statement,
+			Sql:       s.info.Sql,
+			Duration:  d,
+			Rows:      rows,
+			Err:       err,
+		})
+	}
+}
+
+// SqlContext - same as Sql, but the calculation is traced by the tracer set by SetTracer and ctx is passed to the logger
+func (b *SqlBinder) SqlContext(ctx context.Context) (string, error) {
+	return b.sqlContext(ctx, true)
+}
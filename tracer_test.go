@@ -0,0 +1,47 @@
+package sqlb
+
+import (
+	"context"
+	"testing"
+)
+
+// testTracer - tracer, which records the spans
+type testTracer struct {
+	spans []SpanInfo
+	rows  []int64
+}
+
+func (t *testTracer) Start(ctx context.Context, info SpanInfo) (context.Context, SpanEnd) {
+	t.spans = append(t.spans, info)
+	return ctx, func(rows int64, err error) {
+		t.rows = append(t.rows, rows)
+	}
+}
+
+func TestSetTracer(t *testing.T) {
+	tr := &testTracer{}
+	SetTracer(tr)
+	defer SetTracer(nil)
+
+	ctx := context.Background()
+	if _, err := NewBinder("UPDATE t SET a = :a", WithCacheKey("t.update")).MustBind("a", 1).SqlContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_, end := StartSpan(ctx, SpanInfo{Operation: SpanExecute, Template: "WITH x AS (SELECT 1) SELECT * FROM x"})
+	end(1, nil)
+
+	if len(tr.spans) != 2 || len(tr.rows) != 2 {
+		t.Fatalf("unexpected spans: %v", tr.spans)
+	}
+	if s := tr.spans[0]; s.Operation != SpanCalculate || s.Key != "t.update" || s.Statement != "UPDATE" {
+		t.Fatalf("unexpected span: %+v", s)
+	}
+	if s := tr.spans[1]; s.Key != templateKey(s.Template) || s.Statement != "WITH" || tr.rows[1] != 1 {
+		t.Fatalf("unexpected span: %+v", s)
+	}
+
+	SetTracer(nil)
+	if _, end := StartSpan(ctx, SpanInfo{}); end == nil {
+		t.Fatal("expected no-op end")
+	}
+}
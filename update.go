@@ -0,0 +1,275 @@
+package sqlb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// UpdateBuilder - builder of UPDATE queries. SET values are converted via ToSql,
+// WHERE conditions are inserted as is and may contain :variables, which are bound by the binder returned by Binder or Q.
+// The first error is saved and returned by Template
+type UpdateBuilder struct {
+	table     string
+	set       []updateValue
+	skipZero  bool
+	where     []Cond
+	returning []string
+	// Не добавлять условие мягкого удаления
+	includeDeleted bool
+	audit          auditState
+	// Режим подстановки переменных вместо значений
+	placeholders bool
+	err          error
+}
+
+// updateValue - column value
+type updateValue struct {
+	column string
+	value  any
+	opts   []Option
+}
+
+// Update - create UPDATE builder for the table
+func Update(table string) *UpdateBuilder {
+	u := &UpdateBuilder{table: table}
+	u.setErr(checkExpressions("table", []string{table}))
+
+	return u
+}
+
+// setErr - save the first error
+func (u *UpdateBuilder) setErr(err error) {
+	if u.err == nil {
+		u.err = err
+	}
+}
+
+// When - call fn with the builder, if cond is true, so optional parts can be added without breaking the chain
+func (u *UpdateBuilder) When(cond bool, fn func(b *UpdateBuilder)) *UpdateBuilder {
+	if cond && fn != nil {
+		fn(u)
+	}
+
+	return u
+}
+
+// Set - set the column value
+func (u *UpdateBuilder) Set(column string, value any, opts ...Option) *UpdateBuilder {
+	u.setErr(checkExpressions("column", []string{column}))
+	u.set = append(u.set, updateValue{column: column, value: value, opts: opts})
+	return u
+}
+
+// SetMap - set the values of the columns from the map. Columns are sorted by name
+func (u *UpdateBuilder) SetMap(values map[string]any) *UpdateBuilder {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		u.Set(column, values[column])
+	}
+
+	return u
+}
+
+// SetStruct - set the values of the columns from the struct fields. Column names and options are taken
+// from the db tags as in BindStruct
+func (u *UpdateBuilder) SetStruct(s any) *UpdateBuilder {
+	v, err := structValue(s)
+	if err != nil {
+		u.setErr(err)
+		return u
+	}
+
+	u.setErr(walkStruct(v, func(name string, value any, opts []Option) error {
+		u.Set(name, value, opts...)
+		return nil
+	}))
+
+	return u
+}
+
+// SkipZero - do not update the columns with nil or zero values
+func (u *UpdateBuilder) SkipZero() *UpdateBuilder {
+	u.skipZero = true
+	return u
+}
+
+// Where - add the condition as is, it may contain :variables. Conditions are combined by AND
+func (u *UpdateBuilder) Where(cond string) *UpdateBuilder {
+	u.setErr(checkExpressions("condition", []string{cond}))
+	u.where = append(u.where, Expr(cond))
+	return u
+}
+
+// WhereCond - add the condition (see Cond). Conditions are combined by AND
+func (u *UpdateBuilder) WhereCond(c Cond) *UpdateBuilder {
+	if c == nil {
+		u.setErr(nerr.New("nil condition"))
+		return u
+	}
+
+	u.where = append(u.where, c)
+	return u
+}
+
+// Returning - RETURNING clause
+func (u *UpdateBuilder) Returning(exprs ...string) *UpdateBuilder {
+	u.setErr(checkExpressions("RETURNING expression", exprs))
+	u.returning = append(u.returning, exprs...)
+	return u
+}
+
+// IncludeDeleted - update soft deleted rows too. See SetSoftDelete
+func (u *UpdateBuilder) IncludeDeleted() *UpdateBuilder {
+	u.includeDeleted = true
+	return u
+}
+
+// Audit - set the audit columns (see SetAudit): updated_at - NOW(), updated_by - the actor from the context (see WithActor).
+// For tables not registered by SetAudit the columns are updated_at and updated_by. Explicitly set columns are not changed
+func (u *UpdateBuilder) Audit(ctx context.Context) *UpdateBuilder {
+	u.setErr(u.audit.set(ctx, u.table))
+	return u
+}
+
+// Placeholders - emit values as generated variables :sqlb_1, :sqlb_2, ... instead of inline literals.
+// The values are returned by TemplateParams and bound by Binder and Q
+func (u *UpdateBuilder) Placeholders() *UpdateBuilder {
+	u.placeholders = true
+	return u
+}
+
+func (u *UpdateBuilder) usesPlaceholders() bool {
+	return u.placeholders
+}
+
+// Err - the first error that occurred
+func (u *UpdateBuilder) Err() error {
+	return u.err
+}
+
+// Validate - check the query and, if the schema is registered by SetSchema, check it against the schema. See Schema.Validate
+func (u *UpdateBuilder) Validate() error {
+	if _, err := u.Template(); err != nil {
+		return err
+	}
+
+	return validateSchema(u)
+}
+
+// Template - sql template of the query
+func (u *UpdateBuilder) Template() (string, error) {
+	return u.template(newCondRenderer(u.placeholders))
+}
+
+// TemplateParams - sql template of the query and the values of the generated variables (see Placeholders)
+func (u *UpdateBuilder) TemplateParams() (string, map[string]any, error) {
+	r := newCondRenderer(u.placeholders)
+	sql, err := u.template(r)
+	return sql, r.params, err
+}
+
+func (u *UpdateBuilder) template(r *condRenderer) (string, error) {
+	if u.err != nil {
+		return "", u.err
+	}
+
+	items := make([]string, 0, len(u.set))
+	for _, s := range u.set {
+		value := s.value
+		if v, ok := value.(Value); ok {
+			value = v.value
+		}
+		if u.skipZero && isZero(value) {
+			continue
+		}
+
+		val, err := r.value(withOptions(s.value, s.opts))
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("column %s: %v", s.column, err))
+		}
+		items = append(items, s.column+" = "+val)
+	}
+
+	if len(items) == 0 {
+		return "", nerr.New("no columns to update")
+	}
+
+	explicit := make([]string, len(u.set))
+	for i, s := range u.set {
+		explicit[i] = s.column
+	}
+	auditCols, auditVals, err := u.audit.values(u.table, false, explicit)
+	if err != nil {
+		return "", err
+	}
+	for i, column := range auditCols {
+		val, err := r.value(auditVals[i])
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("column %s: %v", column, err))
+		}
+		items = append(items, column+" = "+val)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("UPDATE ")
+	sql.WriteString(u.table)
+	sql.WriteString(" SET ")
+	sql.WriteString(strings.Join(items, ", "))
+
+	conds := u.where
+	if column := softDeleteColumn(u.table); len(column) > 0 && !u.includeDeleted {
+		conds = append(conds[:len(conds):len(conds)], IsNull(column))
+	}
+
+	if len(conds) > 0 {
+		where, err := conditionsToSql(conds, r)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(" WHERE ")
+		sql.WriteString(where)
+	}
+
+	if len(u.returning) > 0 {
+		sql.WriteString(" RETURNING ")
+		sql.WriteString(strings.Join(u.returning, ", "))
+	}
+
+	if err := r.embedded.check(sql.String()); err != nil {
+		return "", err
+	}
+
+	return sql.String(), nil
+}
+
+// Binder - binder for the query template
+func (u *UpdateBuilder) Binder(opts ...BinderOption) *SqlBinder {
+	q := u
+	if sql, err := u.Template(); err == nil && needTenantCond(sql, opts) {
+		c := *u
+		c.where = append(u.where[:len(u.where):len(u.where)], tenantCond(tableAlias(u.table)))
+		q = &c
+	}
+
+	sql, params, err := q.TemplateParams()
+	return paramsBinder(sql, params, err, opts)
+}
+
+// Q - chainable Query for the query template
+func (u *UpdateBuilder) Q(opts ...BinderOption) *Query {
+	return &Query{binder: u.Binder(opts...)}
+}
+
+// Sql - the query, if its conditions have no variables
+func (u *UpdateBuilder) Sql() (string, error) {
+	return u.Binder().Sql()
+}
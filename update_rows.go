@@ -0,0 +1,197 @@
+package sqlb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/n-r-w/nerr"
+)
+
+// ColumnType - sql type of the column in UpdateRows. Values of the VALUES list have no declared types,
+// so strings, time values and nulls need the cast to the type of the updated column: ColumnType("created_at", "timestamptz")
+func ColumnType(column string, typ string) UpdateRowsOption {
+	return updateRowsOption(func(o *rowsOptions) {
+		if o.columnTypes == nil {
+			o.columnTypes = map[string]string{}
+		}
+		o.columnTypes[column] = typ
+	})
+}
+
+// updateRowsOption - option of UpdateRows only
+type updateRowsOption func(o *rowsOptions)
+
+func (f updateRowsOption) applyUpdate(o *rowsOptions) {
+	f(o)
+}
+
+// UpdateRows - batch update of rows in one statement:
+// UPDATE table AS t SET col = v.col FROM (VALUES (...), (...)) AS v(id, col) WHERE t.id = v.id.
+// rows is a slice of structs (columns from the db tags as in BindStruct) or of map[string]any, all rows must have the same columns.
+// keys are the columns identifying the row, the other columns are updated. Values are converted via ToSql with the value
+// conversion options, rows are split into statements of at most RowsPerStatement (DefaultRowsPerStatement) rows
+func UpdateRows(table string, keys []string, rows any, opts ...UpdateRowsOption) ([]string, error) {
+	if err := checkExpressions("table", []string{table}); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, nerr.New("no key columns")
+	}
+
+	columns, values, err := rowsValues(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	o := &rowsOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyUpdate(o)
+		}
+	}
+
+	set, err := updateRowsSet(columns, keys, o.columnTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	where := make([]string, len(keys))
+	for i, key := range keys {
+		where[i] = "t." + key + " = " + castColumn("v."+key, o.columnTypes[key])
+	}
+
+	chunk := o.chunk()
+
+	prefix := "UPDATE " + table + " AS t SET " + strings.Join(set, ", ") + " FROM (VALUES "
+	suffix := ") AS v(" + strings.Join(columns, ", ") + ") WHERE " + strings.Join(where, " AND ")
+
+	res := make([]string, 0, (len(values)+chunk-1)/chunk)
+	for start := 0; start < len(values); start += chunk {
+		end := start + chunk
+		if end > len(values) {
+			end = len(values)
+		}
+
+		var sql strings.Builder
+		sql.WriteString(prefix)
+		for n := start; n < end; n++ {
+			if n > start {
+				sql.WriteString(", ")
+			}
+			if err := writeValuesRow(&sql, values[n], o.values); err != nil {
+				return nil, nerr.New(fmt.Sprintf("row %d: %v", n, err))
+			}
+		}
+		sql.WriteString(suffix)
+		res = append(res, sql.String())
+	}
+
+	return res, nil
+}
+
+// updateRowsSet - SET items for the columns that are not keys
+func updateRowsSet(columns []string, keys []string, types map[string]string) ([]string, error) {
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+
+	found := 0
+	var set []string
+	for _, c := range columns {
+		if isKey[c] {
+			found++
+			continue
+		}
+		set = append(set, c+" = "+castColumn("v."+c, types[c]))
+	}
+
+	if found != len(isKey) {
+		return nil, nerr.New(fmt.Sprintf("key columns %s are not found in the rows", strings.Join(keys, ", ")))
+	}
+	if len(set) == 0 {
+		return nil, nerr.New("no columns to update")
+	}
+
+	return set, nil
+}
+
+// castColumn - column with the optional cast
+func castColumn(column string, typ string) string {
+	if len(typ) == 0 {
+		return column
+	}
+
+	return column + "::" + typ
+}
+
+// rowsValues - columns and values of the rows: slice of structs or of map[string]any
+func rowsValues(rows any) ([]string, [][]any, error) {
+	v := reflect.ValueOf(rows)
+	if rows == nil || v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, nil, nerr.New(fmt.Sprintf("slice expected, got %T", rows))
+	}
+
+	var columns []string
+	values := make([][]any, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		rowColumns, row, err := rowValues(v.Index(i).Interface())
+		if err != nil {
+			return nil, nil, nerr.New(fmt.Sprintf("row %d: %v", i, err))
+		}
+
+		if i == 0 {
+			columns = rowColumns
+		} else if !reflect.DeepEqual(columns, rowColumns) {
+			return nil, nil, nerr.New(fmt.Sprintf("row %d: columns %v differ from %v", i, rowColumns, columns))
+		}
+		values[i] = row
+	}
+
+	return columns, values, nil
+}
+
+// rowValues - columns and values of one row
+func rowValues(row any) ([]string, []any, error) {
+	if m, ok := row.(map[string]any); ok {
+		columns := make([]string, 0, len(m))
+		for c := range m {
+			columns = append(columns, c)
+		}
+		sort.Strings(columns)
+
+		values := make([]any, len(columns))
+		for i, c := range columns {
+			values[i] = m[c]
+		}
+
+		return columns, values, nil
+	}
+
+	v, err := structValue(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var columns []string
+	var values []any
+	err = walkStruct(v, func(name string, value any, opts []Option) error {
+		columns = append(columns, name)
+		if len(opts) > 0 {
+			value = V(value, opts...)
+		}
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return columns, values, nil
+}
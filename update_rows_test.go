@@ -0,0 +1,62 @@
+package sqlb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateRows(t *testing.T) {
+	type item struct {
+		ID      int `db:"id"`
+		Price   float64
+		Updated time.Time `db:"updated_at"`
+		Note    string    `db:"note,nullzero"`
+	}
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []item{
+		{ID: 1, Price: 1.5, Updated: ts, Note: "a"},
+		{ID: 2, Price: 2, Updated: ts},
+		{ID: 3, Price: 3, Updated: ts},
+	}
+
+	sqls, err := UpdateRows("items", []string{"id"}, rows, ColumnType("updated_at", "timestamptz"), ColumnType("note", "text"), RowsPerStatement(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := []string{
+		"UPDATE items AS t SET price = v.price, updated_at = v.updated_at::timestamptz, note = v.note::text FROM (VALUES " +
+			"(1, 1.5, '2024-01-02 03:04:05.000000 +0000', E'a'), (2, 2, '2024-01-02 03:04:05.000000 +0000', null)" +
+			") AS v(id, price, updated_at, note) WHERE t.id = v.id",
+		"UPDATE items AS t SET price = v.price, updated_at = v.updated_at::timestamptz, note = v.note::text FROM (VALUES " +
+			"(3, 3, '2024-01-02 03:04:05.000000 +0000', null)" +
+			") AS v(id, price, updated_at, note) WHERE t.id = v.id",
+	}
+	if len(sqls) != len(req) {
+		t.Fatalf("%v, wants: %v", sqls, req)
+	}
+	for i := range req {
+		if sqls[i] != req[i] {
+			t.Fatalf("%s, wants: %s", sqls[i], req[i])
+		}
+	}
+
+	sqls, err = UpdateRows("t", []string{"a", "b"}, []map[string]any{{"a": 1, "b": 2, "c": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := "UPDATE t AS t SET c = v.c FROM (VALUES (1, 2, E'x')) AS v(a, b, c) WHERE t.a = v.a AND t.b = v.b"; sqls[0] != req {
+		t.Fatalf("%s, wants: %s", sqls[0], req)
+	}
+
+	if _, err := UpdateRows("t", []string{"id"}, []map[string]any{{"id": 1, "a": 1}, {"id": 2}}); err == nil {
+		t.Fatal("expected error for different columns")
+	}
+	if _, err := UpdateRows("t", []string{"x"}, []map[string]any{{"id": 1, "a": 1}}); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+	if _, err := UpdateRows("t", []string{"id"}, []map[string]any{{"id": 1}}); err == nil {
+		t.Fatal("expected error without columns to update")
+	}
+}
@@ -0,0 +1,37 @@
+package sqlb
+
+import "testing"
+
+func TestUpdateBuilder(t *testing.T) {
+	type user struct {
+		Name  string
+		Email string `db:"email,nullzero"`
+		Age   int
+		Note  string `db:"-"`
+	}
+
+	sql, err := Update("users").SetStruct(user{Name: "a'b", Age: 30}).Where("id = :id").Returning("id").
+		Q().Set("id", 5).Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := `UPDATE users SET name = E'a\'b', email = null, age = 30 WHERE id = 5 RETURNING id`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	sql, err = Update("users").SetMap(map[string]any{"name": "x", "age": 0, "email": nil}).SkipZero().
+		Where("id = 1").Where("deleted_at IS NULL").Sql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := `UPDATE users SET name = E'x' WHERE (id = 1) AND (deleted_at IS NULL)`; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+
+	if _, err := Update("users").Set("age", 0).SkipZero().Sql(); err == nil {
+		t.Fatal("expected error without columns")
+	}
+	if _, err := Update("users").SetStruct(1).Sql(); err == nil {
+		t.Fatal("expected error for non-struct value")
+	}
+}
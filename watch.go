@@ -0,0 +1,131 @@
+package sqlb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/n-r-w/nerr"
+)
+
+// Watch - development mode: every interval the directory is checked for files matching glob (see fs.Glob),
+// changed and new files are parsed and registered again (replacing the previous version),
+// templates of deleted files are removed. The template name is the file path relative to dir.
+// Errors are passed to onError (may be nil), the previous version of the failed template is kept.
+// Returns the function that stops watching. interval must be positive, glob must be a valid pattern
+func (r *Registry) Watch(dir string, glob string, interval time.Duration, onError func(error)) (stop func(), err error) {
+	return r.WatchFS(os.DirFS(dir), glob, interval, onError)
+}
+
+// WatchFS - same as Watch, but for fs.FS
+func (r *Registry) WatchFS(fsys fs.FS, glob string, interval time.Duration, onError func(error)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, nerr.New(fmt.Sprintf("invalid watch interval: %v", interval))
+	}
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, nerr.New(fmt.Sprintf("invalid glob %q: %v", glob, err))
+	}
+
+	w := &watcher{
+		registry: r,
+		fsys:     fsys,
+		glob:     glob,
+		modTimes: map[string]time.Time{},
+		onError:  onError,
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+
+	w.scan()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.scan()
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}, nil
+}
+
+// watcher - state of the directory watching
+type watcher struct {
+	registry *Registry
+	fsys     fs.FS
+	glob     string
+	// Время изменения загруженных файлов
+	modTimes map[string]time.Time
+	onError  func(error)
+}
+
+// scan - reload changed files
+func (w *watcher) scan() {
+	files, err := fs.Glob(w.fsys, w.glob)
+	if err != nil {
+		w.report(err)
+		return
+	}
+
+	var errs []error
+	found := make(map[string]bool, len(files))
+
+	for _, file := range files {
+		found[file] = true
+
+		info, err := fs.Stat(w.fsys, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+
+		if t, ok := w.modTimes[file]; ok && t.Equal(info.ModTime()) {
+			continue
+		}
+
+		data, err := fs.ReadFile(w.fsys, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+
+		t, err := Compile(string(data))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", file, err))
+		} else {
+			w.registry.set(file, t)
+		}
+		// запоминаем и при ошибке, чтобы не сообщать о ней повторно до следующего изменения
+		w.modTimes[file] = info.ModTime()
+	}
+
+	for file := range w.modTimes {
+		if !found[file] {
+			delete(w.modTimes, file)
+			w.registry.remove(file)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		w.report(err)
+	}
+}
+
+func (w *watcher) report(err error) {
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
@@ -0,0 +1,69 @@
+package sqlb
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRegistry_Watch(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users.sql": {Data: []byte("SELECT 1"), ModTime: time.Unix(1, 0)},
+	}
+
+	var lastErr error
+	reg := NewRegistry()
+	w := &watcher{
+		registry: reg,
+		fsys:     fsys,
+		glob:     "*.sql",
+		modTimes: map[string]time.Time{},
+		onError:  func(err error) { lastErr = err },
+	}
+
+	w.scan()
+	if sql, _ := reg.Sql("users.sql", nil); sql != "SELECT 1" {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+
+	// изменение файла
+	fsys["users.sql"] = &fstest.MapFile{Data: []byte("SELECT 2"), ModTime: time.Unix(2, 0)}
+	w.scan()
+	if sql, _ := reg.Sql("users.sql", nil); sql != "SELECT 2" {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+
+	// ошибка парсинга сохраняет предыдущую версию
+	fsys["users.sql"] = &fstest.MapFile{Data: []byte("SELECT : FROM t"), ModTime: time.Unix(3, 0)}
+	w.scan()
+	if lastErr == nil {
+		t.Fatal("expected parse error")
+	}
+	if sql, _ := reg.Sql("users.sql", nil); sql != "SELECT 2" {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+
+	// удаление файла
+	delete(fsys, "users.sql")
+	w.scan()
+	if len(reg.Names()) != 0 {
+		t.Fatalf("unexpected templates: %v", reg.Names())
+	}
+}
+
+func TestRegistry_WatchFS_Invalid(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	if _, err := NewRegistry().WatchFS(fsys, "*.sql", 0, nil); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if _, err := NewRegistry().WatchFS(fsys, "[", time.Second, nil); err == nil {
+		t.Error("expected error for invalid glob")
+	}
+
+	stop, err := NewRegistry().WatchFS(fsys, "*.sql", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+}
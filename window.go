@@ -0,0 +1,98 @@
+package sqlb
+
+import "strings"
+
+// WindowSpec - window definition for the OVER clause
+type WindowSpec struct {
+	partitionBy []string
+	orderBy     []string
+	frame       string
+}
+
+// Window - empty window definition: OVER ()
+func Window() *WindowSpec {
+	return &WindowSpec{}
+}
+
+// PartitionBy - window definition with PARTITION BY
+func PartitionBy(exprs ...string) *WindowSpec {
+	return Window().PartitionBy(exprs...)
+}
+
+// PartitionBy - add partitioning expressions
+func (w *WindowSpec) PartitionBy(exprs ...string) *WindowSpec {
+	w.partitionBy = append(w.partitionBy, exprs...)
+	return w
+}
+
+// OrderBy - add sort expressions, e.g. "created_at DESC"
+func (w *WindowSpec) OrderBy(exprs ...string) *WindowSpec {
+	w.orderBy = append(w.orderBy, exprs...)
+	return w
+}
+
+// Frame - frame clause, e.g. "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"
+func (w *WindowSpec) Frame(frame string) *WindowSpec {
+	w.frame = frame
+	return w
+}
+
+// String - window definition in parentheses
+func (w *WindowSpec) String() string {
+	if w == nil {
+		return "()"
+	}
+
+	var items []string
+	if len(w.partitionBy) > 0 {
+		items = append(items, "PARTITION BY "+strings.Join(w.partitionBy, ", "))
+	}
+	if len(w.orderBy) > 0 {
+		items = append(items, "ORDER BY "+strings.Join(w.orderBy, ", "))
+	}
+	if len(strings.TrimSpace(w.frame)) > 0 {
+		items = append(items, w.frame)
+	}
+
+	return "(" + strings.Join(items, " ") + ")"
+}
+
+// WindowFunc - window function call
+type WindowFunc struct {
+	call string
+}
+
+// WindowCall - any window or aggregate function call, e.g. "sum(total)", "lag(price, 1)"
+func WindowCall(call string) *WindowFunc {
+	return &WindowFunc{call: call}
+}
+
+// RowNumber - row_number()
+func RowNumber() *WindowFunc {
+	return WindowCall("row_number()")
+}
+
+// Rank - rank()
+func Rank() *WindowFunc {
+	return WindowCall("rank()")
+}
+
+// DenseRank - dense_rank()
+func DenseRank() *WindowFunc {
+	return WindowCall("dense_rank()")
+}
+
+// CountAll - count(*), with an empty window it is the total number of rows of the query: CountAll().Over(nil)
+func CountAll() *WindowFunc {
+	return WindowCall("count(*)")
+}
+
+// Over - expression of the function over the window. nil window means OVER ()
+func (f *WindowFunc) Over(w *WindowSpec) string {
+	return f.call + " OVER " + w.String()
+}
+
+// OverAs - expression of the function over the window with the column alias
+func (f *WindowFunc) OverAs(w *WindowSpec, alias string) string {
+	return f.Over(w) + " AS " + alias
+}
@@ -0,0 +1,29 @@
+package sqlb
+
+import "testing"
+
+func TestWindowFunc(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		result string
+	}{
+		{"row number", RowNumber().Over(PartitionBy("user_id").OrderBy("created_at DESC")),
+			"row_number() OVER (PARTITION BY user_id ORDER BY created_at DESC)"},
+		{"total", CountAll().OverAs(nil, "total"), "count(*) OVER () AS total"},
+		{"frame", WindowCall("sum(amount)").Over(Window().OrderBy("id").Frame("ROWS BETWEEN 2 PRECEDING AND CURRENT ROW")),
+			"sum(amount) OVER (ORDER BY id ROWS BETWEEN 2 PRECEDING AND CURRENT ROW)"},
+		{"rank", DenseRank().Over(PartitionBy("a", "b")), "dense_rank() OVER (PARTITION BY a, b)"},
+	}
+
+	for _, test := range tests {
+		if test.expr != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, test.expr, test.result)
+		}
+	}
+
+	sql := Select("id", CountAll().OverAs(nil, "total")).From("t").Limit(10).String()
+	if req := "SELECT id, count(*) OVER () AS total FROM t LIMIT 10"; sql != req {
+		t.Fatalf("%s, wants: %s", sql, req)
+	}
+}
@@ -0,0 +1,70 @@
+package sqlb
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/n-r-w/nerr"
+)
+
+// xmlMode - type of xml conversion
+type xmlMode int
+
+const (
+	xmlNone xmlMode = iota
+	// XMLPARSE(DOCUMENT '...')
+	xmlDocument
+	// '...'::xml
+	xmlCast
+)
+
+// XmlDocument - render the value as XMLPARSE(DOCUMENT '...').
+// The value can be a string, []byte or any value supported by encoding/xml
+func XmlDocument() Option {
+	return func(o *options) {
+		o.xml = xmlDocument
+	}
+}
+
+// Xml - render the value as '...'::xml.
+// The value can be a string, []byte or any value supported by encoding/xml
+func Xml() Option {
+	return func(o *options) {
+		o.xml = xmlCast
+	}
+}
+
+// xmlToSql - convert value to sql xml expression
+func xmlToSql(v any, mode xmlMode) (string, error) {
+	var text string
+
+	switch v := v.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		text = v
+	case []byte:
+		if v == nil {
+			return "null", nil
+		}
+		text = string(v)
+	default:
+		data, err := xml.Marshal(v)
+		if err != nil {
+			return "", nerr.New(fmt.Sprintf("can't convert to xml, value: %v, error: %v", v, err))
+		}
+		text = string(data)
+	}
+
+	// пустая строка не является корректным xml документом, но может быть xml контентом
+	lit := prepareString(text, `'`, true)
+	if len(lit) == 0 {
+		lit = `''`
+	}
+
+	if mode == xmlDocument {
+		return "XMLPARSE(DOCUMENT " + lit + ")", nil
+	}
+
+	return lit + "::xml", nil
+}
@@ -0,0 +1,35 @@
+package sqlb
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestToSql_Xml(t *testing.T) {
+	type item struct {
+		XMLName xml.Name `xml:"item"`
+		Name    string   `xml:"name"`
+	}
+
+	tests := []struct {
+		name   string
+		value  any
+		opt    Option
+		result string
+	}{
+		{"document", `<a>it's</a>`, XmlDocument(), `XMLPARSE(DOCUMENT E'<a>it\'s</a>')`},
+		{"cast", `<a/>`, Xml(), `E'<a/>'::xml`},
+		{"marshal", item{Name: "x"}, Xml(), `E'<item><name>x</name></item>'::xml`},
+		{"null", nil, XmlDocument(), `null`},
+	}
+
+	for _, test := range tests {
+		sql, err := ToSql(test.value, test.opt)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if sql != test.result {
+			t.Errorf("%s: %s, wants: %s", test.name, sql, test.result)
+		}
+	}
+}